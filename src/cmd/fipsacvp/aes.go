@@ -0,0 +1,292 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+)
+
+func init() {
+	register("ACVP-AES-GCM", processGCM)
+	register("ACVP-AES-CBC", processCBC)
+	register("ACVP-AES-CTR", processCTR)
+}
+
+type aesTestGroup struct {
+	ID         int    `json:"tgId"`
+	Type       string `json:"testType"`
+	Direction  string `json:"direction"`
+	KeyLen     int    `json:"keyLen"`
+	IVLen      int    `json:"ivLen"`
+	IVGen      string `json:"ivGen"`
+	PayloadLen int    `json:"payloadLen"`
+	AADLen     int    `json:"aadLen"`
+	TagLen     int    `json:"tagLen"`
+	Tests      []struct {
+		ID         int      `json:"tcId"`
+		Key        hexBytes `json:"key"`
+		IV         hexBytes `json:"iv"`
+		PT         hexBytes `json:"pt"`
+		CT         hexBytes `json:"ct"`
+		AAD        hexBytes `json:"aad"`
+		Tag        hexBytes `json:"tag"`
+		PayloadLen *int     `json:"payloadLen"`
+	} `json:"tests"`
+}
+
+// aesTestResponse uses pointers so that empty payloads are still
+// reported while fields that do not apply are omitted.
+type aesTestResponse struct {
+	ID      int              `json:"tcId"`
+	IV      *hexBytes        `json:"iv,omitempty"`
+	PT      *hexBytes        `json:"pt,omitempty"`
+	CT      *hexBytes        `json:"ct,omitempty"`
+	Tag     *hexBytes        `json:"tag,omitempty"`
+	Passed  *bool            `json:"testPassed,omitempty"`
+	Results []aesMCTResponse `json:"resultsArray,omitempty"`
+}
+
+type aesMCTResponse struct {
+	Key hexBytes `json:"key"`
+	IV  hexBytes `json:"iv"`
+	PT  hexBytes `json:"pt"`
+	CT  hexBytes `json:"ct"`
+}
+
+func decodeAESGroups(vs *vectorSet) ([]aesTestGroup, error) {
+	var groups []aesTestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.Direction != "encrypt" && g.Direction != "decrypt" {
+			return nil, fmt.Errorf("tgId %d: unknown direction %q", g.ID, g.Direction)
+		}
+	}
+	return groups, nil
+}
+
+func newGCM(key []byte, ivLen, tagLen int) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ivLen == 96 && tagLen == 128:
+		return cipher.NewGCM(block)
+	case tagLen == 128 && ivLen%8 == 0:
+		return cipher.NewGCMWithNonceSize(block, ivLen/8)
+	case ivLen == 96 && tagLen%8 == 0:
+		return cipher.NewGCMWithTagSize(block, tagLen/8)
+	}
+	return nil, fmt.Errorf("unsupported GCM IV length %d with tag length %d", ivLen, tagLen)
+}
+
+func processGCM(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeAESGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		if g.Type != "AFT" {
+			return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+		}
+		var tests []aesTestResponse
+		for _, tc := range g.Tests {
+			aead, err := newGCM(tc.Key, g.IVLen, g.TagLen)
+			if err != nil {
+				return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+			}
+			r := aesTestResponse{ID: tc.ID}
+			iv := []byte(tc.IV)
+			if g.Direction == "encrypt" && g.IVGen == "internal" {
+				iv = make([]byte, aead.NonceSize())
+				if _, err := rand.Read(iv); err != nil {
+					return nil, err
+				}
+				r.IV = hexPtr(iv)
+			}
+			if len(iv) != aead.NonceSize() {
+				return nil, fmt.Errorf("tcId %d: IV is %d bytes, want %d", tc.ID, len(iv), aead.NonceSize())
+			}
+			if g.Direction == "encrypt" {
+				out := aead.Seal(nil, iv, tc.PT, tc.AAD)
+				n := len(out) - aead.Overhead()
+				r.CT, r.Tag = hexPtr(out[:n]), hexPtr(out[n:])
+			} else {
+				in := append(append([]byte(nil), tc.CT...), tc.Tag...)
+				pt, err := aead.Open(nil, iv, in, tc.AAD)
+				if err != nil {
+					passed := false
+					r.Passed = &passed
+				} else {
+					r.PT = hexPtr(pt)
+				}
+			}
+			tests = append(tests, r)
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
+
+func processCBC(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeAESGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		encrypt := g.Direction == "encrypt"
+		var tests []aesTestResponse
+		for _, tc := range g.Tests {
+			in := tc.CT
+			if encrypt {
+				in = tc.PT
+			}
+			if len(tc.IV) != aes.BlockSize || len(in)%aes.BlockSize != 0 {
+				return nil, fmt.Errorf("tcId %d: bad IV or payload length", tc.ID)
+			}
+			r := aesTestResponse{ID: tc.ID}
+			switch g.Type {
+			case "AFT":
+				mode, err := newCBC(encrypt, tc.Key, tc.IV)
+				if err != nil {
+					return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+				}
+				out := make([]byte, len(in))
+				mode.CryptBlocks(out, in)
+				if encrypt {
+					r.CT = hexPtr(out)
+				} else {
+					r.PT = hexPtr(out)
+				}
+			case "MCT":
+				if len(in) != aes.BlockSize {
+					return nil, fmt.Errorf("tcId %d: MCT payload must be one block", tc.ID)
+				}
+				r.Results, err = cbcMCT(encrypt, tc.Key, tc.IV, in)
+				if err != nil {
+					return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+				}
+			default:
+				return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+			}
+			tests = append(tests, r)
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
+
+func newCBC(encrypt bool, key, iv []byte) (cipher.BlockMode, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if encrypt {
+		return cipher.NewCBCEncrypter(block, iv), nil
+	}
+	return cipher.NewCBCDecrypter(block, iv), nil
+}
+
+// cbcMCT runs the AES-CBC Monte Carlo test from the AESAVS. Encryption
+// and decryption follow the same chaining, with the roles of plaintext
+// and ciphertext swapped.
+func cbcMCT(encrypt bool, key, iv, in []byte) ([]aesMCTResponse, error) {
+	results := make([]aesMCTResponse, 100)
+	for i := range results {
+		mode, err := newCBC(encrypt, key, iv)
+		if err != nil {
+			return nil, err
+		}
+		first := in
+		var prev, cur []byte
+		for j := 0; j < 1000; j++ {
+			out := make([]byte, aes.BlockSize)
+			mode.CryptBlocks(out, in)
+			if j == 0 {
+				in = iv
+			} else {
+				in = cur
+			}
+			prev, cur = cur, out
+		}
+
+		results[i] = aesMCTResponse{Key: key, IV: iv}
+		if encrypt {
+			results[i].PT, results[i].CT = first, cur
+		} else {
+			results[i].CT, results[i].PT = first, cur
+		}
+
+		next := make([]byte, len(key))
+		copy(next, key)
+		switch len(key) {
+		case 16:
+			xorBytes(next, cur)
+		case 24:
+			xorBytes(next, append(append([]byte(nil), prev[8:]...), cur...))
+		case 32:
+			xorBytes(next, append(append([]byte(nil), prev...), cur...))
+		}
+		key, iv, in = next, cur, prev
+	}
+	return results, nil
+}
+
+func xorBytes(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
+
+func processCTR(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeAESGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		if g.Type != "AFT" && g.Type != "CTR" {
+			return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+		}
+		var tests []aesTestResponse
+		for _, tc := range g.Tests {
+			in := tc.CT
+			if g.Direction == "encrypt" {
+				in = tc.PT
+			}
+			if tc.PayloadLen != nil {
+				if in, err = hexBytes(in).bits(*tc.PayloadLen); err != nil {
+					return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+				}
+			}
+			if len(tc.IV) != aes.BlockSize {
+				return nil, fmt.Errorf("tcId %d: IV is %d bytes, want %d", tc.ID, len(tc.IV), aes.BlockSize)
+			}
+			block, err := aes.NewCipher(tc.Key)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			out := make([]byte, len(in))
+			cipher.NewCTR(block, tc.IV).XORKeyStream(out, in)
+			r := aesTestResponse{ID: tc.ID}
+			if g.Direction == "encrypt" {
+				r.CT = hexPtr(out)
+			} else {
+				r.PT = hexPtr(out)
+			}
+			tests = append(tests, r)
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
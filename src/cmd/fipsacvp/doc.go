@@ -0,0 +1,54 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Fipsacvp answers NIST ACVP test vector requests using the standard crypto
+packages, so that the results produced by the OpenSSL FIPS backend can be
+submitted for algorithm validation.
+
+Usage:
+
+	go tool fipsacvp [-o response.json] [-allow-nonfips] request.json
+
+Fipsacvp reads an ACVP request file, as downloaded from the ACVP server,
+computes the answer to every test case and writes the matching response
+file to standard output or to the file named by the -o flag. It never
+contacts the ACVP server; fetching requests and uploading responses is
+left to other tools.
+
+The request may be either a JSON array holding the protocol version
+object followed by a vector set, or a bare vector set object. The
+response has the same shape.
+
+Every operation goes through the public crypto packages (crypto/aes,
+crypto/cipher, crypto/sha256, crypto/hmac, crypto/rsa, crypto/ecdsa,
+crypto/kdf and so on), exactly as an application would call them. Fipsacvp refuses to
+run unless the FIPS backend is enabled, as reported by crypto/boring.Enabled;
+the -allow-nonfips flag lifts that restriction, which is useful for
+comparing against the pure Go implementations.
+
+The supported algorithms are:
+
+	SHA-1, SHA2-224, SHA2-256, SHA2-384, SHA2-512, SHA2-512/224, SHA2-512/256
+		AFT, MCT and LDT tests
+	HMAC-SHA-1, HMAC-SHA2-224, HMAC-SHA2-256, HMAC-SHA2-384, HMAC-SHA2-512
+	ACVP-AES-GCM, ACVP-AES-CBC, ACVP-AES-CTR
+		AES-CBC supports AFT and MCT tests
+	RSA sigGen and sigVer (FIPS186-4), PKCS #1 v1.5 and PSS
+	ECDSA keyGen, keyVer, sigGen and sigVer (FIPS186-4)
+	KDF (SP 800-108) in counter mode with HMAC or AES-CMAC, with a
+		32-bit counter before the fixed data
+	kdf-components ansix9.63
+
+The SP 800-108 fixed input data is chosen by fipsacvp, as ACVP expects,
+and has the form crypto/kdf uses. The one-step KDF of SP 800-56C that
+crypto/kdf also implements is not supported: ACVP only tests it as part
+of the KDA vector sets, whose fixed info patterns fipsacvp does not
+implement.
+
+Test groups whose parameters the crypto packages reject, such as GCM
+nonce or tag sizes that the backend does not support, cause fipsacvp to
+fail rather than produce a partial response.
+*/
+package main
@@ -0,0 +1,195 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+func init() {
+	register("ECDSA/keyGen", processECDSAKeyGen)
+	register("ECDSA/keyVer", processECDSAKeyVer)
+	register("ECDSA/sigGen", processECDSASigGen)
+	register("ECDSA/sigVer", processECDSASigVer)
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-224": elliptic.P224(),
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+type ecdsaTestGroup struct {
+	ID      int    `json:"tgId"`
+	Type    string `json:"testType"`
+	Curve   string `json:"curve"`
+	HashAlg string `json:"hashAlg"`
+	Tests   []struct {
+		ID      int      `json:"tcId"`
+		Message hexBytes `json:"message"`
+		Qx      hexBytes `json:"qx"`
+		Qy      hexBytes `json:"qy"`
+		R       hexBytes `json:"r"`
+		S       hexBytes `json:"s"`
+	} `json:"tests"`
+}
+
+type ecdsaKeyGenTestResponse struct {
+	ID int      `json:"tcId"`
+	D  hexBytes `json:"d"`
+	Qx hexBytes `json:"qx"`
+	Qy hexBytes `json:"qy"`
+}
+
+type ecdsaSigGenGroupResponse struct {
+	ID    int                       `json:"tgId"`
+	Qx    hexBytes                  `json:"qx"`
+	Qy    hexBytes                  `json:"qy"`
+	Tests []ecdsaSigGenTestResponse `json:"tests"`
+}
+
+type ecdsaSigGenTestResponse struct {
+	ID int      `json:"tcId"`
+	R  hexBytes `json:"r"`
+	S  hexBytes `json:"s"`
+}
+
+func decodeECDSAGroups(vs *vectorSet) ([]ecdsaTestGroup, error) {
+	var groups []ecdsaTestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if curves[g.Curve] == nil {
+			return nil, fmt.Errorf("tgId %d: unsupported curve %q", g.ID, g.Curve)
+		}
+	}
+	return groups, nil
+}
+
+// fieldBytes encodes x as a big-endian integer of the curve's field size.
+func fieldBytes(c elliptic.Curve, x *big.Int) hexBytes {
+	b := make([]byte, (c.Params().BitSize+7)/8)
+	return x.FillBytes(b)
+}
+
+func processECDSAKeyGen(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeECDSAGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		c := curves[g.Curve]
+		var tests []ecdsaKeyGenTestResponse
+		for _, tc := range g.Tests {
+			priv, err := ecdsa.GenerateKey(c, rand.Reader)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			tests = append(tests, ecdsaKeyGenTestResponse{
+				ID: tc.ID,
+				D:  fieldBytes(c, priv.D),
+				Qx: fieldBytes(c, priv.X),
+				Qy: fieldBytes(c, priv.Y),
+			})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
+
+func processECDSAKeyVer(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeECDSAGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		c := curves[g.Curve]
+		p := c.Params().P
+		var tests []sigVerTestResponse
+		for _, tc := range g.Tests {
+			x := new(big.Int).SetBytes(tc.Qx)
+			y := new(big.Int).SetBytes(tc.Qy)
+			ok := x.Cmp(p) < 0 && y.Cmp(p) < 0 && c.IsOnCurve(x, y)
+			tests = append(tests, sigVerTestResponse{ID: tc.ID, Passed: ok})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
+
+func processECDSASigGen(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeECDSAGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []ecdsaSigGenGroupResponse
+	for _, g := range groups {
+		c := curves[g.Curve]
+		h, err := lookupHash(g.HashAlg)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		priv, err := ecdsa.GenerateKey(c, rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		r := ecdsaSigGenGroupResponse{
+			ID: g.ID,
+			Qx: fieldBytes(c, priv.X),
+			Qy: fieldBytes(c, priv.Y),
+		}
+		for _, tc := range g.Tests {
+			sr, ss, err := ecdsa.HashSign(rand.Reader, priv, tc.Message, h)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			r.Tests = append(r.Tests, ecdsaSigGenTestResponse{
+				ID: tc.ID,
+				R:  fieldBytes(c, sr),
+				S:  fieldBytes(c, ss),
+			})
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
+
+func processECDSASigVer(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeECDSAGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		c := curves[g.Curve]
+		h, err := lookupHash(g.HashAlg)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		var tests []sigVerTestResponse
+		for _, tc := range g.Tests {
+			pub := &ecdsa.PublicKey{
+				Curve: c,
+				X:     new(big.Int).SetBytes(tc.Qx),
+				Y:     new(big.Int).SetBytes(tc.Qy),
+			}
+			r := new(big.Int).SetBytes(tc.R)
+			s := new(big.Int).SetBytes(tc.S)
+			ok := ecdsa.HashVerify(pub, tc.Message, r, s, h)
+			tests = append(tests, sigVerTestResponse{ID: tc.ID, Passed: ok})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
@@ -0,0 +1,250 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// run processes the vector set in req, wrapped in the usual version
+// array, and returns the decoded response vector set.
+func run(t *testing.T, req string) map[string]interface{} {
+	t.Helper()
+	out, err := process([]byte(`[{"acvVersion": "1.0"}, ` + req + `]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp []map[string]interface{}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp) != 2 || resp[0]["acvVersion"] != "1.0" {
+		t.Fatalf("bad response framing: %s", out)
+	}
+	return resp[1]
+}
+
+// field returns the value at path in v, where each path element is
+// either an object key or an array index.
+func field(t *testing.T, v interface{}, path ...interface{}) interface{} {
+	t.Helper()
+	for _, p := range path {
+		switch p := p.(type) {
+		case string:
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				t.Fatalf("%v: not an object", path)
+			}
+			v = m[p]
+		case int:
+			a, ok := v.([]interface{})
+			if !ok || p >= len(a) {
+				t.Fatalf("%v: not an array or index out of range", path)
+			}
+			v = a[p]
+		}
+	}
+	return v
+}
+
+func checkField(t *testing.T, v interface{}, want interface{}, path ...interface{}) {
+	t.Helper()
+	got := field(t, v, path...)
+	if s, ok := want.(string); ok {
+		want = strings.ToUpper(s)
+	}
+	if got != want {
+		t.Errorf("%v = %v, want %v", path, got, want)
+	}
+}
+
+func TestSHA(t *testing.T) {
+	resp := run(t, `{"vsId": 1, "algorithm": "SHA2-256", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "tests": [
+			{"tcId": 1, "msg": "", "len": 0},
+			{"tcId": 2, "msg": "616263", "len": 24}]},
+		{"tgId": 2, "testType": "LDT", "tests": [
+			{"tcId": 3, "largeMsg": {"content": "61", "contentLength": 8, "fullLength": 8000000, "expansionTechnique": "repeating"}}]}]}`)
+	checkField(t, resp, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", "testGroups", 0, "tests", 0, "md")
+	checkField(t, resp, "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad", "testGroups", 0, "tests", 1, "md")
+	checkField(t, resp, "cdc76e5c9914fb9281a1c7e284d73e67f1809a48a497200e046d39ccc7112cd0", "testGroups", 1, "tests", 0, "md")
+	checkField(t, resp, 1.0, "vsId")
+}
+
+func TestSHAMCT(t *testing.T) {
+	resp := run(t, `{"vsId": 1, "algorithm": "SHA-1", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "MCT", "tests": [{"tcId": 1, "msg": "00", "len": 8}]}]}`)
+	results := field(t, resp, "testGroups", 0, "tests", 0, "resultsArray").([]interface{})
+	if len(results) != 100 {
+		t.Fatalf("got %d MCT results, want 100", len(results))
+	}
+}
+
+func TestHMAC(t *testing.T) {
+	// RFC 4231, test case 2, with the MAC truncated to 128 bits in the second group.
+	resp := run(t, `{"vsId": 1, "algorithm": "HMAC-SHA2-256", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "keyLen": 32, "msgLen": 224, "macLen": 256, "tests": [
+			{"tcId": 1, "key": "4a656665", "msg": "7768617420646f2079612077616e7420666f72206e6f7468696e673f"}]},
+		{"tgId": 2, "testType": "AFT", "keyLen": 32, "msgLen": 224, "macLen": 128, "tests": [
+			{"tcId": 2, "key": "4a656665", "msg": "7768617420646f2079612077616e7420666f72206e6f7468696e673f"}]}]}`)
+	checkField(t, resp, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843", "testGroups", 0, "tests", 0, "mac")
+	checkField(t, resp, "5bdcc146bf60754e6a042426089575c7", "testGroups", 1, "tests", 0, "mac")
+}
+
+func TestAESGCM(t *testing.T) {
+	// Test case 2 from the GCM specification.
+	resp := run(t, `{"vsId": 1, "algorithm": "ACVP-AES-GCM", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "direction": "encrypt", "keyLen": 128, "ivLen": 96, "ivGen": "external",
+		 "payloadLen": 128, "aadLen": 0, "tagLen": 128, "tests": [
+			{"tcId": 1, "key": "00000000000000000000000000000000", "iv": "000000000000000000000000",
+			 "pt": "00000000000000000000000000000000", "aad": ""}]},
+		{"tgId": 2, "testType": "AFT", "direction": "decrypt", "keyLen": 128, "ivLen": 96, "ivGen": "external",
+		 "payloadLen": 128, "aadLen": 0, "tagLen": 128, "tests": [
+			{"tcId": 2, "key": "00000000000000000000000000000000", "iv": "000000000000000000000000",
+			 "ct": "0388dace60b6a392f328c2b971b2fe78", "aad": "", "tag": "ab6e47d42cec13bdf53a67b21257bddf"},
+			{"tcId": 3, "key": "00000000000000000000000000000000", "iv": "000000000000000000000000",
+			 "ct": "0388dace60b6a392f328c2b971b2fe78", "aad": "", "tag": "ab6e47d42cec13bdf53a67b21257bdde"}]}]}`)
+	checkField(t, resp, "0388dace60b6a392f328c2b971b2fe78", "testGroups", 0, "tests", 0, "ct")
+	checkField(t, resp, "ab6e47d42cec13bdf53a67b21257bddf", "testGroups", 0, "tests", 0, "tag")
+	checkField(t, resp, "00000000000000000000000000000000", "testGroups", 1, "tests", 0, "pt")
+	checkField(t, resp, false, "testGroups", 1, "tests", 1, "testPassed")
+}
+
+func TestAESCBC(t *testing.T) {
+	// SP 800-38A, F.2.1 and F.2.2, and the first result of the AESAVS
+	// CBCMCT128 encryption vectors.
+	resp := run(t, `{"vsId": 1, "algorithm": "ACVP-AES-CBC", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "direction": "encrypt", "keyLen": 128, "tests": [
+			{"tcId": 1, "key": "2b7e151628aed2a6abf7158809cf4f3c", "iv": "000102030405060708090a0b0c0d0e0f",
+			 "pt": "6bc1bee22e409f96e93d7e117393172a"}]},
+		{"tgId": 2, "testType": "AFT", "direction": "decrypt", "keyLen": 128, "tests": [
+			{"tcId": 2, "key": "2b7e151628aed2a6abf7158809cf4f3c", "iv": "000102030405060708090a0b0c0d0e0f",
+			 "ct": "7649abac8119b246cee98e9b12e9197d"}]},
+		{"tgId": 3, "testType": "MCT", "direction": "encrypt", "keyLen": 128, "tests": [
+			{"tcId": 3, "key": "9dc2c84a37850c11699818605f47958c", "iv": "256953b2feab2a04ae0180d8335bbed6",
+			 "pt": "2e586692e647f5028ec6fa47a55a2aab"}]}]}`)
+	checkField(t, resp, "7649abac8119b246cee98e9b12e9197d", "testGroups", 0, "tests", 0, "ct")
+	checkField(t, resp, "6bc1bee22e409f96e93d7e117393172a", "testGroups", 1, "tests", 0, "pt")
+	checkField(t, resp, "9dc2c84a37850c11699818605f47958c", "testGroups", 2, "tests", 0, "resultsArray", 0, "key")
+	checkField(t, resp, "1b1ebd1fc45ec43037fd4844241a437f", "testGroups", 2, "tests", 0, "resultsArray", 0, "ct")
+}
+
+func TestAESCTR(t *testing.T) {
+	// SP 800-38A, F.5.1.
+	resp := run(t, `{"vsId": 1, "algorithm": "ACVP-AES-CTR", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "direction": "encrypt", "keyLen": 128, "tests": [
+			{"tcId": 1, "key": "2b7e151628aed2a6abf7158809cf4f3c", "iv": "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff",
+			 "pt": "6bc1bee22e409f96e93d7e117393172a", "payloadLen": 128}]}]}`)
+	checkField(t, resp, "874d6191b620e3261bef6864990db6ce", "testGroups", 0, "tests", 0, "ct")
+}
+
+func TestRSA(t *testing.T) {
+	for _, sigType := range []string{"pkcs1v1.5", "pss"} {
+		gen := run(t, fmt.Sprintf(`{"vsId": 1, "algorithm": "RSA", "mode": "sigGen", "revision": "FIPS186-4", "testGroups": [
+			{"tgId": 1, "testType": "GDT", "sigType": %q, "modulo": 2048, "hashAlg": "SHA2-256", "saltLen": 32, "tests": [
+				{"tcId": 1, "message": "616263"}]}]}`, sigType))
+		n := field(t, gen, "testGroups", 0, "n").(string)
+		e := field(t, gen, "testGroups", 0, "e").(string)
+		sig := field(t, gen, "testGroups", 0, "tests", 0, "signature").(string)
+
+		ver := run(t, fmt.Sprintf(`{"vsId": 2, "algorithm": "RSA", "mode": "sigVer", "revision": "FIPS186-4", "testGroups": [
+			{"tgId": 1, "testType": "GDT", "sigType": %q, "modulo": 2048, "hashAlg": "SHA2-256", "saltLen": 32,
+			 "n": %q, "e": %q, "tests": [
+				{"tcId": 1, "message": "616263", "signature": %q},
+				{"tcId": 2, "message": "616264", "signature": %q}]}]}`, sigType, n, e, sig, sig))
+		checkField(t, ver, true, "testGroups", 0, "tests", 0, "testPassed")
+		checkField(t, ver, false, "testGroups", 0, "tests", 1, "testPassed")
+	}
+}
+
+func TestECDSA(t *testing.T) {
+	gen := run(t, `{"vsId": 1, "algorithm": "ECDSA", "mode": "sigGen", "revision": "FIPS186-4", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "curve": "P-256", "hashAlg": "SHA2-256", "tests": [
+			{"tcId": 1, "message": "616263"}]}]}`)
+	qx := field(t, gen, "testGroups", 0, "qx").(string)
+	qy := field(t, gen, "testGroups", 0, "qy").(string)
+	r := field(t, gen, "testGroups", 0, "tests", 0, "r").(string)
+	s := field(t, gen, "testGroups", 0, "tests", 0, "s").(string)
+
+	ver := run(t, fmt.Sprintf(`{"vsId": 2, "algorithm": "ECDSA", "mode": "sigVer", "revision": "FIPS186-4", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "curve": "P-256", "hashAlg": "SHA2-256", "tests": [
+			{"tcId": 1, "message": "616263", "qx": %[1]q, "qy": %[2]q, "r": %[3]q, "s": %[4]q},
+			{"tcId": 2, "message": "616264", "qx": %[1]q, "qy": %[2]q, "r": %[3]q, "s": %[4]q}]}]}`, qx, qy, r, s))
+	checkField(t, ver, true, "testGroups", 0, "tests", 0, "testPassed")
+	checkField(t, ver, false, "testGroups", 0, "tests", 1, "testPassed")
+
+	keyGen := run(t, `{"vsId": 3, "algorithm": "ECDSA", "mode": "keyGen", "revision": "FIPS186-4", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "curve": "P-384", "secretGenerationMode": "testing candidates", "tests": [{"tcId": 1}]}]}`)
+	qx = field(t, keyGen, "testGroups", 0, "tests", 0, "qx").(string)
+	qy = field(t, keyGen, "testGroups", 0, "tests", 0, "qy").(string)
+	keyVer := run(t, fmt.Sprintf(`{"vsId": 4, "algorithm": "ECDSA", "mode": "keyVer", "revision": "FIPS186-4", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "curve": "P-384", "tests": [
+			{"tcId": 1, "qx": %[1]q, "qy": %[2]q},
+			{"tcId": 2, "qx": %[1]q, "qy": %[1]q}]}]}`, qx, qy))
+	checkField(t, keyVer, true, "testGroups", 0, "tests", 0, "testPassed")
+	checkField(t, keyVer, false, "testGroups", 0, "tests", 1, "testPassed")
+}
+
+func TestKDF108(t *testing.T) {
+	resp := run(t, `{"vsId": 1, "algorithm": "KDF", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "kdfMode": "counter", "macMode": "HMAC-SHA2-256", "counterLocation": "before fixed data", "keyOutLength": 320, "counterLength": 32, "tests": [
+			{"tcId": 7, "keyIn": "000102030405060708090a0b0c0d0e0f", "deferred": false}]},
+		{"tgId": 2, "testType": "AFT", "kdfMode": "counter", "macMode": "CMAC-AES128", "counterLocation": "before fixed data", "keyOutLength": 128, "counterLength": 32, "tests": [
+			{"tcId": 8, "keyIn": "000102030405060708090a0b0c0d0e0f", "deferred": false}]}]}`)
+
+	// The fixed data is chosen by fipsacvp; check the key against it.
+	fixed := hexString(t, field(t, resp, "testGroups", 0, "tests", 0, "fixedData"))
+	if want := append([]byte("fipsacvp\x00\x00\x00\x00\x07"), 0, 0, 1, 0x40); !bytes.Equal(fixed, want) {
+		t.Errorf("fixedData = %x, want %x", fixed, want)
+	}
+	key := []byte{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	var want []byte
+	for i := byte(1); len(want) < 40; i++ {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte{0, 0, 0, i})
+		mac.Write(fixed)
+		want = mac.Sum(want)
+	}
+	checkField(t, resp, hex.EncodeToString(want[:40]), "testGroups", 0, "tests", 0, "keyOut")
+	if out := hexString(t, field(t, resp, "testGroups", 1, "tests", 0, "keyOut")); len(out) != 16 {
+		t.Errorf("CMAC keyOut is %d bytes, want 16", len(out))
+	}
+
+	if _, err := process([]byte(`{"vsId": 1, "algorithm": "KDF", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "kdfMode": "feedback", "macMode": "HMAC-SHA2-256", "counterLocation": "before iterator", "keyOutLength": 128, "counterLength": 8, "tests": []}]}`)); err == nil {
+		t.Error("unsupported KDF mode did not fail")
+	}
+}
+
+func TestX963(t *testing.T) {
+	// From the NIST CAVS ANS X9.63 test vectors.
+	resp := run(t, `{"vsId": 1, "algorithm": "kdf-components", "mode": "ansix9.63", "revision": "1.0", "testGroups": [
+		{"tgId": 1, "testType": "AFT", "fieldSize": 192, "hashAlg": "SHA-1", "sharedInfoLength": 0, "keyDataLength": 128, "tests": [
+			{"tcId": 1, "z": "1c7d7b5f0597b03d06a018466ed1a93e30ed4b04dc64ccdd", "sharedInfo": ""}]}]}`)
+	checkField(t, resp, "bf71dffd8f4d99223936beb46fee8ccc", "testGroups", 0, "tests", 0, "keyData")
+}
+
+func hexString(t *testing.T, v interface{}) []byte {
+	t.Helper()
+	s, _ := v.(string)
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestUnsupported(t *testing.T) {
+	if _, err := process([]byte(`{"vsId": 1, "algorithm": "ACVP-TDES-ECB", "revision": "1.0", "testGroups": []}`)); err == nil {
+		t.Error("unsupported algorithm did not fail")
+	}
+}
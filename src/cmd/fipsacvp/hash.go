@@ -0,0 +1,208 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto"
+	"crypto/hmac"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha3"
+	_ "crypto/sha512"
+	"encoding/json"
+	"fmt"
+)
+
+// hashes maps ACVP hash names to the crypto.Hash implementing them.
+var hashes = map[string]crypto.Hash{
+	"SHA-1":        crypto.SHA1,
+	"SHA2-224":     crypto.SHA224,
+	"SHA2-256":     crypto.SHA256,
+	"SHA2-384":     crypto.SHA384,
+	"SHA2-512":     crypto.SHA512,
+	"SHA2-512/224": crypto.SHA512_224,
+	"SHA2-512/256": crypto.SHA512_256,
+	"SHA3-224":     crypto.SHA3_224,
+	"SHA3-256":     crypto.SHA3_256,
+	"SHA3-384":     crypto.SHA3_384,
+	"SHA3-512":     crypto.SHA3_512,
+}
+
+func lookupHash(name string) (crypto.Hash, error) {
+	h, ok := hashes[name]
+	if !ok || !h.Available() {
+		return 0, fmt.Errorf("unsupported hash %q", name)
+	}
+	return h, nil
+}
+
+func init() {
+	for _, name := range []string{"SHA-1", "SHA2-224", "SHA2-256", "SHA2-384", "SHA2-512", "SHA2-512/224", "SHA2-512/256"} {
+		h := hashes[name]
+		register(name, func(vs *vectorSet) (interface{}, error) { return processSHA(vs, h) })
+		register("HMAC-"+name, func(vs *vectorSet) (interface{}, error) { return processHMAC(vs, h) })
+	}
+}
+
+type shaTestGroup struct {
+	ID         int    `json:"tgId"`
+	Type       string `json:"testType"`
+	MCTVersion string `json:"mctVersion"`
+	Tests      []struct {
+		ID       int      `json:"tcId"`
+		Msg      hexBytes `json:"msg"`
+		Len      int      `json:"len"`
+		LargeMsg *struct {
+			Content    hexBytes `json:"content"`
+			ContentLen int      `json:"contentLength"`
+			FullLen    uint64   `json:"fullLength"`
+			Expansion  string   `json:"expansionTechnique"`
+		} `json:"largeMsg"`
+	} `json:"tests"`
+}
+
+type shaTestResponse struct {
+	ID      int              `json:"tcId"`
+	Digest  hexBytes         `json:"md,omitempty"`
+	Results []shaMCTResponse `json:"resultsArray,omitempty"`
+}
+
+type shaMCTResponse struct {
+	Digest hexBytes `json:"md"`
+}
+
+type testGroupResponse struct {
+	ID    int         `json:"tgId"`
+	Tests interface{} `json:"tests"`
+}
+
+func processSHA(vs *vectorSet, h crypto.Hash) (interface{}, error) {
+	var groups []shaTestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		var tests []shaTestResponse
+		for _, tc := range g.Tests {
+			r := shaTestResponse{ID: tc.ID}
+			switch g.Type {
+			case "AFT":
+				msg, err := tc.Msg.bits(tc.Len)
+				if err != nil {
+					return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+				}
+				d := h.New()
+				d.Write(msg)
+				r.Digest = d.Sum(nil)
+			case "MCT":
+				if g.MCTVersion != "" && g.MCTVersion != "standard" {
+					return nil, fmt.Errorf("tgId %d: unsupported MCT version %q", g.ID, g.MCTVersion)
+				}
+				msg, err := tc.Msg.bits(tc.Len)
+				if err != nil {
+					return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+				}
+				r.Results = shaMCT(h, msg)
+			case "LDT":
+				lm := tc.LargeMsg
+				if lm == nil || lm.Expansion != "repeating" {
+					return nil, fmt.Errorf("tcId %d: unsupported large message", tc.ID)
+				}
+				content, err := lm.Content.bits(lm.ContentLen)
+				if err != nil {
+					return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+				}
+				if len(content) == 0 || lm.FullLen%8 != 0 {
+					return nil, fmt.Errorf("tcId %d: unsupported large message length", tc.ID)
+				}
+				d := h.New()
+				for n := lm.FullLen / 8; n > 0; {
+					c := content
+					if uint64(len(c)) > n {
+						c = c[:n]
+					}
+					d.Write(c)
+					n -= uint64(len(c))
+				}
+				r.Digest = d.Sum(nil)
+			default:
+				return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+			}
+			tests = append(tests, r)
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
+
+// shaMCT runs the SHA-1/SHA-2 Monte Carlo test starting from seed.
+func shaMCT(h crypto.Hash, seed []byte) []shaMCTResponse {
+	results := make([]shaMCTResponse, 100)
+	d := h.New()
+	for j := range results {
+		a, b, c := seed, seed, seed
+		for i := 0; i < 1000; i++ {
+			d.Reset()
+			d.Write(a)
+			d.Write(b)
+			d.Write(c)
+			a, b, c = b, c, d.Sum(nil)
+		}
+		seed = c
+		results[j].Digest = c
+	}
+	return results
+}
+
+type hmacTestGroup struct {
+	ID     int    `json:"tgId"`
+	Type   string `json:"testType"`
+	KeyLen int    `json:"keyLen"`
+	MsgLen int    `json:"msgLen"`
+	MACLen int    `json:"macLen"`
+	Tests  []struct {
+		ID  int      `json:"tcId"`
+		Key hexBytes `json:"key"`
+		Msg hexBytes `json:"msg"`
+	} `json:"tests"`
+}
+
+type hmacTestResponse struct {
+	ID  int      `json:"tcId"`
+	MAC hexBytes `json:"mac"`
+}
+
+func processHMAC(vs *vectorSet, h crypto.Hash) (interface{}, error) {
+	var groups []hmacTestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		if g.Type != "AFT" {
+			return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+		}
+		if g.MACLen%8 != 0 || g.MACLen/8 > h.Size() {
+			return nil, fmt.Errorf("tgId %d: unsupported MAC length %d", g.ID, g.MACLen)
+		}
+		var tests []hmacTestResponse
+		for _, tc := range g.Tests {
+			key, err := tc.Key.bits(g.KeyLen)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			msg, err := tc.Msg.bits(g.MsgLen)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			mac := hmac.New(h.New, key)
+			mac.Write(msg)
+			tests = append(tests, hmacTestResponse{ID: tc.ID, MAC: mac.Sum(nil)[:g.MACLen/8]})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto/kdf"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register("KDF", processKDF108)
+	register("kdf-components/ansix9.63", processX963)
+}
+
+type kdf108TestGroup struct {
+	ID              int    `json:"tgId"`
+	Type            string `json:"testType"`
+	KDFMode         string `json:"kdfMode"`
+	MACMode         string `json:"macMode"`
+	CounterLocation string `json:"counterLocation"`
+	KeyOutLength    int    `json:"keyOutLength"`
+	CounterLength   int    `json:"counterLength"`
+	Tests           []struct {
+		ID       int      `json:"tcId"`
+		KeyIn    hexBytes `json:"keyIn"`
+		Deferred bool     `json:"deferred"`
+	} `json:"tests"`
+}
+
+type kdf108TestResponse struct {
+	ID        int      `json:"tcId"`
+	KeyOut    hexBytes `json:"keyOut"`
+	FixedData hexBytes `json:"fixedData"`
+}
+
+// kdf108Label is the label of the fixed input data that fipsacvp
+// chooses for every SP 800-108 test case. The context is the tcId.
+var kdf108Label = []byte("fipsacvp")
+
+// processKDF108 answers SP 800-108 requests. The IUT chooses the fixed
+// input data, which is label || 0x00 || context || L as built by
+// crypto/kdf, so only groups with a 32-bit counter before the fixed data
+// are supported.
+func processKDF108(vs *vectorSet) (interface{}, error) {
+	var groups []kdf108TestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		if g.Type != "AFT" {
+			return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+		}
+		if g.KDFMode != "counter" || g.CounterLocation != "before fixed data" || g.CounterLength != 32 {
+			return nil, fmt.Errorf("tgId %d: unsupported %s mode with a %d-bit counter %s", g.ID, g.KDFMode, g.CounterLength, g.CounterLocation)
+		}
+		if g.KeyOutLength <= 0 || g.KeyOutLength%8 != 0 {
+			return nil, fmt.Errorf("tgId %d: unsupported key length %d", g.ID, g.KeyOutLength)
+		}
+		derive, err := kdf108Func(g.MACMode)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		length := g.KeyOutLength / 8
+		var tests []kdf108TestResponse
+		for _, tc := range g.Tests {
+			context := make([]byte, 4)
+			binary.BigEndian.PutUint32(context, uint32(tc.ID))
+			out, err := derive(tc.KeyIn, kdf108Label, context, length)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			tests = append(tests, kdf108TestResponse{ID: tc.ID, KeyOut: out, FixedData: kdf108FixedData(context, g.KeyOutLength)})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
+
+// kdf108FixedData returns the fixed input data that crypto/kdf builds
+// from kdf108Label and context for an output of bits bits.
+func kdf108FixedData(context []byte, bits int) []byte {
+	fixed := make([]byte, 0, len(kdf108Label)+1+len(context)+4)
+	fixed = append(fixed, kdf108Label...)
+	fixed = append(fixed, 0)
+	fixed = append(fixed, context...)
+	var l [4]byte
+	binary.BigEndian.PutUint32(l[:], uint32(bits))
+	return append(fixed, l[:]...)
+}
+
+// kdf108Func returns the crypto/kdf function for the ACVP MAC name.
+func kdf108Func(macMode string) (func(key, label, context []byte, length int) ([]byte, error), error) {
+	switch macMode {
+	case "CMAC-AES128", "CMAC-AES192", "CMAC-AES256":
+		return kdf.CounterCMAC, nil
+	}
+	if !strings.HasPrefix(macMode, "HMAC-") {
+		return nil, fmt.Errorf("unsupported MAC %q", macMode)
+	}
+	h, err := lookupHash(strings.TrimPrefix(macMode, "HMAC-"))
+	if err != nil {
+		return nil, err
+	}
+	return func(key, label, context []byte, length int) ([]byte, error) {
+		return kdf.CounterHMAC(h.New, key, label, context, length)
+	}, nil
+}
+
+type x963TestGroup struct {
+	ID               int    `json:"tgId"`
+	Type             string `json:"testType"`
+	FieldSize        int    `json:"fieldSize"`
+	HashAlg          string `json:"hashAlg"`
+	SharedInfoLength int    `json:"sharedInfoLength"`
+	KeyDataLength    int    `json:"keyDataLength"`
+	Tests            []struct {
+		ID         int      `json:"tcId"`
+		Z          hexBytes `json:"z"`
+		SharedInfo hexBytes `json:"sharedInfo"`
+	} `json:"tests"`
+}
+
+type x963TestResponse struct {
+	ID      int      `json:"tcId"`
+	KeyData hexBytes `json:"keyData"`
+}
+
+func processX963(vs *vectorSet) (interface{}, error) {
+	var groups []x963TestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		if g.Type != "AFT" {
+			return nil, fmt.Errorf("tgId %d: %v %q", g.ID, errUnsupportedTestType, g.Type)
+		}
+		if g.KeyDataLength <= 0 || g.KeyDataLength%8 != 0 {
+			return nil, fmt.Errorf("tgId %d: unsupported key data length %d", g.ID, g.KeyDataLength)
+		}
+		h, err := lookupHash(g.HashAlg)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		var tests []x963TestResponse
+		for _, tc := range g.Tests {
+			info, err := tc.SharedInfo.bits(g.SharedInfoLength)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			out, err := kdf.X963(h.New, tc.Z, info, g.KeyDataLength/8)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			tests = append(tests, x963TestResponse{ID: tc.ID, KeyData: out})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
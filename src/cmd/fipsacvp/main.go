@@ -0,0 +1,194 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"crypto/boring"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: go tool fipsacvp [-o response.json] [-allow-nonfips] request.json\n")
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+var (
+	outFlag          = flag.String("o", "", "write the response to `file` instead of standard output")
+	allowNonFIPSFlag = flag.Bool("allow-nonfips", false, "run even if the FIPS backend is not enabled")
+)
+
+func main() {
+	log.SetPrefix("fipsacvp: ")
+	log.SetFlags(0)
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+	}
+	if !boring.Enabled() && !*allowNonFIPSFlag {
+		log.Fatal("FIPS backend not enabled (set GOLANG_FIPS=1, or use -allow-nonfips)")
+	}
+
+	req, err := ioutil.ReadFile(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp, err := process(req)
+	if err != nil {
+		log.Fatalf("%s: %v", flag.Arg(0), err)
+	}
+	if *outFlag == "" {
+		os.Stdout.Write(resp)
+		return
+	}
+	if err := ioutil.WriteFile(*outFlag, resp, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// A vectorSet is the part of an ACVP request common to all algorithms.
+// The test groups are decoded by the handler for the algorithm.
+type vectorSet struct {
+	VsID       int             `json:"vsId"`
+	Algorithm  string          `json:"algorithm"`
+	Mode       string          `json:"mode,omitempty"`
+	Revision   string          `json:"revision"`
+	IsSample   bool            `json:"isSample,omitempty"`
+	TestGroups json.RawMessage `json:"testGroups"`
+}
+
+type vectorSetResponse struct {
+	VsID       int         `json:"vsId"`
+	Algorithm  string      `json:"algorithm"`
+	Mode       string      `json:"mode,omitempty"`
+	Revision   string      `json:"revision"`
+	IsSample   bool        `json:"isSample,omitempty"`
+	TestGroups interface{} `json:"testGroups"`
+}
+
+// A handler computes the response test groups for a vector set.
+type handler func(vs *vectorSet) (interface{}, error)
+
+// handlers maps "algorithm" or "algorithm/mode" to the handler for it.
+var handlers = map[string]handler{}
+
+func register(name string, h handler) {
+	if handlers[name] != nil {
+		panic("fipsacvp: duplicate handler for " + name)
+	}
+	handlers[name] = h
+}
+
+// process answers the ACVP request in req and returns the encoded response.
+func process(req []byte) ([]byte, error) {
+	req = bytes.TrimSpace(req)
+	if len(req) > 0 && req[0] == '[' {
+		var elems []json.RawMessage
+		if err := json.Unmarshal(req, &elems); err != nil {
+			return nil, err
+		}
+		if len(elems) != 2 {
+			return nil, fmt.Errorf("request array has %d elements, want version and vector set", len(elems))
+		}
+		var version map[string]interface{}
+		if err := json.Unmarshal(elems[0], &version); err != nil {
+			return nil, err
+		}
+		resp, err := processVectorSet(elems[1])
+		if err != nil {
+			return nil, err
+		}
+		return marshal([]interface{}{version, resp})
+	}
+	resp, err := processVectorSet(req)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(resp)
+}
+
+func processVectorSet(data []byte) (*vectorSetResponse, error) {
+	var vs vectorSet
+	if err := json.Unmarshal(data, &vs); err != nil {
+		return nil, err
+	}
+	name := vs.Algorithm
+	if vs.Mode != "" {
+		name += "/" + vs.Mode
+	}
+	h := handlers[name]
+	if h == nil {
+		return nil, fmt.Errorf("unsupported algorithm %q", name)
+	}
+	groups, err := h(&vs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	return &vectorSetResponse{
+		VsID:       vs.VsID,
+		Algorithm:  vs.Algorithm,
+		Mode:       vs.Mode,
+		Revision:   vs.Revision,
+		IsSample:   vs.IsSample,
+		TestGroups: groups,
+	}, nil
+}
+
+func marshal(v interface{}) ([]byte, error) {
+	out, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// hexBytes is a byte string encoded as hexadecimal, the ACVP convention.
+// Requests may use either case; responses use upper case.
+type hexBytes []byte
+
+func (h hexBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(hex.EncodeToString(h)))
+}
+
+func (h *hexBytes) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	*h = b
+	return nil
+}
+
+func hexPtr(b []byte) *hexBytes {
+	h := hexBytes(b)
+	return &h
+}
+
+// bits returns the first n bits of h, which must be a whole number of bytes.
+func (h hexBytes) bits(n int) ([]byte, error) {
+	if n%8 != 0 {
+		return nil, fmt.Errorf("bit length %d is not a multiple of 8", n)
+	}
+	if n/8 > len(h) {
+		return nil, fmt.Errorf("bit length %d exceeds the %d bytes given", n, len(h))
+	}
+	return h[:n/8], nil
+}
+
+var errUnsupportedTestType = errors.New("unsupported test type")
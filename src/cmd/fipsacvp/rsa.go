@@ -0,0 +1,140 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+func init() {
+	register("RSA/sigGen", processRSASigGen)
+	register("RSA/sigVer", processRSASigVer)
+}
+
+type rsaTestGroup struct {
+	ID      int      `json:"tgId"`
+	Type    string   `json:"testType"`
+	SigType string   `json:"sigType"`
+	Modulo  int      `json:"modulo"`
+	HashAlg string   `json:"hashAlg"`
+	SaltLen int      `json:"saltLen"`
+	N       hexBytes `json:"n"`
+	E       hexBytes `json:"e"`
+	Tests   []struct {
+		ID        int      `json:"tcId"`
+		Message   hexBytes `json:"message"`
+		Signature hexBytes `json:"signature"`
+	} `json:"tests"`
+}
+
+type rsaSigGenGroupResponse struct {
+	ID    int                     `json:"tgId"`
+	N     hexBytes                `json:"n"`
+	E     hexBytes                `json:"e"`
+	Tests []rsaSigGenTestResponse `json:"tests"`
+}
+
+type rsaSigGenTestResponse struct {
+	ID        int      `json:"tcId"`
+	Signature hexBytes `json:"signature"`
+}
+
+type sigVerTestResponse struct {
+	ID     int  `json:"tcId"`
+	Passed bool `json:"testPassed"`
+}
+
+func decodeRSAGroups(vs *vectorSet) ([]rsaTestGroup, error) {
+	var groups []rsaTestGroup
+	if err := json.Unmarshal(vs.TestGroups, &groups); err != nil {
+		return nil, err
+	}
+	for _, g := range groups {
+		if g.SigType != "pkcs1v1.5" && g.SigType != "pss" {
+			return nil, fmt.Errorf("tgId %d: unsupported signature type %q", g.ID, g.SigType)
+		}
+	}
+	return groups, nil
+}
+
+func processRSASigGen(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeRSAGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []rsaSigGenGroupResponse
+	for _, g := range groups {
+		h, err := lookupHash(g.HashAlg)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		priv, err := rsa.GenerateKey(rand.Reader, g.Modulo)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		r := rsaSigGenGroupResponse{
+			ID: g.ID,
+			N:  priv.N.Bytes(),
+			E:  big.NewInt(int64(priv.E)).Bytes(),
+		}
+		for _, tc := range g.Tests {
+			sig, err := rsaSign(priv, g.SigType, h, g.SaltLen, tc.Message)
+			if err != nil {
+				return nil, fmt.Errorf("tcId %d: %v", tc.ID, err)
+			}
+			r.Tests = append(r.Tests, rsaSigGenTestResponse{ID: tc.ID, Signature: sig})
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
+
+func rsaSign(priv *rsa.PrivateKey, sigType string, h crypto.Hash, saltLen int, msg []byte) ([]byte, error) {
+	if sigType == "pss" {
+		return rsa.HashSignPSS(rand.Reader, priv, h, msg, &rsa.PSSOptions{SaltLength: saltLen})
+	}
+	s, err := rsa.NewHashSignerPKCS1v15(rand.Reader, priv, h)
+	if err != nil {
+		return nil, err
+	}
+	s.Write(msg)
+	return s.Sign()
+}
+
+func processRSASigVer(vs *vectorSet) (interface{}, error) {
+	groups, err := decodeRSAGroups(vs)
+	if err != nil {
+		return nil, err
+	}
+	var resp []testGroupResponse
+	for _, g := range groups {
+		h, err := lookupHash(g.HashAlg)
+		if err != nil {
+			return nil, fmt.Errorf("tgId %d: %v", g.ID, err)
+		}
+		e := new(big.Int).SetBytes(g.E)
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("tgId %d: public exponent too large", g.ID)
+		}
+		pub := &rsa.PublicKey{N: new(big.Int).SetBytes(g.N), E: int(e.Int64())}
+		var tests []sigVerTestResponse
+		for _, tc := range g.Tests {
+			var err error
+			if g.SigType == "pss" {
+				err = rsa.HashVerifyPSS(pub, h, tc.Message, tc.Signature, &rsa.PSSOptions{SaltLength: g.SaltLen})
+			} else {
+				err = rsa.HashVerifyPKCS1v15(pub, h, tc.Message, tc.Signature)
+			}
+			tests = append(tests, sigVerTestResponse{ID: tc.ID, Passed: err == nil})
+		}
+		resp = append(resp, testGroupResponse{ID: g.ID, Tests: tests})
+	}
+	return resp, nil
+}
@@ -1486,7 +1486,7 @@
 //
 // Usage:
 //
-// 	go version [-m] [-v] [file ...]
+// 	go version [-m] [-v] [-json] [file ...]
 //
 // Version prints the build information for Go executables.
 //
@@ -1504,7 +1504,14 @@
 // The -m flag causes go version to print each executable's embedded
 // module version information, when available. In the output, the module
 // information consists of multiple lines following the version line, each
-// indented by a leading tab character.
+// indented by a leading tab character. On amd64, it also reports which
+// crypto implementations were linked in, as "crypto" lines naming
+// boringcrypto (the OpenSSL FIPS backend), fipsonly (crypto/tls/fipsonly)
+// or standardcrypto (standard Go crypto).
+//
+// The -json flag causes go version to print a JSON object for each
+// executable instead, with fields File, GoVersion and, with -m,
+// ModuleInfo and Crypto.
 //
 // See also: go doc runtime/debug.BuildInfo.
 //
@@ -25,6 +25,10 @@ type exe interface {
 
 	// DataStart returns the writable data segment start address.
 	DataStart() uint64
+
+	// TextRange returns the start and end addresses of the text section,
+	// or 0, 0 if there is none.
+	TextRange() (start, end uint64)
 }
 
 // openExe opens file and returns it as an exe.
@@ -116,6 +120,13 @@ func (x *elfExe) DataStart() uint64 {
 	return 0
 }
 
+func (x *elfExe) TextRange() (start, end uint64) {
+	if s := x.f.Section(".text"); s != nil {
+		return s.Addr, s.Addr + s.Size
+	}
+	return 0, 0
+}
+
 // peExe is the PE (Windows Portable Executable) implementation of the exe interface.
 type peExe struct {
 	os *os.File
@@ -177,6 +188,14 @@ func (x *peExe) DataStart() uint64 {
 	return 0
 }
 
+func (x *peExe) TextRange() (start, end uint64) {
+	if s := x.f.Section(".text"); s != nil {
+		start = uint64(s.VirtualAddress) + x.imageBase()
+		return start, start + uint64(s.VirtualSize)
+	}
+	return 0, 0
+}
+
 // machoExe is the Mach-O (Apple macOS/iOS) implementation of the exe interface.
 type machoExe struct {
 	os *os.File
@@ -230,6 +249,13 @@ func (x *machoExe) DataStart() uint64 {
 	return 0
 }
 
+func (x *machoExe) TextRange() (start, end uint64) {
+	if s := x.f.Section("__text"); s != nil {
+		return s.Addr, s.Addr + s.Size
+	}
+	return 0, 0
+}
+
 // xcoffExe is the XCOFF (AIX eXtended COFF) implementation of the exe interface.
 type xcoffExe struct {
 	os *os.File
@@ -261,3 +287,10 @@ func (x *xcoffExe) ReadData(addr, size uint64) ([]byte, error) {
 func (x *xcoffExe) DataStart() uint64 {
 	return x.f.SectionByType(xcoff.STYP_DATA).VirtualAddress
 }
+
+func (x *xcoffExe) TextRange() (start, end uint64) {
+	if s := x.f.SectionByType(xcoff.STYP_TEXT); s != nil {
+		return s.VirtualAddress, s.VirtualAddress + s.Size
+	}
+	return 0, 0
+}
@@ -9,6 +9,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"os"
@@ -20,7 +21,7 @@ import (
 )
 
 var CmdVersion = &base.Command{
-	UsageLine: "go version [-m] [-v] [file ...]",
+	UsageLine: "go version [-m] [-v] [-json] [file ...]",
 	Short:     "print Go version",
 	Long: `Version prints the build information for Go executables.
 
@@ -38,7 +39,14 @@ during a directory scan. The -v flag causes it to report unrecognized files.
 The -m flag causes go version to print each executable's embedded
 module version information, when available. In the output, the module
 information consists of multiple lines following the version line, each
-indented by a leading tab character.
+indented by a leading tab character. On amd64, it also reports which
+crypto implementations were linked in, as "crypto" lines naming
+boringcrypto (the OpenSSL FIPS backend), fipsonly (crypto/tls/fipsonly)
+or standardcrypto (standard Go crypto).
+
+The -json flag causes go version to print a JSON object for each
+executable instead, with fields File, GoVersion and, with -m,
+ModuleInfo and Crypto.
 
 See also: go doc runtime/debug.BuildInfo.
 `,
@@ -49,8 +57,9 @@ func init() {
 }
 
 var (
-	versionM = CmdVersion.Flag.Bool("m", false, "")
-	versionV = CmdVersion.Flag.Bool("v", false, "")
+	versionM    = CmdVersion.Flag.Bool("m", false, "")
+	versionV    = CmdVersion.Flag.Bool("v", false, "")
+	versionJSON = CmdVersion.Flag.Bool("json", false, "")
 )
 
 func runVersion(ctx context.Context, cmd *base.Command, args []string) {
@@ -62,7 +71,7 @@ func runVersion(ctx context.Context, cmd *base.Command, args []string) {
 		// a reasonable use case. For example, imagine GOFLAGS=-v to
 		// turn "verbose mode" on for all Go commands, which should not
 		// break "go version".
-		if (!base.InGOFLAGS("-m") && *versionM) || (!base.InGOFLAGS("-v") && *versionV) {
+		if (!base.InGOFLAGS("-m") && *versionM) || (!base.InGOFLAGS("-v") && *versionV) || (!base.InGOFLAGS("-json") && *versionJSON) {
 			fmt.Fprintf(os.Stderr, "go version: flags can only be used with arguments\n")
 			base.SetExitStatus(2)
 			return
@@ -152,10 +161,39 @@ func scanFile(file string, info fs.FileInfo, mustPrint bool) {
 		return
 	}
 
+	var crypto []string
+	if *versionM {
+		crypto = findCrypto(x)
+	}
+
+	if *versionJSON {
+		v := versionInfo{File: file, GoVersion: vers, Crypto: crypto}
+		if *versionM {
+			v.ModuleInfo = mod
+		}
+		js, err := json.MarshalIndent(v, "", "\t")
+		if err != nil {
+			base.Fatalf("go version: %v", err)
+		}
+		fmt.Printf("%s\n", js)
+		return
+	}
+
 	fmt.Printf("%s: %s\n", file, vers)
 	if *versionM && mod != "" {
 		fmt.Printf("\t%s\n", strings.ReplaceAll(mod[:len(mod)-1], "\n", "\n\t"))
 	}
+	for _, c := range crypto {
+		fmt.Printf("\tcrypto\t%s\n", c)
+	}
+}
+
+// versionInfo is the -json output for an executable.
+type versionInfo struct {
+	File       string
+	GoVersion  string
+	ModuleInfo string   `json:",omitempty"`
+	Crypto     []string `json:",omitempty"`
 }
 
 // The build info blob left by the linker is identified by
@@ -222,3 +260,59 @@ func readString(x exe, ptrSize int, readPtr func([]byte) uint64, addr uint64) st
 	}
 	return string(data)
 }
+
+// The functions in crypto/internal/boring/sig have recognizable
+// implementations on amd64: a two-byte jump over a 5-byte indicator
+// sequence and 24 bytes identifying the function, then a return.
+// See crypto/internal/boring/sig/sig_amd64.s.
+var cryptoMarkers = []struct {
+	name string
+	code []byte
+}{
+	{"boringcrypto", []byte("\xEB\x1D\xF4\x48\xF4\x4B\xF4" +
+		"\xB3\x32\xF5\x28\x13\xA3\xB4\x50\xD4\x41\xCC\x24\x85\xF0\x01\x45\x4E\x92\x10\x1B\x1D\x2F\x19\x50" +
+		"\xC3")},
+	{"fipsonly", []byte("\xEB\x1D\xF4\x48\xF4\x4B\xF4" +
+		"\x36\x3C\xB9\xCE\x9D\x68\x04\x7D\x31\xF2\x8D\x32\x5D\x5C\xA5\x87\x3F\x5D\x80\xCA\xF6\xD6\x15\x1B" +
+		"\xC3")},
+	{"standardcrypto", []byte("\xEB\x1D\xF4\x48\xF4\x4B\xF4" +
+		"\xBA\xEE\x4D\xFA\x98\x51\xCA\x56\xA9\x11\x45\xE8\x3E\x99\xC5\x9C\xF9\x11\xCB\x8E\x80\xDA\xF1\x2F" +
+		"\xC3")},
+}
+
+// findCrypto scans the text of the executable x for the crypto
+// marker functions and returns the names of those present.
+func findCrypto(x exe) []string {
+	const (
+		chunk   = 1 << 20
+		overlap = 32 - 1 // so markers spanning chunks are seen
+	)
+	found := make([]bool, len(cryptoMarkers))
+	start, end := x.TextRange()
+	for addr := start; addr < end; {
+		n := end - addr
+		if n > chunk+overlap {
+			n = chunk + overlap
+		}
+		data, err := x.ReadData(addr, n)
+		if err != nil {
+			break
+		}
+		for i, m := range cryptoMarkers {
+			if !found[i] && bytes.Contains(data, m.code) {
+				found[i] = true
+			}
+		}
+		if len(data) <= overlap {
+			break
+		}
+		addr += uint64(len(data) - overlap)
+	}
+	var names []string
+	for i, m := range cryptoMarkers {
+		if found[i] {
+			names = append(names, m.name)
+		}
+	}
+	return names
+}
@@ -75,6 +75,13 @@ Flags:
 		Set space-separated flags to pass to the external linker.
 	-f
 		Ignore version mismatch in the linked archives.
+	-fipsonly
+		Fail the link if a crypto package was built without the OpenSSL
+		backend, listing each function that reaches standard Go crypto.
+		This only checks for the crypto/internal/boring/sig.StandardCrypto
+		marker. A binary using the OpenSSL backend passes, but it still
+		contains the standard Go code and runs it unless FIPS mode is
+		enabled at run time.
 	-g
 		Disable Go package data checks.
 	-importcfg file
@@ -0,0 +1,88 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ld
+
+import (
+	"cmd/internal/objabi"
+	"cmd/link/internal/loader"
+	"cmd/link/internal/sym"
+	"sort"
+	"strings"
+)
+
+// standardCryptoSym is the marker that boring.Unreachable links into
+// binaries using standard Go crypto. See crypto/internal/boring/sig.
+const standardCryptoSym = "crypto/internal/boring/sig.StandardCrypto"
+
+// checkFIPSOnly implements -fipsonly. It fails the link if the standard
+// Go crypto marker is reachable, reporting each reachable function that
+// calls it and the chain of references from main that reached it.
+// It must run after deadcode, with Reachparent populated.
+//
+// Only builds without the OpenSSL backend link the marker. With the
+// backend, the standard Go fallback is still reachable through
+// boring.Unreachable, which panics only when FIPS mode is enabled at
+// run time, so passing this check does not mean the binary never runs
+// standard Go crypto.
+func checkFIPSOnly(ctxt *Link) {
+	ldr := ctxt.loader
+	if objabi.Fieldtrack_enabled == 0 {
+		defer func() { ldr.Reachparent = nil }()
+	}
+
+	markers := make(map[loader.Sym]bool)
+	for _, v := range []int{sym.SymVerABI0, sym.SymVerABIInternal} {
+		if s := ldr.Lookup(standardCryptoSym, v); s != 0 && ldr.AttrReachable(s) {
+			markers[s] = true
+			markers[ldr.ResolveABIAlias(s)] = true
+		}
+	}
+	if len(markers) == 0 {
+		return
+	}
+
+	// boring.Unreachable is inlined, so look for the marker call
+	// in every reachable function rather than for calls to it.
+	var callers []loader.Sym
+	for s := loader.Sym(1); s < loader.Sym(ldr.NSym()); s++ {
+		if !ldr.AttrReachable(s) || ldr.SymType(s) != sym.STEXT || markers[s] {
+			continue
+		}
+		relocs := ldr.Relocs(s)
+		for ri := 0; ri < relocs.Count(); ri++ {
+			if markers[ldr.ResolveABIAlias(relocs.At(ri).Sym())] {
+				callers = append(callers, s)
+				break
+			}
+		}
+	}
+	sort.Slice(callers, func(i, j int) bool {
+		return ldr.SymName(callers[i]) < ldr.SymName(callers[j])
+	})
+
+	var buf strings.Builder
+	buf.WriteString("-fipsonly: standard Go crypto is reachable")
+	for _, s := range callers {
+		buf.WriteString("\n\t")
+		buf.WriteString(ldr.SymName(s))
+		if pkg := ldr.SymPkg(s); pkg != "" {
+			buf.WriteString(" (package " + pkg + ")")
+		}
+		var path []string
+		for p := ldr.Reachparent[s]; p != 0 && len(path) < 32; p = ldr.Reachparent[p] {
+			// ABI aliases share the name of their target.
+			if name := ldr.SymName(p); len(path) == 0 || path[len(path)-1] != name {
+				path = append(path, name)
+			}
+		}
+		if len(path) > 0 {
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			buf.WriteString("\n\t\treached from " + strings.Join(path, " -> "))
+		}
+	}
+	Exitf("%s", buf.String())
+}
@@ -0,0 +1,68 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ld
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const fipsOnlySrc = `
+package main
+
+import "crypto/sha256"
+
+func main() {
+	println(sha256.Sum256([]byte("hello"))[0])
+}
+`
+
+func TestFIPSOnly(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	t.Parallel()
+
+	tmpdir, err := ioutil.TempDir("", "TestFIPSOnly")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	src := filepath.Join(tmpdir, "main.go")
+	if err := ioutil.WriteFile(src, []byte(fipsOnlySrc), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	build := func(cgo string) ([]byte, error) {
+		cmd := exec.Command(testenv.GoToolPath(t), "build", "-ldflags=-fipsonly", "-o", filepath.Join(tmpdir, "main.exe"), src)
+		cmd.Env = append(os.Environ(), "CGO_ENABLED="+cgo)
+		return cmd.CombinedOutput()
+	}
+
+	// Without cgo there is no OpenSSL backend, so the link must fail
+	// and name the function that uses standard Go crypto.
+	out, err := build("0")
+	if err == nil {
+		t.Fatalf("link with -fipsonly and CGO_ENABLED=0 succeeded; want error")
+	}
+	for _, want := range []string{"standard Go crypto is reachable", "crypto/sha256.", "reached from "} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		return
+	}
+	testenv.MustHaveCGO(t)
+	if out, err := build("1"); err != nil {
+		t.Errorf("link with -fipsonly using the OpenSSL backend failed: %v\n%s", err, out)
+	}
+}
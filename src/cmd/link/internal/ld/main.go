@@ -66,6 +66,7 @@ var (
 	flagRace          = flag.Bool("race", false, "enable race detector")
 	flagMsan          = flag.Bool("msan", false, "enable MSan interface")
 	flagAslr          = flag.Bool("aslr", true, "enable ASLR for buildmode=c-shared on windows")
+	flagFIPSOnly      = flag.Bool("fipsonly", false, "fail if crypto is built without the OpenSSL backend (see crypto/internal/boring/sig)")
 
 	flagFieldTrack = flag.String("k", "", "set field tracking `symbol`")
 	flagLibGCC     = flag.String("libgcc", "", "compiler support lib for internal linking; use \"none\" to disable")
@@ -245,6 +246,9 @@ func Main(arch *sys.Arch, theArch Arch) {
 
 	bench.Start("deadcode")
 	deadcode(ctxt)
+	if *flagFIPSOnly {
+		checkFIPSOnly(ctxt)
+	}
 
 	bench.Start("linksetup")
 	ctxt.linksetup()
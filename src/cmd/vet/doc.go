@@ -36,6 +36,7 @@ To list the available checks, run "go tool vet help":
     cgocall      detect some violations of the cgo pointer passing rules
     composites   check for unkeyed composite literals
     copylocks    check for locks erroneously passed by value
+    fips         report crypto usage that is not allowed in strict FIPS mode
     httpresponse check for mistakes using HTTP responses
     loopclosure  check references to loop variables from within nested functions
     lostcancel   check cancel func returned by context.WithCancel is called
@@ -0,0 +1,286 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package fips defines an Analyzer that reports crypto usage that is
+// not allowed when running with the OpenSSL FIPS backend in strict mode.
+package fips
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"os"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"golang.org/x/tools/go/types/typeutil"
+)
+
+const Doc = `report crypto usage that is not allowed in strict FIPS mode
+
+The fips checker reports, at compile time, what GOLANG_STRICT_FIPS=1
+would otherwise only reveal at run time:
+
+- any use of crypto/md5, crypto/des, crypto/rc4, crypto/dsa or
+  crypto/ed25519;
+- signing or verifying a precomputed digest with ecdsa.Sign, ecdsa.Verify,
+  ecdsa.SignASN1, ecdsa.VerifyASN1, (*ecdsa.PrivateKey).Sign,
+  rsa.SignPKCS1v15, rsa.VerifyPKCS1v15 or (*rsa.PrivateKey).Sign with
+  PKCS #1 v1.5 options;
+- tls.Config cipher suites and curve preferences that FIPS mode drops.
+
+The check only reports when the -fips.strict flag is set, which is the
+default if GOLANG_STRICT_FIPS=1 is set in the environment.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "fips",
+	Doc:      Doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var strict bool
+
+func init() {
+	Analyzer.Flags.BoolVar(&strict, "strict", os.Getenv("GOLANG_STRICT_FIPS") == "1",
+		"report non-FIPS crypto usage (defaults to true if GOLANG_STRICT_FIPS=1)")
+}
+
+// nonFIPSPackages are the crypto packages with no FIPS approved use.
+var nonFIPSPackages = map[string]bool{
+	"crypto/md5":     true,
+	"crypto/des":     true,
+	"crypto/rc4":     true,
+	"crypto/dsa":     true,
+	"crypto/ed25519": true,
+}
+
+// digestFuncs maps functions that sign or verify a precomputed digest,
+// which PanicIfStrictFIPS rejects, to the replacement to suggest.
+var digestFuncs = map[string]string{
+	"crypto/ecdsa.Sign":               "ecdsa.HashSign",
+	"crypto/ecdsa.SignASN1":           "ecdsa.HashSign",
+	"(*crypto/ecdsa.PrivateKey).Sign": "ecdsa.HashSign",
+	"crypto/ecdsa.Verify":             "ecdsa.HashVerify",
+	"crypto/ecdsa.VerifyASN1":         "ecdsa.HashVerify",
+	"crypto/ecdsa.SignRaw":            "ecdsa.HashSign",
+	"crypto/ecdsa.VerifyRaw":          "ecdsa.HashVerify",
+	"crypto/rsa.SignPKCS1v15":         "rsa.NewHashSignerPKCS1v15",
+	"(*crypto/rsa.PrivateKey).Sign":   "rsa.NewHashSignerPKCS1v15",
+	"crypto/rsa.VerifyPKCS1v15":       "rsa.HashVerifyPKCS1v15",
+}
+
+// The FIPS cipher suites and curves must match defaultFIPSCipherSuites
+// and defaultFIPSCurvePreferences in crypto/tls/boring.go. They are kept
+// here by value, rather than by importing crypto/tls, so that vet does
+// not link the crypto/tls (and OpenSSL) code to read a few constants.
+var fipsCipherSuites = map[uint16]bool{
+	0xc02f: true, // TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	0xc030: true, // TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384
+	0xc02b: true, // TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256
+	0xc02c: true, // TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384
+	0x009c: true, // TLS_RSA_WITH_AES_128_GCM_SHA256
+	0x009d: true, // TLS_RSA_WITH_AES_256_GCM_SHA384
+}
+
+var fipsCurves = map[uint16]bool{
+	23: true, // CurveP256
+	24: true, // CurveP384
+	25: true, // CurveP521
+}
+
+// cipherSuiteNames and curveNames mirror the names crypto/tls uses, for
+// messages.
+var cipherSuiteNames = map[uint16]string{
+	0x0005: "TLS_RSA_WITH_RC4_128_SHA",
+	0x000a: "TLS_RSA_WITH_3DES_EDE_CBC_SHA",
+	0x002f: "TLS_RSA_WITH_AES_128_CBC_SHA",
+	0x0035: "TLS_RSA_WITH_AES_256_CBC_SHA",
+	0x003c: "TLS_RSA_WITH_AES_128_CBC_SHA256",
+	0x009c: "TLS_RSA_WITH_AES_128_GCM_SHA256",
+	0x009d: "TLS_RSA_WITH_AES_256_GCM_SHA384",
+	0xc007: "TLS_ECDHE_ECDSA_WITH_RC4_128_SHA",
+	0xc009: "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA",
+	0xc00a: "TLS_ECDHE_ECDSA_WITH_AES_256_CBC_SHA",
+	0xc011: "TLS_ECDHE_RSA_WITH_RC4_128_SHA",
+	0xc012: "TLS_ECDHE_RSA_WITH_3DES_EDE_CBC_SHA",
+	0xc013: "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA",
+	0xc014: "TLS_ECDHE_RSA_WITH_AES_256_CBC_SHA",
+	0xc023: "TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA256",
+	0xc027: "TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA256",
+	0xc02f: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256",
+	0xc02b: "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
+	0xc030: "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384",
+	0xc02c: "TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
+	0xcca8: "TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256",
+	0xcca9: "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256",
+	0x1301: "TLS_AES_128_GCM_SHA256",
+	0x1302: "TLS_AES_256_GCM_SHA384",
+	0x1303: "TLS_CHACHA20_POLY1305_SHA256",
+	0x5600: "TLS_FALLBACK_SCSV",
+}
+
+var curveNames = map[uint16]string{
+	23: "CurveP256",
+	24: "CurveP384",
+	25: "CurveP521",
+	29: "X25519",
+}
+
+func cipherSuiteName(id uint16) string {
+	if name, ok := cipherSuiteNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", id)
+}
+
+func curveName(id uint16) string {
+	if name, ok := curveNames[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("CurveID(%d)", id)
+}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	if !strict || nonFIPSPackages[pass.Pkg.Path()] {
+		return nil, nil
+	}
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+
+	nodeFilter := []ast.Node{
+		(*ast.Ident)(nil),
+		(*ast.CallExpr)(nil),
+		(*ast.CompositeLit)(nil),
+		(*ast.AssignStmt)(nil),
+	}
+	inspect.Preorder(nodeFilter, func(n ast.Node) {
+		switch n := n.(type) {
+		case *ast.Ident:
+			// Any use of a function, method, type, constant, variable
+			// or field declared in a non-FIPS package.
+			obj := pass.TypesInfo.Uses[n]
+			if obj == nil || obj.Pkg() == nil || !nonFIPSPackages[obj.Pkg().Path()] {
+				return
+			}
+			if _, ok := obj.(*types.PkgName); ok {
+				return
+			}
+			name := obj.Pkg().Path() + "." + obj.Name()
+			if fn, ok := obj.(*types.Func); ok {
+				name = fn.FullName()
+			}
+			pass.ReportRangef(n, "%s is not allowed in FIPS mode", name)
+		case *ast.CallExpr:
+			checkDigestCall(pass, n)
+		case *ast.CompositeLit:
+			if !isTLSConfig(pass.TypesInfo.Types[n].Type) {
+				return
+			}
+			for _, elt := range n.Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					if id, ok := kv.Key.(*ast.Ident); ok {
+						checkTLSField(pass, id.Name, kv.Value)
+					}
+				}
+			}
+		case *ast.AssignStmt:
+			if len(n.Lhs) != len(n.Rhs) {
+				return
+			}
+			for i, lhs := range n.Lhs {
+				sel, ok := lhs.(*ast.SelectorExpr)
+				if ok && isTLSConfig(pass.TypesInfo.Types[sel.X].Type) {
+					checkTLSField(pass, sel.Sel.Name, n.Rhs[i])
+				}
+			}
+		}
+	})
+	return nil, nil
+}
+
+func checkDigestCall(pass *analysis.Pass, call *ast.CallExpr) {
+	fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func)
+	if !ok {
+		return
+	}
+	name := fn.FullName()
+	alt, ok := digestFuncs[name]
+	if !ok {
+		return
+	}
+	switch name {
+	case "crypto/rsa.VerifyPKCS1v15":
+		// A zero hash means the input is not a digest at all, which the
+		// backend verifies without the strict FIPS check.
+		if len(call.Args) == 4 && isZeroConst(pass, call.Args[1]) {
+			return
+		}
+	case "(*crypto/rsa.PrivateKey).Sign":
+		// Only PKCS #1 v1.5 signing is rejected. Report it only when the
+		// options are statically known not to be *rsa.PSSOptions.
+		if len(call.Args) != 3 {
+			return
+		}
+		t := pass.TypesInfo.Types[call.Args[2]].Type
+		if t == nil || types.IsInterface(t) || isNamed(t, "crypto/rsa", "PSSOptions") {
+			return
+		}
+	}
+	pass.ReportRangef(call, "%s signs or verifies a precomputed digest, which strict FIPS mode rejects; use %s with the raw message", name, alt)
+}
+
+func checkTLSField(pass *analysis.Pass, field string, value ast.Expr) {
+	lit, ok := value.(*ast.CompositeLit)
+	if !ok {
+		return
+	}
+	for _, elt := range lit.Elts {
+		v := pass.TypesInfo.Types[elt].Value
+		if v == nil || v.Kind() != constant.Int {
+			continue
+		}
+		id, ok := constant.Uint64Val(v)
+		if !ok {
+			continue
+		}
+		switch field {
+		case "CipherSuites":
+			if !fipsCipherSuites[uint16(id)] {
+				pass.ReportRangef(elt, "cipher suite %s is not allowed in FIPS mode", cipherSuiteName(uint16(id)))
+			}
+		case "CurvePreferences":
+			if !fipsCurves[uint16(id)] {
+				pass.ReportRangef(elt, "curve %s is not allowed in FIPS mode", curveName(uint16(id)))
+			}
+		}
+	}
+}
+
+func isZeroConst(pass *analysis.Pass, e ast.Expr) bool {
+	v := pass.TypesInfo.Types[e].Value
+	return v != nil && constant.Sign(v) == 0
+}
+
+// isTLSConfig reports whether t is tls.Config or a pointer to it.
+func isTLSConfig(t types.Type) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	return isNamed(t, "crypto/tls", "Config")
+}
+
+// isNamed reports whether t, or the type it points to, is pkg.name.
+func isNamed(t types.Type, pkg, name string) bool {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := n.Obj()
+	return obj.Name() == name && obj.Pkg() != nil && obj.Pkg().Path() == pkg
+}
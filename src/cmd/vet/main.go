@@ -2,6 +2,7 @@ package main
 
 import (
 	"cmd/internal/objabi"
+	"cmd/vet/internal/fips"
 
 	"golang.org/x/tools/go/analysis/unitchecker"
 
@@ -46,6 +47,7 @@ func main() {
 		composite.Analyzer,
 		copylock.Analyzer,
 		errorsas.Analyzer,
+		fips.Analyzer,
 		framepointer.Analyzer,
 		httpresponse.Analyzer,
 		ifaceassert.Analyzer,
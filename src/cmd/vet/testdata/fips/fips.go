@@ -0,0 +1,64 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// This file contains tests for the fips checker.
+
+package fips
+
+import (
+	"crypto"
+	"crypto/des"
+	"crypto/ecdsa"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"math/big"
+)
+
+func Hashes(data []byte) {
+	md5.Sum(data) // ERROR "crypto/md5.Sum is not allowed in FIPS mode"
+	h := md5.New  // ERROR "crypto/md5.New is not allowed in FIPS mode"
+	_ = h
+
+	// Constants and types are reported too.
+	var b [md5.Size]byte     // ERROR "crypto/md5.Size is not allowed in FIPS mode"
+	var err des.KeySizeError // ERROR "crypto/des.KeySizeError is not allowed in FIPS mode"
+	_, _ = b, err
+}
+
+func ECDSA(priv *ecdsa.PrivateKey, digest []byte) {
+	r, s, _ := ecdsa.Sign(rand.Reader, priv, digest)   // ERROR "crypto/ecdsa.Sign signs or verifies a precomputed digest.*use ecdsa.HashSign"
+	ecdsa.Verify(&priv.PublicKey, digest, r, s)        // ERROR "crypto/ecdsa.Verify signs or verifies a precomputed digest.*use ecdsa.HashVerify"
+	ecdsa.SignASN1(rand.Reader, priv, digest)          // ERROR "crypto/ecdsa.SignASN1 signs"
+	priv.Sign(rand.Reader, digest, crypto.SHA256)      // ERROR "\(\*crypto/ecdsa.PrivateKey\).Sign signs"
+	sig, _ := ecdsa.SignRaw(rand.Reader, priv, digest) // ERROR "crypto/ecdsa.SignRaw signs.*use ecdsa.HashSign"
+	ecdsa.VerifyRaw(&priv.PublicKey, digest, sig)      // ERROR "crypto/ecdsa.VerifyRaw signs.*use ecdsa.HashVerify"
+
+	ecdsa.HashSign(rand.Reader, priv, digest, crypto.SHA256)
+	ecdsa.HashVerify(&priv.PublicKey, digest, new(big.Int), new(big.Int), crypto.SHA256)
+}
+
+func RSA(priv *rsa.PrivateKey, digest []byte, opts crypto.SignerOpts) {
+	rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest)      // ERROR "crypto/rsa.SignPKCS1v15 signs.*use rsa.NewHashSignerPKCS1v15"
+	rsa.VerifyPKCS1v15(&priv.PublicKey, crypto.SHA256, digest, nil) // ERROR "crypto/rsa.VerifyPKCS1v15 signs.*use rsa.HashVerifyPKCS1v15"
+	priv.Sign(rand.Reader, digest, crypto.SHA256)                   // ERROR "\(\*crypto/rsa.PrivateKey\).Sign signs"
+
+	// Raw PKCS #1 v1.5, PSS and dynamic options are allowed.
+	rsa.VerifyPKCS1v15(&priv.PublicKey, 0, digest, nil)
+	priv.Sign(rand.Reader, digest, &rsa.PSSOptions{Hash: crypto.SHA256})
+	priv.Sign(rand.Reader, digest, opts)
+}
+
+func TLS(c *tls.Config) {
+	_ = &tls.Config{
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305, // ERROR "cipher suite TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256 is not allowed in FIPS mode"
+		},
+		CurvePreferences: []tls.CurveID{tls.CurveP256, tls.X25519}, // ERROR "curve X25519 is not allowed in FIPS mode"
+	}
+	c.CurvePreferences = []tls.CurveID{tls.CurveP384}
+	c.CipherSuites = []uint16{tls.TLS_RSA_WITH_AES_128_CBC_SHA} // ERROR "cipher suite TLS_RSA_WITH_AES_128_CBC_SHA is not allowed in FIPS mode"
+}
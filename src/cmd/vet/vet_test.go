@@ -195,6 +195,24 @@ func TestTags(t *testing.T) {
 	}
 }
 
+func TestFIPS(t *testing.T) {
+	t.Parallel()
+	Build(t)
+
+	// Without -fips.strict the checker is silent.
+	cmd := vetCmd(t, "-fips.strict=false", "fips")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("vet -fips.strict=false: %v\n%s", err, out)
+	}
+
+	cmd = vetCmd(t, "-fips.strict", "fips")
+	files, err := filepath.Glob(filepath.Join("testdata", "fips", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errchk(cmd, files, t)
+}
+
 // All declarations below were adapted from test/run.go.
 
 // errorCheck matches errors in outStr against comments in source files.
@@ -0,0 +1,254 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boring
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/internal/boring"
+	"crypto/rsa"
+	"crypto/subtle"
+	"errors"
+	"io"
+)
+
+// ParsePrivateKey parses a DER-encoded PKCS #1 RSA, SEC 1 EC or
+// unencrypted PKCS #8 private key directly into an OpenSSL EVP_PKEY, which
+// all its private-key operations go through.
+//
+// The returned key is a crypto.Signer, and RSA keys are also
+// crypto.Decrypters. Unlike the keys returned by crypto/x509, they do not
+// expose the private components, which never leave the module. They can
+// be used wherever a crypto.Signer is accepted, such as in a
+// tls.Certificate or by x509.CreateCertificate.
+//
+// ParsePrivateKey returns an error if BoringCrypto is not enabled.
+func ParsePrivateKey(der []byte) (crypto.Signer, error) {
+	if !boring.Enabled() {
+		return nil, errors.New("crypto/boring: not enabled")
+	}
+	return newSigner(boring.ParsePrivateKey(der))
+}
+
+// LoadPrivateKey loads the first private key found at uri through
+// OpenSSL's OSSL_STORE API, which needs OpenSSL 1.1.1 or later. Besides
+// file paths and file: URIs, this reaches any store registered by a
+// configured engine or provider, such as pkcs11: URIs for keys held in
+// an HSM. Credentials such as a PIN must be part of the URI or of the
+// OpenSSL configuration.
+//
+// The returned key is used like those of ParsePrivateKey, and likewise
+// does not expose any private components. Keys that stay in a device are
+// only operated on through it.
+//
+// LoadPrivateKey returns an error if BoringCrypto is not enabled.
+func LoadPrivateKey(uri string) (crypto.Signer, error) {
+	if !boring.Enabled() {
+		return nil, errors.New("crypto/boring: not enabled")
+	}
+	return newSigner(boring.LoadPrivateKey(uri))
+}
+
+// LoadEnginePrivateKey is like LoadPrivateKey, but loads the key named
+// keyID with the OpenSSL ENGINE whose id is engine, as the openssl
+// command does with -engine engine -keyform engine. For example, the
+// pkcs11 engine of libp11 accepts PKCS #11 URIs as key IDs.
+//
+// LoadEnginePrivateKey returns an error if BoringCrypto is not enabled.
+func LoadEnginePrivateKey(engine, keyID string) (crypto.Signer, error) {
+	if !boring.Enabled() {
+		return nil, errors.New("crypto/boring: not enabled")
+	}
+	return newSigner(boring.LoadEnginePrivateKey(engine, keyID))
+}
+
+// newSigner wraps a key returned by the crypto/internal/boring loaders.
+func newSigner(k interface{}, err error) (crypto.Signer, error) {
+	if err != nil {
+		return nil, err
+	}
+	switch k := k.(type) {
+	case *boring.PrivateKeyRSA:
+		N, E := k.PublicKey()
+		if !E.IsInt64() || E.Int64() > 1<<31-1 {
+			return nil, errors.New("crypto/boring: RSA public exponent too large")
+		}
+		return &rsaKey{priv: k, pub: &rsa.PublicKey{N: N, E: int(E.Int64())}}, nil
+	case *boring.PrivateKeyECDSA:
+		name, X, Y, err := k.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		var curve elliptic.Curve
+		switch name {
+		case "P-224":
+			curve = elliptic.P224()
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, errors.New("crypto/boring: unsupported elliptic curve")
+		}
+		return &ecdsaKey{priv: k, pub: &ecdsa.PublicKey{Curve: curve, X: X, Y: Y}}, nil
+	}
+	return nil, errors.New("crypto/boring: unsupported private key type")
+}
+
+type rsaKey struct {
+	priv *boring.PrivateKeyRSA
+	pub  *rsa.PublicKey
+}
+
+func (k *rsaKey) Public() crypto.PublicKey {
+	return k.pub
+}
+
+// pssSaltLength resolves the special salt lengths of rsa.PSSOptions the
+// way rsa.SignPSS does.
+func (k *rsaKey) pssSaltLength(hash crypto.Hash, opts *rsa.PSSOptions) int {
+	if opts == nil {
+		return k.pub.Size() - 2 - hash.Size()
+	}
+	switch opts.SaltLength {
+	case rsa.PSSSaltLengthAuto:
+		return k.pub.Size() - 2 - hash.Size()
+	case rsa.PSSSaltLengthEqualsHash:
+		return hash.Size()
+	}
+	return opts.SaltLength
+}
+
+func (k *rsaKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		return boring.SignRSAPSS(k.priv, hash, digest, k.pssSaltLength(hash, pssOpts))
+	}
+	return boring.SignRSAPKCS1v15(k.priv, hash, digest, true)
+}
+
+func (k *rsaKey) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	if hash == 0 {
+		return k.Sign(rand, msg, opts)
+	}
+	var s *boring.DigestSigner
+	var err error
+	if pssOpts, ok := opts.(*rsa.PSSOptions); ok {
+		s, err = boring.NewDigestSignerRSAPSS(k.priv, hash, k.pssSaltLength(hash, pssOpts))
+	} else {
+		s, err = boring.NewDigestSignerRSAPKCS1v15(k.priv, hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.Write(msg)
+	return s.Sign()
+}
+
+func (k *rsaKey) Decrypt(rand io.Reader, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	switch opts := opts.(type) {
+	case nil:
+		return k.decryptPKCS1v15(ciphertext)
+
+	case *rsa.OAEPOptions:
+		mgfHash := opts.MGFHash
+		if mgfHash == 0 {
+			mgfHash = opts.Hash
+		}
+		out, err := boring.DecryptRSAOAEP(opts.Hash.New(), mgfHash.New(), k.priv, ciphertext, opts.Label)
+		if err != nil {
+			return nil, rsa.ErrDecryption
+		}
+		return out, nil
+
+	case *rsa.PKCS1v15DecryptOptions:
+		if l := opts.SessionKeyLen; l > 0 {
+			key := make([]byte, l)
+			if _, err := io.ReadFull(rand, key); err != nil {
+				return nil, err
+			}
+			if err := k.decryptSessionKey(ciphertext, key); err != nil {
+				return nil, err
+			}
+			return key, nil
+		}
+		return k.decryptPKCS1v15(ciphertext)
+
+	default:
+		return nil, errors.New("crypto/boring: invalid options for Decrypt")
+	}
+}
+
+func (k *rsaKey) decryptPKCS1v15(ciphertext []byte) ([]byte, error) {
+	out, err := boring.DecryptRSAPKCS1(k.priv, ciphertext)
+	if err != nil {
+		return nil, rsa.ErrDecryption
+	}
+	return out, nil
+}
+
+// decryptSessionKey is rsa.DecryptPKCS1v15SessionKey for keys held by the
+// module: key is overwritten with the decrypted session key only if the
+// padding is valid and the lengths match, without revealing which.
+func (k *rsaKey) decryptSessionKey(ciphertext, key []byte) error {
+	size := k.pub.Size()
+	if size-(len(key)+3+8) < 0 {
+		return rsa.ErrDecryption
+	}
+	em, err := boring.DecryptRSANoPadding(k.priv, ciphertext)
+	if err != nil {
+		return err
+	}
+	if len(em) != size {
+		return rsa.ErrDecryption
+	}
+
+	firstByteIsZero := subtle.ConstantTimeByteEq(em[0], 0)
+	secondByteIsTwo := subtle.ConstantTimeByteEq(em[1], 2)
+
+	lookingForIndex, index := 1, 0
+	for i := 2; i < len(em); i++ {
+		equals0 := subtle.ConstantTimeByteEq(em[i], 0)
+		index = subtle.ConstantTimeSelect(lookingForIndex&equals0, i, index)
+		lookingForIndex = subtle.ConstantTimeSelect(equals0, 0, lookingForIndex)
+	}
+	validPS := subtle.ConstantTimeLessOrEq(2+8, index)
+
+	valid := firstByteIsZero & secondByteIsTwo & (^lookingForIndex & 1) & validPS
+	index = subtle.ConstantTimeSelect(valid, index+1, 0)
+	valid &= subtle.ConstantTimeEq(int32(len(em)-index), int32(len(key)))
+	subtle.ConstantTimeCopy(valid, key, em[len(em)-len(key):])
+	return nil
+}
+
+type ecdsaKey struct {
+	priv *boring.PrivateKeyECDSA
+	pub  *ecdsa.PublicKey
+}
+
+func (k *ecdsaKey) Public() crypto.PublicKey {
+	return k.pub
+}
+
+func (k *ecdsaKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return boring.SignMarshalECDSA(k.priv, digest, crypto.Hash(0))
+}
+
+func (k *ecdsaKey) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hash := opts.HashFunc()
+	if hash == 0 {
+		return k.Sign(rand, msg, opts)
+	}
+	s, err := boring.NewDigestSignerECDSA(k.priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	s.Write(msg)
+	return s.Sign()
+}
@@ -0,0 +1,354 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package boring_test
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/boring"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParsePrivateKeyRSA(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("BoringCrypto is not enabled")
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, der := range map[string][]byte{
+		"PKCS1": x509.MarshalPKCS1PrivateKey(priv),
+		"PKCS8": pkcs8,
+	} {
+		t.Run(name, func(t *testing.T) {
+			key, err := boring.ParsePrivateKey(der)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := key.(*rsa.PrivateKey); ok {
+				t.Fatal("ParsePrivateKey exposed the private key")
+			}
+			testRSAKey(t, key, priv)
+		})
+	}
+}
+
+// testRSAKey checks signing and decryption with key, which holds the
+// same key as priv.
+func testRSAKey(t *testing.T, key crypto.Signer, priv *rsa.PrivateKey) {
+	pub, ok := key.Public().(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(priv.N) != 0 || pub.E != priv.E {
+		t.Fatalf("wrong public key: %v", key.Public())
+	}
+
+	msg := []byte("hello, world")
+	digest := sha256.Sum256(msg)
+	sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("PKCS #1 v1.5 signature: %v", err)
+	}
+	sig, err = key.(crypto.MessageSigner).SignMessage(rand.Reader, msg, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig); err != nil {
+		t.Errorf("PKCS #1 v1.5 message signature: %v", err)
+	}
+
+	dec := key.(crypto.Decrypter)
+	ct, err := rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, msg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err := dec.Decrypt(rand.Reader, ct, &rsa.OAEPOptions{Hash: crypto.SHA256})
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Errorf("OAEP decryption: got %q, %v", pt, err)
+	}
+	ct, err = rsa.EncryptPKCS1v15(rand.Reader, pub, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt, err = dec.Decrypt(rand.Reader, ct, nil)
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Errorf("PKCS #1 v1.5 decryption: got %q, %v", pt, err)
+	}
+	pt, err = dec.Decrypt(rand.Reader, ct, &rsa.PKCS1v15DecryptOptions{SessionKeyLen: len(msg)})
+	if err != nil || !bytes.Equal(pt, msg) {
+		t.Errorf("PKCS #1 v1.5 session key decryption: got %q, %v", pt, err)
+	}
+	pt, err = dec.Decrypt(rand.Reader, ct, &rsa.PKCS1v15DecryptOptions{SessionKeyLen: len(msg) + 1})
+	if err != nil || bytes.Equal(pt[:len(msg)], msg) {
+		t.Errorf("PKCS #1 v1.5 session key decryption with wrong length: got %q, %v", pt, err)
+	}
+
+	testCreateCertificate(t, key)
+}
+
+func TestParsePrivateKeyRSAPSS(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("BoringCrypto is not enabled")
+	}
+	priv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := boring.ParsePrivateKey(x509.MarshalPKCS1PrivateKey(priv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("hello, world")
+	digest := sha256.Sum256(msg)
+	for _, saltLength := range []int{rsa.PSSSaltLengthAuto, rsa.PSSSaltLengthEqualsHash, 20} {
+		opts := &rsa.PSSOptions{SaltLength: saltLength, Hash: crypto.SHA256}
+		sig, err := key.Sign(rand.Reader, digest[:], opts)
+		if err != nil {
+			t.Fatalf("Sign with salt length %d: %v", saltLength, err)
+		}
+		if err := rsa.VerifyPSS(&priv.PublicKey, crypto.SHA256, digest[:], sig, opts); err != nil {
+			t.Errorf("Sign with salt length %d: %v", saltLength, err)
+		}
+		sig, err = key.(crypto.MessageSigner).SignMessage(rand.Reader, msg, opts)
+		if err != nil {
+			t.Fatalf("SignMessage with salt length %d: %v", saltLength, err)
+		}
+		if err := rsa.VerifyPSS(&priv.PublicKey, crypto.SHA256, digest[:], sig, opts); err != nil {
+			t.Errorf("SignMessage with salt length %d: %v", saltLength, err)
+		}
+	}
+}
+
+func TestParsePrivateKeyECDSA(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("BoringCrypto is not enabled")
+	}
+	for _, curve := range []elliptic.Curve{elliptic.P256(), elliptic.P384(), elliptic.P521()} {
+		priv, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sec1, err := x509.MarshalECPrivateKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkcs8, err := x509.MarshalPKCS8PrivateKey(priv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, der := range map[string][]byte{
+			"SEC1":  sec1,
+			"PKCS8": pkcs8,
+		} {
+			t.Run(curve.Params().Name+"/"+name, func(t *testing.T) {
+				key, err := boring.ParsePrivateKey(der)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := key.(*ecdsa.PrivateKey); ok {
+					t.Fatal("ParsePrivateKey exposed the private key")
+				}
+				pub, ok := key.Public().(*ecdsa.PublicKey)
+				if !ok || pub.Curve != curve || pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+					t.Fatalf("wrong public key: %v", key.Public())
+				}
+
+				msg := []byte("hello, world")
+				digest := sha256.Sum256(msg)
+				sig, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+					t.Error("Sign produced an invalid signature")
+				}
+				sig, err = key.(crypto.MessageSigner).SignMessage(rand.Reader, msg, crypto.SHA256)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !ecdsa.VerifyASN1(pub, digest[:], sig) {
+					t.Error("SignMessage produced an invalid signature")
+				}
+
+				testCreateCertificate(t, key)
+			})
+		}
+	}
+}
+
+func TestParsePrivateKeyInvalid(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("BoringCrypto is not enabled")
+	}
+	for _, der := range [][]byte{nil, {0x30, 0x03, 0x02, 0x01, 0x00}} {
+		if _, err := boring.ParsePrivateKey(der); err == nil {
+			t.Errorf("ParsePrivateKey(%x) succeeded", der)
+		}
+	}
+}
+
+func TestLoadPrivateKeyFile(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("BoringCrypto is not enabled")
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, priv := range map[string]crypto.Signer{"ECDSA": ecKey, "RSA": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			der, err := x509.MarshalPKCS8PrivateKey(priv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, name+".pem")
+			if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, uri := range []string{path, "file:" + path} {
+				key, err := boring.LoadPrivateKey(uri)
+				if err != nil {
+					t.Fatalf("LoadPrivateKey(%q): %v", uri, err)
+				}
+				switch priv := priv.(type) {
+				case *ecdsa.PrivateKey:
+					pub, ok := key.Public().(*ecdsa.PublicKey)
+					if !ok || pub.X.Cmp(priv.X) != 0 || pub.Y.Cmp(priv.Y) != 0 {
+						t.Fatalf("LoadPrivateKey(%q): wrong public key", uri)
+					}
+				case *rsa.PrivateKey:
+					testRSAKey(t, key, priv)
+				}
+				testTLSHandshake(t, key)
+			}
+
+			if _, err := boring.LoadEnginePrivateKey("no-such-engine", path); err == nil {
+				t.Error("LoadEnginePrivateKey succeeded with a missing engine")
+			}
+		})
+	}
+
+	if _, err := boring.LoadPrivateKey(filepath.Join(dir, "missing.pem")); err == nil {
+		t.Error("LoadPrivateKey succeeded on a missing file")
+	}
+}
+
+// TestLoadPrivateKeyPKCS11 loads a key from a PKCS #11 token, such as
+// one created with SoftHSM:
+//
+//	softhsm2-util --init-token --free --label test --pin 1234 --so-pin 1234
+//	pkcs11-tool --module libsofthsm2.so --login --pin 1234 \
+//		--keypairgen --key-type EC:prime256v1 --label test-key
+//
+// GO_BORING_PKCS11_KEY holds the key URI, for example
+// "pkcs11:token=test;object=test-key;pin-value=1234". The key is loaded
+// with LoadPrivateKey, which needs a PKCS #11 store loader to be
+// configured in OpenSSL, or with LoadEnginePrivateKey if
+// GO_BORING_PKCS11_ENGINE names an engine, such as "pkcs11".
+func TestLoadPrivateKeyPKCS11(t *testing.T) {
+	uri := os.Getenv("GO_BORING_PKCS11_KEY")
+	if uri == "" {
+		t.Skip("GO_BORING_PKCS11_KEY is not set")
+	}
+	if !boring.Enabled() {
+		t.Skip("BoringCrypto is not enabled")
+	}
+	var key crypto.Signer
+	var err error
+	if engine := os.Getenv("GO_BORING_PKCS11_ENGINE"); engine != "" {
+		key, err = boring.LoadEnginePrivateKey(engine, uri)
+	} else {
+		key, err = boring.LoadPrivateKey(uri)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCreateCertificate(t, key)
+	testTLSHandshake(t, key)
+}
+
+// testTLSHandshake checks that key can authenticate a TLS server.
+func testTLSHandshake(t *testing.T, key crypto.Signer) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+
+	c, s := net.Pipe()
+	defer c.Close()
+	defer s.Close()
+	server := tls.Server(s, &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	errc := make(chan error, 1)
+	go func() { errc <- server.Handshake() }()
+	client := tls.Client(c, &tls.Config{ServerName: "example.com", RootCAs: roots})
+	if err := client.Handshake(); err != nil {
+		t.Fatalf("client handshake: %v", err)
+	}
+	if err := <-errc; err != nil {
+		t.Fatalf("server handshake: %v", err)
+	}
+}
+
+// testCreateCertificate checks that key can self-sign a certificate.
+func testCreateCertificate(t *testing.T, key crypto.Signer) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.CheckSignature(cert.SignatureAlgorithm, cert.RawTBSCertificate, cert.Signature); err != nil {
+		t.Errorf("certificate signature: %v", err)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Counter with CBC-MAC (CCM) mode.
+
+// See NIST SP 800-38C and RFC 3610.
+
+package cipher
+
+import (
+	subtleoverlap "crypto/internal/subtle"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// ccmAble is an interface implemented by ciphers that have a specific
+// implementation of CCM, like crypto/aes in FIPS mode. NewCCM will check for
+// this interface and return the specific AEAD if found.
+type ccmAble interface {
+	NewCCM(nonceSize, tagSize int) (AEAD, error)
+}
+
+const ccmBlockSize = 16
+
+type ccm struct {
+	cipher    Block
+	nonceSize int
+	tagSize   int
+}
+
+// NewCCM returns the given 128-bit block cipher wrapped in Counter with
+// CBC-MAC mode, with nonces of nonceSize bytes and tags of tagSize bytes.
+// The nonce size must be between 7 and 13 and the tag size an even number
+// between 4 and 16. The nonce size bounds the plaintext length: with an
+// n-byte nonce, messages must be shorter than 2^(8*(15-n)) bytes.
+//
+// CCM is mostly used by network protocols that mandate it. Other users
+// should use NewGCM.
+func NewCCM(cipher Block, nonceSize, tagSize int) (AEAD, error) {
+	if nonceSize < 7 || nonceSize > 13 {
+		return nil, errors.New("cipher: incorrect nonce size given to CCM")
+	}
+	if tagSize < 4 || tagSize > 16 || tagSize%2 != 0 {
+		return nil, errors.New("cipher: incorrect tag size given to CCM")
+	}
+
+	if cipher, ok := cipher.(ccmAble); ok {
+		return cipher.NewCCM(nonceSize, tagSize)
+	}
+
+	if cipher.BlockSize() != ccmBlockSize {
+		return nil, errors.New("cipher: NewCCM requires 128-bit block cipher")
+	}
+
+	return &ccm{cipher: cipher, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+func (c *ccm) NonceSize() int {
+	return c.nonceSize
+}
+
+func (c *ccm) Overhead() int {
+	return c.tagSize
+}
+
+// maxLength returns the largest plaintext length that the length field
+// left by the nonce can encode.
+func (c *ccm) maxLength() uint64 {
+	q := 15 - c.nonceSize
+	if q >= 8 {
+		return 1<<64 - 1
+	}
+	return 1<<(8*uint(q)) - 1
+}
+
+func (c *ccm) Seal(dst, nonce, plaintext, data []byte) []byte {
+	if len(nonce) != c.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to CCM")
+	}
+	if uint64(len(plaintext)) > c.maxLength() {
+		panic("crypto/cipher: message too large for CCM")
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+c.tagSize)
+	if subtleoverlap.InexactOverlap(out, plaintext) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+
+	var tag [ccmBlockSize]byte
+	c.auth(&tag, nonce, plaintext, data)
+
+	// Encrypt the plaintext with counters 1 and on, and the tag with
+	// counter 0.
+	var counter, s0 [ccmBlockSize]byte
+	c.formatCounter(&counter, nonce)
+	c.cipher.Encrypt(s0[:], counter[:])
+	counter[ccmBlockSize-1] = 1
+	NewCTR(c.cipher, counter[:]).XORKeyStream(out, plaintext)
+	xorBytes(out[len(plaintext):], tag[:c.tagSize], s0[:c.tagSize])
+
+	return ret
+}
+
+func (c *ccm) Open(dst, nonce, ciphertext, data []byte) ([]byte, error) {
+	if len(nonce) != c.nonceSize {
+		panic("crypto/cipher: incorrect nonce length given to CCM")
+	}
+	// Sanity check to prevent the authentication from always succeeding if an implementation
+	// leaves tagSize uninitialized, for example.
+	if c.tagSize < 4 {
+		panic("crypto/cipher: incorrect CCM tag size")
+	}
+
+	if len(ciphertext) < c.tagSize {
+		return nil, errOpen
+	}
+	if uint64(len(ciphertext)-c.tagSize) > c.maxLength() {
+		return nil, errOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-c.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-c.tagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if subtleoverlap.InexactOverlap(out, ciphertext) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+
+	var counter, s0 [ccmBlockSize]byte
+	c.formatCounter(&counter, nonce)
+	c.cipher.Encrypt(s0[:], counter[:])
+	counter[ccmBlockSize-1] = 1
+	NewCTR(c.cipher, counter[:]).XORKeyStream(out, ciphertext)
+
+	var expectedTag [ccmBlockSize]byte
+	c.auth(&expectedTag, nonce, out, data)
+	xorBytes(expectedTag[:], expectedTag[:], s0[:])
+
+	if subtle.ConstantTimeCompare(expectedTag[:c.tagSize], tag) != 1 {
+		for i := range out {
+			out[i] = 0
+		}
+		return nil, errOpen
+	}
+
+	return ret, nil
+}
+
+// formatCounter sets counter to the initial counter block Ctr0 for nonce,
+// SP 800-38C, Appendix A.3.
+func (c *ccm) formatCounter(counter *[ccmBlockSize]byte, nonce []byte) {
+	*counter = [ccmBlockSize]byte{}
+	counter[0] = byte(14 - c.nonceSize)
+	copy(counter[1:], nonce)
+}
+
+// auth computes the unencrypted CBC-MAC tag of plaintext and data, SP
+// 800-38C, Section 6.1.
+func (c *ccm) auth(tag *[ccmBlockSize]byte, nonce, plaintext, data []byte) {
+	// The first block, B0, holds the flags, the nonce and the plaintext
+	// length.
+	q := 15 - c.nonceSize
+	tag[0] = byte((c.tagSize-2)/2<<3 | (q - 1))
+	if len(data) > 0 {
+		tag[0] |= 0x40
+	}
+	copy(tag[1:], nonce)
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(plaintext)))
+	copy(tag[1+c.nonceSize:], length[8-q:])
+	c.cipher.Encrypt(tag[:], tag[:])
+
+	if len(data) > 0 {
+		// The associated data is prefixed with its encoded length.
+		var prefix []byte
+		switch n := uint64(len(data)); {
+		case n < 0xff00:
+			prefix = []byte{byte(n >> 8), byte(n)}
+		case n <= 0xffffffff:
+			prefix = []byte{0xff, 0xfe, 0, 0, 0, 0}
+			binary.BigEndian.PutUint32(prefix[2:], uint32(n))
+		default:
+			prefix = []byte{0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}
+			binary.BigEndian.PutUint64(prefix[2:], n)
+		}
+		c.cbcMAC(tag, prefix, data)
+	}
+	c.cbcMAC(tag, plaintext, nil)
+}
+
+// cbcMAC continues the CBC-MAC in tag over the concatenation of a and b,
+// zero-padded to a whole number of blocks.
+func (c *ccm) cbcMAC(tag *[ccmBlockSize]byte, a, b []byte) {
+	var block [ccmBlockSize]byte
+	n := 0
+	for _, in := range [][]byte{a, b} {
+		for len(in) > 0 {
+			m := copy(block[n:], in)
+			n += m
+			in = in[m:]
+			if n == ccmBlockSize {
+				xorBytes(tag[:], tag[:], block[:])
+				c.cipher.Encrypt(tag[:], tag[:])
+				n = 0
+			}
+		}
+	}
+	if n > 0 {
+		for i := n; i < ccmBlockSize; i++ {
+			block[i] = 0
+		}
+		xorBytes(tag[:], tag[:], block[:])
+		c.cipher.Encrypt(tag[:], tag[:])
+	}
+}
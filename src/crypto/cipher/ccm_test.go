@@ -0,0 +1,165 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cipher_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+// ccmGoBlock hides any native CCM implementation of the underlying
+// cipher, so that the Go implementation is exercised in FIPS mode too.
+type ccmGoBlock struct {
+	cipher.Block
+}
+
+// ccmCounting returns the n bytes from start, start+1, ..., wrapping at
+// 0xff, in hex.
+func ccmCounting(start byte, n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = start + byte(i)
+	}
+	return hex.EncodeToString(b)
+}
+
+var aesCCMTests = []struct {
+	key, nonce, plaintext, ad, result string
+}{
+	// SP 800-38C, Appendix C.
+	{
+		"404142434445464748494a4b4c4d4e4f",
+		"10111213141516",
+		"20212223",
+		"0001020304050607",
+		"7162015b4dac255d",
+	},
+	{
+		"404142434445464748494a4b4c4d4e4f",
+		"1011121314151617",
+		"202122232425262728292a2b2c2d2e2f",
+		"000102030405060708090a0b0c0d0e0f",
+		"d2a1f0e051ea5f62081a7792073d593d1fc64fbfaccd",
+	},
+	{
+		"404142434445464748494a4b4c4d4e4f",
+		"101112131415161718191a1b",
+		ccmCounting(0x20, 24),
+		ccmCounting(0x00, 20),
+		"e3b201a9f5b71a7a9b1ceaeccd97e70b6176aad9a4428aa5484392fbc1b09951",
+	},
+	{
+		"404142434445464748494a4b4c4d4e4f",
+		"101112131415161718191a1b1c",
+		ccmCounting(0x20, 32),
+		ccmCounting(0x00, 65536),
+		"69915dad1e84c6376a68c2967e4dab615ae0fd1faec44cc484828529463ccf72b4ac6bec93e8598e7f0dadbcea5b",
+	},
+	// RFC 3610, Packet Vector #1.
+	{
+		"c0c1c2c3c4c5c6c7c8c9cacbcccdcecf",
+		"00000003020100a0a1a2a3a4a5",
+		ccmCounting(0x08, 23),
+		"0001020304050607",
+		"588c979a61c663d2f066d0c2c0f989806d5f6b61dac38417e8d12cfdf926e0",
+	},
+}
+
+func TestAESCCM(t *testing.T) {
+	for i, tt := range aesCCMTests {
+		key, _ := hex.DecodeString(tt.key)
+		nonce, _ := hex.DecodeString(tt.nonce)
+		plaintext, _ := hex.DecodeString(tt.plaintext)
+		ad, _ := hex.DecodeString(tt.ad)
+		result, _ := hex.DecodeString(tt.result)
+		tagSize := len(result) - len(plaintext)
+
+		b, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range []cipher.Block{b, ccmGoBlock{b}} {
+			aead, err := cipher.NewCCM(block, len(nonce), tagSize)
+			if err != nil {
+				t.Fatalf("#%d, %T: %v", i, block, err)
+			}
+			if aead.NonceSize() != len(nonce) || aead.Overhead() != tagSize {
+				t.Errorf("#%d, %T: got NonceSize %d and Overhead %d", i, block, aead.NonceSize(), aead.Overhead())
+			}
+
+			ct := aead.Seal([]byte("prefix"), nonce, plaintext, ad)
+			if !bytes.Equal(ct[6:], result) {
+				t.Errorf("#%d, %T: got %x, want %x", i, block, ct[6:], result)
+			}
+
+			pt, err := aead.Open(nil, nonce, result, ad)
+			if err != nil || !bytes.Equal(pt, plaintext) {
+				t.Errorf("#%d, %T: Open got %x, %v", i, block, pt, err)
+			}
+
+			for _, tamper := range []int{0, len(plaintext), len(result) - 1} {
+				bad := append([]byte(nil), result...)
+				bad[tamper] ^= 0x80
+				if _, err := aead.Open(nil, nonce, bad, ad); err == nil {
+					t.Errorf("#%d, %T: Open accepted a ciphertext tampered at byte %d", i, block, tamper)
+				}
+			}
+		}
+	}
+}
+
+// TestAESCCMImplementations checks that the native and Go implementations
+// agree over nonce and tag sizes and short or empty inputs.
+func TestAESCCMImplementations(t *testing.T) {
+	b, err := aes.NewCipher([]byte("0123456789abcdef"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for nonceSize := 7; nonceSize <= 13; nonceSize++ {
+		for tagSize := 4; tagSize <= 16; tagSize += 2 {
+			native, err := cipher.NewCCM(b, nonceSize, tagSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			generic, err := cipher.NewCCM(ccmGoBlock{b}, nonceSize, tagSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			nonce := make([]byte, nonceSize)
+			for _, n := range []int{0, 1, 16, 33} {
+				plaintext := bytes.Repeat([]byte{'p'}, n)
+				for _, ad := range [][]byte{nil, []byte("ad")} {
+					a := native.Seal(nil, nonce, plaintext, ad)
+					g := generic.Seal(nil, nonce, plaintext, ad)
+					if !bytes.Equal(a, g) {
+						t.Errorf("nonce %d, tag %d, %d bytes, ad %q: got %x and %x", nonceSize, tagSize, n, ad, a, g)
+					}
+					if out, err := native.Open(g[:0], nonce, g, ad); err != nil || !bytes.Equal(out, plaintext) {
+						t.Errorf("nonce %d, tag %d, %d bytes, ad %q: in-place Open got %x, %v", nonceSize, tagSize, n, ad, out, err)
+					}
+					a[len(a)-1] ^= 1
+					if _, err := native.Open(nil, nonce, a, ad); err == nil {
+						t.Errorf("nonce %d, tag %d, %d bytes, ad %q: Open accepted a bad tag", nonceSize, tagSize, n, ad)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestCCMInvalidSizes(t *testing.T) {
+	b, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range [][2]int{{6, 16}, {14, 16}, {12, 2}, {12, 5}, {12, 18}} {
+		if _, err := cipher.NewCCM(b, size[0], size[1]); err == nil {
+			t.Errorf("NewCCM accepted nonce size %d and tag size %d", size[0], size[1])
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// XTS mode, for encrypting data units such as disk sectors.
+
+// See NIST SP 800-38E and IEEE Std 1619-2007.
+
+package cipher
+
+import (
+	subtleoverlap "crypto/internal/subtle"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// XTS is a 128-bit block cipher in XTS mode. It encrypts fixed-size data
+// units, such as disk sectors, each identified by a sequence number.
+type XTS interface {
+	// Encrypt encrypts the data unit src, which must be at least one block
+	// long, into dst. The sector number is encoded as the little-endian
+	// tweak of IEEE Std 1619. Dst and src must overlap entirely or not at
+	// all. A final partial block is handled with ciphertext stealing.
+	Encrypt(dst, src []byte, sector uint64)
+
+	// Decrypt decrypts the data unit src into dst, with the same
+	// requirements as Encrypt.
+	Decrypt(dst, src []byte, sector uint64)
+}
+
+// xtsAble is an interface implemented by ciphers that have a specific
+// implementation of XTS, like crypto/aes in FIPS mode. NewXTS will check for
+// this interface and return the specific XTS if found.
+type xtsAble interface {
+	NewXTS(tweak Block) (XTS, error)
+}
+
+const xtsBlockSize = 16
+
+var errXTSSameKey = errors.New("cipher: XTS data and tweak keys must differ")
+
+// NewXTS returns an XTS using data to encrypt the data units and tweak to
+// encrypt the tweaks. Both must be 128-bit block ciphers of the same kind,
+// such as two AES ciphers with independent keys. As SP 800-38E requires,
+// NewXTS returns an error if data and tweak use the same key.
+func NewXTS(data, tweak Block) (XTS, error) {
+	if data.BlockSize() != xtsBlockSize || tweak.BlockSize() != xtsBlockSize {
+		return nil, errors.New("cipher: NewXTS requires 128-bit block ciphers")
+	}
+
+	if data, ok := data.(xtsAble); ok {
+		return data.NewXTS(tweak)
+	}
+
+	// Ciphers of the same kind with the same key encrypt the zero block
+	// alike.
+	var d, t [xtsBlockSize]byte
+	data.Encrypt(d[:], d[:])
+	tweak.Encrypt(t[:], t[:])
+	if subtle.ConstantTimeCompare(d[:], t[:]) == 1 {
+		return nil, errXTSSameKey
+	}
+
+	return &xts{data: data, tweak: tweak}, nil
+}
+
+type xts struct {
+	data, tweak Block
+}
+
+func (x *xts) Encrypt(dst, src []byte, sector uint64) {
+	x.crypt(dst, src, sector, true)
+}
+
+func (x *xts) Decrypt(dst, src []byte, sector uint64) {
+	x.crypt(dst, src, sector, false)
+}
+
+func (x *xts) crypt(dst, src []byte, sector uint64, encrypt bool) {
+	if len(src) < xtsBlockSize {
+		panic("crypto/cipher: input smaller than a block")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	if subtleoverlap.InexactOverlap(dst[:len(src)], src) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+
+	var t [xtsBlockSize]byte
+	binary.LittleEndian.PutUint64(t[:8], sector)
+	x.tweak.Encrypt(t[:], t[:])
+
+	// With a final partial block, the last full block is processed
+	// together with it, using ciphertext stealing.
+	tail := len(src) % xtsBlockSize
+	full := len(src) - tail
+	if tail != 0 {
+		full -= xtsBlockSize
+	}
+
+	for i := 0; i < full; i += xtsBlockSize {
+		x.cryptBlock(dst[i:i+xtsBlockSize], src[i:i+xtsBlockSize], &t, encrypt)
+		mulAlpha(&t)
+	}
+	if tail == 0 {
+		return
+	}
+
+	// Ciphertext stealing, SP 800-38E, Section 5.3. The block before the
+	// partial one is processed with the last tweak on decryption, and the
+	// next to last one on encryption.
+	var cc, pp [xtsBlockSize]byte
+	last := src[full : full+xtsBlockSize]
+	t1 := t
+	mulAlpha(&t1)
+	if encrypt {
+		x.cryptBlock(cc[:], last, &t, true)
+	} else {
+		x.cryptBlock(cc[:], last, &t1, false)
+	}
+	copy(pp[:], src[full+xtsBlockSize:])
+	copy(pp[tail:], cc[tail:])
+	copy(dst[full+xtsBlockSize:len(src)], cc[:tail])
+	if encrypt {
+		x.cryptBlock(dst[full:full+xtsBlockSize], pp[:], &t1, true)
+	} else {
+		x.cryptBlock(dst[full:full+xtsBlockSize], pp[:], &t, false)
+	}
+}
+
+// cryptBlock sets dst to the encryption or decryption of src under the
+// tweak value t.
+func (x *xts) cryptBlock(dst, src []byte, t *[xtsBlockSize]byte, encrypt bool) {
+	xorBytes(dst, src, t[:])
+	if encrypt {
+		x.data.Encrypt(dst, dst)
+	} else {
+		x.data.Decrypt(dst, dst)
+	}
+	xorBytes(dst, dst, t[:])
+}
+
+// mulAlpha multiplies t by the primitive element α of GF(2¹²⁸), with the
+// little-endian bit order of XTS.
+func mulAlpha(t *[xtsBlockSize]byte) {
+	lo := binary.LittleEndian.Uint64(t[:8])
+	hi := binary.LittleEndian.Uint64(t[8:])
+	carry := hi >> 63
+	hi = hi<<1 | lo>>63
+	lo = lo<<1 ^ 0x87&-carry
+	binary.LittleEndian.PutUint64(t[:8], lo)
+	binary.LittleEndian.PutUint64(t[8:], hi)
+}
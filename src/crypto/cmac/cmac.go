@@ -0,0 +1,128 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package cmac implements the CMAC message authentication code of
+// NIST SP 800-38B, also specified for AES in RFC 4493.
+//
+// In FIPS mode, blocks returned by crypto/aes.NewCipher compute the MAC
+// with the OpenSSL module. Other blocks use a Go implementation.
+package cmac
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"hash"
+)
+
+// cmacAble is implemented by ciphers with a native CMAC implementation,
+// such as the BoringCrypto AES cipher.
+type cmacAble interface {
+	NewCMAC() (hash.Hash, error)
+}
+
+// New returns a hash.Hash computing the CMAC of its input with the block
+// cipher b, which must have a 64- or 128-bit block size.
+func New(b cipher.Block) (hash.Hash, error) {
+	if c, ok := b.(cmacAble); ok {
+		return c.NewCMAC()
+	}
+
+	var rb byte
+	switch b.BlockSize() {
+	case 8:
+		rb = 0x1b
+	case 16:
+		rb = 0x87
+	default:
+		return nil, errors.New("cmac: block size must be 8 or 16 bytes")
+	}
+
+	bs := b.BlockSize()
+	d := &digest{
+		b:   b,
+		k1:  make([]byte, bs),
+		k2:  make([]byte, bs),
+		x:   make([]byte, bs),
+		buf: make([]byte, bs),
+	}
+	// Derive the subkeys, SP 800-38B, Section 6.1.
+	l := make([]byte, bs)
+	b.Encrypt(l, l)
+	shift(d.k1, l, rb)
+	shift(d.k2, d.k1, rb)
+	return d, nil
+}
+
+// shift sets dst to src shifted left by one bit, reduced with rb.
+func shift(dst, src []byte, rb byte) {
+	var carry byte
+	for i := len(src) - 1; i >= 0; i-- {
+		b := src[i]
+		dst[i] = b<<1 | carry
+		carry = b >> 7
+	}
+	dst[len(dst)-1] ^= byte(subtle.ConstantTimeByteEq(carry, 1)) * rb
+}
+
+// xorBytes sets dst[i] = a[i] ^ b[i] for the length of dst.
+func xorBytes(dst, a, b []byte) {
+	for i := range dst {
+		dst[i] = a[i] ^ b[i]
+	}
+}
+
+type digest struct {
+	b      cipher.Block
+	k1, k2 []byte
+
+	// x is the chaining value. buf holds the last, possibly complete,
+	// block of input, which Sum treats specially.
+	x   []byte
+	buf []byte
+	n   int
+}
+
+func (d *digest) Size() int { return d.b.BlockSize() }
+
+func (d *digest) BlockSize() int { return d.b.BlockSize() }
+
+func (d *digest) Reset() {
+	for i := range d.x {
+		d.x[i] = 0
+	}
+	d.n = 0
+}
+
+func (d *digest) Write(p []byte) (int, error) {
+	nn := len(p)
+	bs := len(d.buf)
+	for len(p) > 0 {
+		if d.n == bs {
+			// The buffered block is not the last one; absorb it.
+			xorBytes(d.x, d.x, d.buf)
+			d.b.Encrypt(d.x, d.x)
+			d.n = 0
+		}
+		c := copy(d.buf[d.n:], p)
+		d.n += c
+		p = p[c:]
+	}
+	return nn, nil
+}
+
+func (d *digest) Sum(in []byte) []byte {
+	bs := len(d.buf)
+	last := make([]byte, bs)
+	copy(last, d.buf[:d.n])
+	if d.n == bs {
+		xorBytes(last, last, d.k1)
+	} else {
+		last[d.n] = 0x80
+		xorBytes(last, last, d.k2)
+	}
+	xorBytes(last, last, d.x)
+	d.b.Encrypt(last, last)
+	return append(in, last...)
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cmac
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+// goBlock hides any native CMAC implementation of the underlying cipher,
+// so that the Go implementation is exercised in FIPS mode too.
+type goBlock struct {
+	cipher.Block
+}
+
+const sp80038BMessage = "6bc1bee22e409f96e93d7e117393172a" +
+	"ae2d8a571e03ac9c9eb76fac45af8e51" +
+	"30c81c46a35ce411e5fbc1191a0a52ef" +
+	"f69f2445df4f9b17ad2b417be66c3710"
+
+// From SP 800-38B, Appendix D. Each example MACs a prefix of
+// sp80038BMessage of the given length.
+var cmacTests = []struct {
+	key string
+	n   int
+	mac string
+}{
+	{"2b7e151628aed2a6abf7158809cf4f3c", 0, "bb1d6929e95937287fa37d129b756746"},
+	{"2b7e151628aed2a6abf7158809cf4f3c", 16, "070a16b46b4d4144f79bdd9dd04a287c"},
+	{"2b7e151628aed2a6abf7158809cf4f3c", 40, "dfa66747de9ae63030ca32611497c827"},
+	{"2b7e151628aed2a6abf7158809cf4f3c", 64, "51f0bebf7e3b9d92fc49741779363cfe"},
+	{"8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b", 0, "d17ddf46adaacde531cac483de7a9367"},
+	{"8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b", 16, "9e99a7bf31e710900662f65e617c5184"},
+	{"8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b", 40, "8a1de5be2eb31aad089a82e6ee908b0e"},
+	{"8e73b0f7da0e6452c810f32b809079e562f8ead2522c6b7b", 64, "a1d5df0eed790f794d77589659f39a11"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 0, "028962f61b7bf89efc6b551f4667d983"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 16, "28a7023f452e8f82bd4bf28d8c37c35c"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 40, "aaf3d8f1de5640c232f5b169b9c911e6"},
+	{"603deb1015ca71be2b73aef0857d77811f352c073b6108d72d9810a30914dff4", 64, "e1992190549f6ed5696a2c056c315410"},
+}
+
+func TestCMAC(t *testing.T) {
+	msg, _ := hex.DecodeString(sp80038BMessage)
+	for i, tt := range cmacTests {
+		key, _ := hex.DecodeString(tt.key)
+		want, _ := hex.DecodeString(tt.mac)
+		b, err := aes.NewCipher(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, block := range []cipher.Block{b, goBlock{b}} {
+			h, err := New(block)
+			if err != nil {
+				t.Fatalf("#%d, %T: %v", i, block, err)
+			}
+			if h.Size() != 16 || h.BlockSize() != 16 {
+				t.Errorf("#%d, %T: got Size %d and BlockSize %d, want 16", i, block, h.Size(), h.BlockSize())
+			}
+
+			// Write the message in two parts, and check that Sum does
+			// not change the state and that Reset restarts it.
+			h.Write(msg[:tt.n/2])
+			h.Sum(nil)
+			h.Write(msg[tt.n/2 : tt.n])
+			if got := h.Sum(nil); !bytes.Equal(got, want) {
+				t.Errorf("#%d, %T: got %x, want %x", i, block, got, want)
+			}
+			h.Reset()
+			for j := 0; j < tt.n; j++ {
+				h.Write(msg[j : j+1])
+			}
+			if got := h.Sum([]byte("prefix")); !bytes.Equal(got, append([]byte("prefix"), want...)) {
+				t.Errorf("#%d, %T: after Reset got %x, want %x", i, block, got, want)
+			}
+		}
+	}
+}
+
+type badBlock struct {
+	cipher.Block
+}
+
+func (badBlock) BlockSize() int { return 4 }
+
+func TestCMACBlockSize(t *testing.T) {
+	b, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := New(badBlock{b}); err == nil {
+		t.Error("New accepted a 32-bit block cipher")
+	}
+}
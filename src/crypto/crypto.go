@@ -75,10 +75,10 @@ const (
 	SHA512                      // import crypto/sha512
 	MD5SHA1                     // no implementation; MD5+SHA1 used for TLS RSA
 	RIPEMD160                   // import golang.org/x/crypto/ripemd160
-	SHA3_224                    // import golang.org/x/crypto/sha3
-	SHA3_256                    // import golang.org/x/crypto/sha3
-	SHA3_384                    // import golang.org/x/crypto/sha3
-	SHA3_512                    // import golang.org/x/crypto/sha3
+	SHA3_224                    // import crypto/sha3
+	SHA3_256                    // import crypto/sha3
+	SHA3_384                    // import crypto/sha3
+	SHA3_512                    // import crypto/sha3
 	SHA512_224                  // import crypto/sha512
 	SHA512_256                  // import crypto/sha512
 	BLAKE2s_256                 // import golang.org/x/crypto/blake2s
@@ -178,6 +178,21 @@ type Signer interface {
 	Sign(rand io.Reader, digest []byte, opts SignerOpts) (signature []byte, err error)
 }
 
+// MessageSigner is an interface for a Signer that can also hash and sign a
+// whole message in one operation. Callers that hold the unhashed message,
+// such as crypto/tls and crypto/x509, prefer SignMessage over Sign when it is
+// available, so that in FIPS mode the digest is computed inside the
+// validated module rather than passed to it precomputed.
+type MessageSigner interface {
+	Signer
+
+	// SignMessage signs msg with the private key, possibly using entropy
+	// from rand. Unlike Sign, msg is not hashed by the caller: SignMessage
+	// hashes it with opts.HashFunc() itself. If opts.HashFunc() is zero,
+	// msg is signed directly, as Sign would.
+	SignMessage(rand io.Reader, msg []byte, opts SignerOpts) (signature []byte, err error)
+}
+
 // SignerOpts contains options for signing with a Signer.
 type SignerOpts interface {
 	// HashFunc returns an identifier for the hash function used to produce
@@ -133,6 +133,24 @@ func (priv *PrivateKey) Sign(rand io.Reader, digest []byte, opts crypto.SignerOp
 	return b.Bytes()
 }
 
+// SignMessage signs msg with priv, hashing it first with opts.HashFunc(),
+// and returns the ASN.1 encoded signature. In FIPS mode the digest is
+// computed by the OpenSSL module. If opts.HashFunc() is zero, msg is taken
+// to be already hashed, as by Sign.
+//
+// This method implements crypto.MessageSigner.
+func (priv *PrivateKey) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	if opts.HashFunc() == 0 {
+		return priv.Sign(rand, msg, opts)
+	}
+	s, err := NewHashSigner(rand, priv, opts.HashFunc())
+	if err != nil {
+		return nil, err
+	}
+	s.Write(msg)
+	return s.Sign()
+}
+
 var one = new(big.Int).SetInt64(1)
 
 // randFieldElement returns a random element of the field underlying the given
@@ -312,6 +330,60 @@ func SignASN1(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
 	return priv.Sign(rand, hash, nil)
 }
 
+// SignRaw signs a hash (which should be the result of hashing a larger message)
+// using the private key, priv. If the hash is longer than the bit-length of the
+// private key's curve order, the hash will be truncated to that length. It
+// returns the signature in the IEEE P1363 format used by JOSE, COSE and
+// WebAuthn: r || s, each zero-padded to the byte length of the curve order.
+// The security of the private key depends on the entropy of rand.
+func SignRaw(rand io.Reader, priv *PrivateKey, hash []byte) ([]byte, error) {
+	if boring.Enabled() {
+		boring.PanicIfStrictFIPS("ecdsa.SignRaw disabled in FIPS mode, use HashSign with raw message instead")
+		b, err := boringPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		return boring.SignRawECDSA(b, hash)
+	}
+
+	r, s, err := Sign(rand, priv, hash)
+	if err != nil {
+		return nil, err
+	}
+	size := rawSize(priv.Curve)
+	sig := make([]byte, 2*size)
+	r.FillBytes(sig[:size])
+	s.FillBytes(sig[size:])
+	return sig, nil
+}
+
+// VerifyRaw verifies the IEEE P1363 signature, sig, of hash using the
+// public key, pub. Its return value records whether the signature is valid.
+func VerifyRaw(pub *PublicKey, hash, sig []byte) bool {
+	size := rawSize(pub.Curve)
+	if len(sig) != 2*size {
+		return false
+	}
+
+	if boring.Enabled() {
+		boring.PanicIfStrictFIPS("ecdsa.VerifyRaw disabled in FIPS mode, use HashVerify with raw message instead")
+		b, err := boringPublicKey(pub)
+		if err != nil {
+			return false
+		}
+		return boring.VerifyRawECDSA(b, hash, sig)
+	}
+
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+	return Verify(pub, hash, r, s)
+}
+
+// rawSize returns the length of each half of an IEEE P1363 signature on c.
+func rawSize(c elliptic.Curve) int {
+	return (c.Params().N.BitLen() + 7) / 8
+}
+
 func HashSign(rand io.Reader, priv *PrivateKey, msg []byte, h crypto.Hash) (r, s *big.Int, err error) {
 	randutil.MaybeReadByte(rand)
 
@@ -13,6 +13,7 @@ import (
 	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
+	_ "crypto/sha3"
 	"crypto/sha512"
 	"encoding/hex"
 	"hash"
@@ -199,6 +200,67 @@ func TestSignAndVerifyASN1(t *testing.T) {
 	testSignAndVerifyASN1(t, elliptic.P521(), "p521")
 }
 
+func testSignAndVerifyRaw(t *testing.T, c elliptic.Curve, tag string) {
+	priv, _ := GenerateKey(c, rand.Reader)
+
+	hashed := []byte("testing")
+	sig, err := SignRaw(rand.Reader, priv, hashed)
+	if err != nil {
+		t.Errorf("%s: error signing: %s", tag, err)
+		return
+	}
+	size := (c.Params().N.BitLen() + 7) / 8
+	if len(sig) != 2*size {
+		t.Errorf("%s: signature is %d bytes, want %d", tag, len(sig), 2*size)
+		return
+	}
+
+	if !VerifyRaw(&priv.PublicKey, hashed, sig) {
+		t.Errorf("%s: VerifyRaw failed", tag)
+	}
+	r := new(big.Int).SetBytes(sig[:size])
+	s := new(big.Int).SetBytes(sig[size:])
+	if !Verify(&priv.PublicKey, hashed, r, s) {
+		t.Errorf("%s: Verify failed on the halves of a raw signature", tag)
+	}
+
+	// Signatures from Sign verify once their halves are padded.
+	r, s, err = Sign(rand.Reader, priv, hashed)
+	if err != nil {
+		t.Errorf("%s: error signing: %s", tag, err)
+		return
+	}
+	padded := make([]byte, 2*size)
+	r.FillBytes(padded[:size])
+	s.FillBytes(padded[size:])
+	if !VerifyRaw(&priv.PublicKey, hashed, padded) {
+		t.Errorf("%s: VerifyRaw failed on a signature from Sign", tag)
+	}
+	if unpadded := append(r.Bytes(), s.Bytes()...); len(unpadded) != 2*size && VerifyRaw(&priv.PublicKey, hashed, unpadded) {
+		t.Errorf("%s: VerifyRaw accepted an unpadded signature", tag)
+	}
+
+	if VerifyRaw(&priv.PublicKey, hashed, sig[:len(sig)-1]) {
+		t.Errorf("%s: VerifyRaw accepted a truncated signature", tag)
+	}
+	hashed[0] ^= 0xff
+	if VerifyRaw(&priv.PublicKey, hashed, sig) {
+		t.Errorf("%s: VerifyRaw always works!", tag)
+	}
+}
+
+func TestSignAndVerifyRaw(t *testing.T) {
+	if !boring.Enabled() {
+		testSignAndVerifyRaw(t, elliptic.P224(), "p224")
+	}
+	testSignAndVerifyRaw(t, elliptic.P256(), "p256")
+	if testing.Short() {
+		return
+	}
+	testSignAndVerifyRaw(t, elliptic.P384(), "p384")
+	testSignAndVerifyRaw(t, elliptic.P521(), "p521")
+}
+
 func TestHashSignAndHashVerify(t *testing.T) {
 	testHashSignAndHashVerify(t, elliptic.P256(), "p256")
 
@@ -209,6 +271,123 @@ func TestHashSignAndHashVerify(t *testing.T) {
 	testHashSignAndHashVerify(t, elliptic.P521(), "p521")
 }
 
+func TestHashSignAndHashVerifySHA3(t *testing.T) {
+	priv, err := GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("testing")
+	for _, h := range []crypto.Hash{crypto.SHA3_256, crypto.SHA3_384, crypto.SHA3_512} {
+		r, s, err := HashSign(rand.Reader, priv, msg, h)
+		if err != nil {
+			t.Errorf("%v: error signing: %s", h, err)
+			continue
+		}
+		if !HashVerify(&priv.PublicKey, msg, r, s, h) {
+			t.Errorf("%v: Verify failed", h)
+		}
+		if HashVerify(&priv.PublicKey, []byte("Testing"), r, s, h) {
+			t.Errorf("%v: Verify always works!", h)
+		}
+	}
+}
+
+func TestHashSigner(t *testing.T) {
+	priv, err := GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(strings.Repeat("testing", 1000))
+
+	s, err := NewHashSigner(rand.Reader, priv, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(msg); i += 100 {
+		s.Write(msg[i : i+100])
+	}
+	sig, err := s.Sign()
+	if err != nil {
+		t.Fatalf("Sign: %s", err)
+	}
+
+	digest := sha256.Sum256(msg)
+	if !boring.Enabled() && !VerifyASN1(&priv.PublicKey, digest[:], sig) {
+		t.Error("VerifyASN1 failed on HashSigner signature")
+	}
+
+	v, err := NewHashVerifier(&priv.PublicKey, crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Write(msg)
+	if !v.Verify(sig) {
+		t.Error("Verify failed")
+	}
+	v.Write([]byte("x"))
+	if v.Verify(sig) {
+		t.Error("Verify of modified message succeeded")
+	}
+}
+
+func TestBoringPrivateKeyCheck(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("key consistency checks only run with the boring backend")
+	}
+	priv, err := GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := boringPrivateKey(priv); err != nil {
+		t.Fatalf("boringPrivateKey of generated key: %v", err)
+	}
+
+	bad := *priv
+	bad.boring = nil
+	bad.D = new(big.Int).Add(priv.D, big.NewInt(1))
+	if _, err := boringPrivateKey(&bad); err == nil {
+		t.Error("boringPrivateKey accepted a key whose public point does not match D")
+	}
+	if _, _, err := HashSign(rand.Reader, &bad, []byte("testing"), crypto.SHA256); err == nil {
+		t.Error("HashSign succeeded with an inconsistent key")
+	}
+}
+
+func TestBoringTestDRBG(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("test DRBG is only available with the boring backend")
+	}
+	priv, err := GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte("testing")
+	sign := func(seed string) (r, s *big.Int) {
+		restore := boring.SetTestDRBG(boring.NewTestDRBG([]byte(seed), nil, nil))
+		defer restore()
+		// rand.Reader would also draw from the DRBG, via MaybeReadByte.
+		r, s, err := HashSign(zeroReader, priv, msg, crypto.SHA256)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r, s
+	}
+
+	// The first operation on a key may set up state that also consumes
+	// random bytes, so do it before any DRBG is installed.
+	if _, _, err := HashSign(rand.Reader, priv, msg, crypto.SHA256); err != nil {
+		t.Fatal(err)
+	}
+
+	r1, s1 := sign("ECDSA nonce seed, first")
+	if r2, s2 := sign("ECDSA nonce seed, first"); r1.Cmp(r2) != 0 || s1.Cmp(s2) != 0 {
+		t.Error("signatures with identically seeded DRBGs differ")
+	}
+	if r3, _ := sign("ECDSA nonce seed, second"); r1.Cmp(r3) == 0 {
+		t.Error("the nonce used with differently seeded DRBGs was the same")
+	}
+}
+
 func testNonceSafety(t *testing.T, c elliptic.Curve, tag string) {
 	priv, _ := GenerateKey(c, rand.Reader)
 
@@ -417,9 +596,6 @@ func TestVectors(t *testing.T) {
 
 			switch parts[0] {
 			case "P-224":
-				if boring.Enabled() { // P-224 not supported in RHEL OpenSSL.
-					continue
-				}
 				pub.Curve = elliptic.P224()
 			case "P-256":
 				pub.Curve = elliptic.P256()
@@ -560,3 +736,37 @@ func TestZeroHashSignature(t *testing.T) {
 		}
 	}
 }
+
+func TestP224SigningPolicy(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("P-224 signing policy only applies in FIPS mode")
+	}
+	defer os.Setenv("GOLANG_FIPS_P224_SIGN", os.Getenv("GOLANG_FIPS_P224_SIGN"))
+
+	os.Setenv("GOLANG_FIPS_P224_SIGN", "0")
+	if _, err := GenerateKey(elliptic.P224(), rand.Reader); err == nil {
+		t.Fatal("P-224 key generation succeeded without GOLANG_FIPS_P224_SIGN=1")
+	}
+
+	os.Setenv("GOLANG_FIPS_P224_SIGN", "1")
+	priv, err := GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatalf("P-224 key generation failed: %v", err)
+	}
+	msg := []byte("testing")
+	r, s, err := HashSign(rand.Reader, priv, msg, crypto.SHA256)
+	if err != nil {
+		t.Fatalf("P-224 signing failed: %v", err)
+	}
+
+	// Verification stays allowed, but a fresh copy of the private key,
+	// which is not yet cached in the backend, can no longer sign.
+	os.Setenv("GOLANG_FIPS_P224_SIGN", "0")
+	if !HashVerify(&priv.PublicKey, msg, r, s, crypto.SHA256) {
+		t.Error("P-224 verification failed")
+	}
+	priv2 := &PrivateKey{PublicKey: PublicKey{Curve: priv.Curve, X: priv.X, Y: priv.Y}, D: priv.D}
+	if _, _, err := HashSign(rand.Reader, priv2, msg, crypto.SHA256); err == nil {
+		t.Error("P-224 signing succeeded without GOLANG_FIPS_P224_SIGN=1")
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ecdsa
+
+import (
+	"crypto"
+	"errors"
+	"hash"
+	"io"
+
+	"crypto/internal/boring"
+)
+
+// This file implements streaming hash-then-sign, for messages that are too
+// large to pass to HashSign and HashVerify as a single slice.
+
+// A HashSigner signs a message that is written to it incrementally.
+// In FIPS mode, both the digest and the signature are computed by the
+// OpenSSL module.
+type HashSigner struct {
+	boring *boring.DigestSigner
+
+	rand io.Reader
+	priv *PrivateKey
+	h    hash.Hash
+}
+
+// NewHashSigner returns a HashSigner that signs the h digest of the message
+// written to it with priv. The security of the private key depends on the
+// entropy of rand, which is not used in FIPS mode.
+func NewHashSigner(rand io.Reader, priv *PrivateKey, h crypto.Hash) (*HashSigner, error) {
+	if boring.Enabled() {
+		b, err := boringPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		s, err := boring.NewDigestSignerECDSA(b, h)
+		if err != nil {
+			return nil, err
+		}
+		return &HashSigner{boring: s}, nil
+	}
+	boring.UnreachableExceptTests()
+
+	if !h.Available() {
+		return nil, errors.New("crypto/ecdsa: unsupported hash function")
+	}
+	return &HashSigner{rand: rand, priv: priv, h: h.New()}, nil
+}
+
+// Write adds more data to the message being signed. It never returns an error.
+func (s *HashSigner) Write(p []byte) (int, error) {
+	if s.boring != nil {
+		return s.boring.Write(p)
+	}
+	return s.h.Write(p)
+}
+
+// Sign returns the ASN.1 encoded signature of the message written so far.
+// It does not change the underlying state, so more data may be written and
+// Sign called again.
+func (s *HashSigner) Sign() ([]byte, error) {
+	if s.boring != nil {
+		return s.boring.Sign()
+	}
+	return SignASN1(s.rand, s.priv, s.h.Sum(nil))
+}
+
+// A HashVerifier verifies the signature of a message that is written to it
+// incrementally. In FIPS mode, both the digest and the verification are
+// computed by the OpenSSL module.
+type HashVerifier struct {
+	boring *boring.DigestVerifier
+
+	pub *PublicKey
+	h   hash.Hash
+}
+
+// NewHashVerifier returns a HashVerifier that checks ECDSA signatures of the
+// h digest of the message written to it with pub.
+func NewHashVerifier(pub *PublicKey, h crypto.Hash) (*HashVerifier, error) {
+	if boring.Enabled() {
+		b, err := boringPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		v, err := boring.NewDigestVerifierECDSA(b, h)
+		if err != nil {
+			return nil, err
+		}
+		return &HashVerifier{boring: v}, nil
+	}
+	boring.UnreachableExceptTests()
+
+	if !h.Available() {
+		return nil, errors.New("crypto/ecdsa: unsupported hash function")
+	}
+	return &HashVerifier{pub: pub, h: h.New()}, nil
+}
+
+// Write adds more data to the message being verified. It never returns an error.
+func (v *HashVerifier) Write(p []byte) (int, error) {
+	if v.boring != nil {
+		return v.boring.Write(p)
+	}
+	return v.h.Write(p)
+}
+
+// Verify reports whether sig is a valid ASN.1 encoded signature of the
+// message written so far. Like Sign, it does not change the underlying state.
+func (v *HashVerifier) Verify(sig []byte) bool {
+	if v.boring != nil {
+		return v.boring.Verify(sig) == nil
+	}
+	return VerifyASN1(v.pub, v.h.Sum(nil), sig)
+}
@@ -9,6 +9,7 @@ import (
 	"crypto/internal/boring"
 	"crypto/sha1"
 	"crypto/sha256"
+	"crypto/sha3"
 	"crypto/sha512"
 	"fmt"
 	"hash"
@@ -534,6 +535,21 @@ var hmacTests = []hmacTest{
 		sha512.Size,
 		sha512.BlockSize,
 	},
+	// HMAC-SHA3-256 example from NIST's "Cryptographic Standards and
+	// Guidelines" examples page.
+	{
+		sha3.New256,
+		[]byte{
+			0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07,
+			0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f,
+			0x10, 0x11, 0x12, 0x13, 0x14, 0x15, 0x16, 0x17,
+			0x18, 0x19, 0x1a, 0x1b, 0x1c, 0x1d, 0x1e, 0x1f,
+		},
+		[]byte("Sample message for keylen<blocklen"),
+		"4fe8e202c4f058e8dddc23d8c34e467343e23555e24fc2f025d598f558f67205",
+		32,
+		136,
+	},
 }
 
 func TestHMAC(t *testing.T) {
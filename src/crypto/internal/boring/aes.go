@@ -15,6 +15,7 @@ import "C"
 import (
 	"crypto/cipher"
 	"errors"
+	"hash"
 	"runtime"
 	"strconv"
 	"unsafe"
@@ -44,6 +45,21 @@ type extraModes interface {
 
 	// Invented for BoringCrypto.
 	NewGCMTLS() (cipher.AEAD, error)
+
+	// Key wrap, used by crypto/keywrap.
+	Wrap(plaintext []byte) ([]byte, error)
+	Unwrap(ciphertext []byte) ([]byte, error)
+	WrapPad(plaintext []byte) ([]byte, error)
+	UnwrapPad(ciphertext []byte) ([]byte, error)
+
+	// CMAC, used by crypto/cmac.
+	NewCMAC() (hash.Hash, error)
+
+	// XTS, used by crypto/cipher.NewXTS.
+	NewXTS(tweak cipher.Block) (cipher.XTS, error)
+
+	// CCM, used by crypto/cipher.NewCCM.
+	NewCCM(nonceSize, tagSize int) (cipher.AEAD, error)
 }
 
 var _ extraModes = (*aesCipher)(nil)
@@ -101,6 +117,7 @@ func (c *aesCipher) Encrypt(dst, src []byte) {
 		if C.int(1) != C._goboringcrypto_EVP_CipherInit_ex(c.enc_ctx, c.cipher, nil, k, nil, C.GO_AES_ENCRYPT) {
 			panic("cipher: unable to initialize EVP cipher ctx")
 		}
+		C._goboringcrypto_EVP_CIPHER_CTX_set_padding(c.enc_ctx, 0)
 	}
 
 	outlen := C.int(0)
@@ -129,6 +146,9 @@ func (c *aesCipher) Decrypt(dst, src []byte) {
 		if C.int(1) != C._goboringcrypto_EVP_CipherInit_ex(c.dec_ctx, c.cipher, nil, k, nil, C.GO_AES_DECRYPT) {
 			panic("cipher: unable to initialize EVP cipher ctx")
 		}
+		// With padding enabled, decryption would hold the block back
+		// until EVP_CipherFinal_ex.
+		C._goboringcrypto_EVP_CIPHER_CTX_set_padding(c.dec_ctx, 0)
 	}
 
 	outlen := C.int(0)
@@ -201,6 +221,9 @@ func (c *aesCipher) NewCBCEncrypter(iv []byte) cipher.BlockMode {
 	if C.int(1) != C._goboringcrypto_EVP_CipherInit_ex(x.ctx, cipher, nil, k, vec, x.mode) {
 		panic("cipher: unable to initialize EVP cipher ctx")
 	}
+	// CryptBlocks works on whole blocks; with padding enabled, decryption
+	// would hold back the last block until EVP_CipherFinal_ex.
+	C._goboringcrypto_EVP_CIPHER_CTX_set_padding(x.ctx, 0)
 
 	runtime.SetFinalizer(x, (*aesCBC).finalize)
 
@@ -235,6 +258,9 @@ func (c *aesCipher) NewCBCDecrypter(iv []byte) cipher.BlockMode {
 	if C.int(1) != C._goboringcrypto_EVP_CipherInit_ex(x.ctx, cipher, nil, k, vec, x.mode) {
 		panic("cipher: unable to initialize EVP cipher ctx")
 	}
+	// CryptBlocks works on whole blocks; with padding enabled, decryption
+	// would hold back the last block until EVP_CipherFinal_ex.
+	C._goboringcrypto_EVP_CIPHER_CTX_set_padding(x.ctx, 0)
 
 	runtime.SetFinalizer(x, (*aesCBC).finalize)
 	return x
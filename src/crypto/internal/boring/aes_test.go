@@ -78,3 +78,56 @@ func TestBlobEncryptBasicBlockEncryption(t *testing.T) {
 		t.Error("unexpected CryptBlocks result for second block")
 	}
 }
+
+func TestBlobDecryptBasicBlockDecryption(t *testing.T) {
+	key := []byte{0x24, 0xcd, 0x8b, 0x13, 0x37, 0xc5, 0xc1, 0xb1, 0x0, 0xbb, 0x27, 0x40, 0x4f, 0xab, 0x5f, 0x7b, 0x2d, 0x0, 0x20, 0xf5, 0x1, 0x84, 0x4, 0xbf, 0xe3, 0xbd, 0xa1, 0xc4, 0xbf, 0x61, 0x2f, 0xc5}
+	iv := []byte{0x91, 0xc7, 0xa7, 0x54, 0x52, 0xef, 0x10, 0xdb, 0x91, 0xa8, 0x6c, 0xf9, 0x79, 0xd5, 0xac, 0x74}
+	encrypted := []byte{
+		0x14, 0xb7, 0x3e, 0x2f, 0xd9, 0xe7, 0x69, 0x7e, 0xb7, 0xd2, 0xc3, 0x5b, 0x31, 0x9c, 0xf0, 0x59,
+		0xbb, 0xd4, 0x95, 0x25, 0x21, 0x56, 0x87, 0x3b, 0xe6, 0x22, 0xe8, 0xd0, 0x19, 0xa8, 0xed, 0xcd,
+	}
+
+	block, err := NewAESCipher(key)
+	if err != nil {
+		t.Fatalf("expected no error for aes.NewCipher, got: %s", err)
+	}
+	decrypter := block.(*aesCipher).NewCBCDecrypter(iv)
+
+	// Each block must be returned as soon as it is decrypted, not held
+	// back as the possible padding block.
+	decrypted := make([]byte, 32)
+	decrypter.CryptBlocks(decrypted, encrypted[:16])
+	if want := bytes.Repeat([]byte{0x01}, 16); !bytes.Equal(decrypted[:16], want) {
+		t.Errorf("unexpected CryptBlocks result for first block: %x", decrypted[:16])
+	}
+	decrypter.CryptBlocks(decrypted[16:], encrypted[16:])
+	if want := bytes.Repeat([]byte{0x02}, 16); !bytes.Equal(decrypted[16:], want) {
+		t.Errorf("unexpected CryptBlocks result for second block: %x", decrypted[16:])
+	}
+}
+
+func TestBlobSingleBlockEncryptDecrypt(t *testing.T) {
+	// FIPS 197, Appendix C.1.
+	key := []byte{0x00, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c, 0x0d, 0x0e, 0x0f}
+	plain := []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55, 0x66, 0x77, 0x88, 0x99, 0xaa, 0xbb, 0xcc, 0xdd, 0xee, 0xff}
+	ciphertext := []byte{0x69, 0xc4, 0xe0, 0xd8, 0x6a, 0x7b, 0x04, 0x30, 0xd8, 0xcd, 0xb7, 0x80, 0x70, 0xb4, 0xc5, 0x5a}
+
+	block, err := NewAESCipher(key)
+	if err != nil {
+		t.Fatalf("expected no error for aes.NewCipher, got: %s", err)
+	}
+
+	// Every call must return its block, not hold it back as the
+	// possible padding block.
+	out := make([]byte, 16)
+	for i := 0; i < 2; i++ {
+		block.Encrypt(out, plain)
+		if !bytes.Equal(out, ciphertext) {
+			t.Errorf("Encrypt #%d = %x, want %x", i, out, ciphertext)
+		}
+		block.Decrypt(out, ciphertext)
+		if !bytes.Equal(out, plain) {
+			t.Errorf("Decrypt #%d = %x, want %x", i, out, plain)
+		}
+	}
+}
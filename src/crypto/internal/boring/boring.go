@@ -11,6 +11,8 @@
 package boring
 
 // #include "goboringcrypto.h"
+// #cgo openssl_static CFLAGS: -DGO_OPENSSL_STATIC
+// #cgo openssl_static LDFLAGS: -l:libcrypto.a -lpthread
 // #cgo LDFLAGS: -ldl
 import "C"
 import (
@@ -72,24 +74,14 @@ func fipsModeEnabled() bool {
 		C._goboringcrypto_FIPS_mode() == fipsOn
 }
 
-var randstub bool
+// headerVersion is the OPENSSL_VERSION_NUMBER of the headers the
+// package is built with.
+const headerVersion = C.OPENSSL_VERSION_NUMBER
 
-func RandStubbed() bool {
-	return randstub
-}
-
-func StubOpenSSLRand() {
-	if !randstub {
-		randstub = true
-		C._goboringcrypto_stub_openssl_rand()
-	}
-}
-
-func RestoreOpenSSLRand() {
-	if randstub {
-		randstub = false
-		C._goboringcrypto_restore_openssl_rand()
-	}
+// libraryVersion returns the version number of the loaded library, in
+// the same format as headerVersion.
+func libraryVersion() uint64 {
+	return uint64(C._goboringcrypto_OpenSSL_version_num())
 }
 
 // Unreachable marks code that should be unreachable
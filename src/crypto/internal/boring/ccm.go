@@ -0,0 +1,127 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"crypto/cipher"
+	"math"
+	"runtime"
+	"unsafe"
+)
+
+// NewCCM returns an AES-CCM AEAD, SP 800-38C, computed with the module's
+// EVP_aes_*_ccm. The nonce and tag sizes are checked by crypto/cipher.NewCCM.
+func (c *aesCipher) NewCCM(nonceSize, tagSize int) (cipher.AEAD, error) {
+	return &aesCCM{key: c.key, nonceSize: nonceSize, tagSize: tagSize}, nil
+}
+
+type aesCCM struct {
+	key       []byte
+	nonceSize int
+	tagSize   int
+}
+
+func (g *aesCCM) NonceSize() int {
+	return g.nonceSize
+}
+
+func (g *aesCCM) Overhead() int {
+	return g.tagSize
+}
+
+// maxLength returns the largest plaintext length that both the CCM length
+// field and the module's int lengths can hold.
+func (g *aesCCM) maxLength() int {
+	if q := 15 - g.nonceSize; q < 4 {
+		return 1<<(8*uint(q)) - 1
+	}
+	return math.MaxInt32 - aesBlockSize
+}
+
+func (g *aesCCM) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != g.nonceSize {
+		panic("cipher: incorrect nonce length given to CCM")
+	}
+	if len(plaintext) > g.maxLength() || len(additionalData) > math.MaxInt32 {
+		panic("cipher: message too large for CCM")
+	}
+	if len(dst)+len(plaintext)+g.tagSize < len(dst) {
+		panic("cipher: message too large for buffer")
+	}
+
+	// Make room in dst to append plaintext+overhead.
+	n := len(dst)
+	for cap(dst) < n+len(plaintext)+g.tagSize {
+		dst = append(dst[:cap(dst)], 0)
+	}
+	dst = dst[:n+len(plaintext)+g.tagSize]
+
+	// Check delayed until now to make sure len(dst) is accurate.
+	if inexactOverlap(dst[n:], plaintext) {
+		panic("cipher: invalid buffer overlap")
+	}
+
+	if ok := C._goboringcrypto_EVP_CIPHER_CTX_seal_ccm(
+		(*C.uint8_t)(unsafe.Pointer(&dst[n])),
+		base(nonce), C.int(len(nonce)),
+		base(additionalData), C.int(len(additionalData)),
+		base(plaintext), C.int(len(plaintext)),
+		C.int(g.tagSize), base(g.key), C.int(len(g.key)*8)); ok != 1 {
+		panic("boringcrypto: EVP_CIPHER_CTX_seal_ccm fail")
+	}
+	runtime.KeepAlive(g)
+	return dst
+}
+
+func (g *aesCCM) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != g.nonceSize {
+		panic("cipher: incorrect nonce length given to CCM")
+	}
+	if len(ciphertext) < g.tagSize {
+		return nil, errOpen
+	}
+	if len(ciphertext)-g.tagSize > g.maxLength() || len(additionalData) > math.MaxInt32 {
+		return nil, errOpen
+	}
+
+	// Make room in dst to append ciphertext without tag.
+	n := len(dst)
+	for cap(dst) < n+len(ciphertext)-g.tagSize {
+		dst = append(dst[:cap(dst)], 0)
+	}
+	dst = dst[:n+len(ciphertext)-g.tagSize]
+
+	// Check delayed until now to make sure len(dst) is accurate.
+	if inexactOverlap(dst[n:], ciphertext) {
+		panic("cipher: invalid buffer overlap")
+	}
+
+	tag := ciphertext[len(ciphertext)-g.tagSize:]
+
+	ok := C._goboringcrypto_EVP_CIPHER_CTX_open_ccm(
+		base(ciphertext), C.int(len(ciphertext)-g.tagSize),
+		base(additionalData), C.int(len(additionalData)),
+		base(tag), C.int(g.tagSize),
+		base(nonce), C.int(len(nonce)),
+		base(g.key), C.int(len(g.key)*8),
+		base(dst[n:]))
+	runtime.KeepAlive(g)
+	if ok == 0 {
+		// Zero output buffer on error.
+		for i := range dst[n:] {
+			dst[n+i] = 0
+		}
+		return nil, errOpen
+	}
+	return dst, nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"hash"
+	"runtime"
+	"unsafe"
+)
+
+// NewCMAC returns a new AES-CMAC hash keyed with the cipher's key,
+// computed by the module's CMAC_CTX. It is used by crypto/cmac.
+func (c *aesCipher) NewCMAC() (hash.Hash, error) {
+	var cipher *C.EVP_CIPHER
+	switch len(c.key) * 8 {
+	case 128:
+		cipher = C._goboringcrypto_EVP_aes_128_cbc()
+	case 192:
+		cipher = C._goboringcrypto_EVP_aes_192_cbc()
+	case 256:
+		cipher = C._goboringcrypto_EVP_aes_256_cbc()
+	}
+
+	h := &boringCMAC{ctx: C._goboringcrypto_CMAC_CTX_new()}
+	if h.ctx == nil {
+		return nil, NewOpenSSLError("CMAC_CTX_new failed")
+	}
+	// Note: Because of the finalizer, any time h.ctx is passed to cgo,
+	// that call must be followed by a call to runtime.KeepAlive(h),
+	// to make sure h is not collected (and finalized) before the cgo
+	// call returns.
+	runtime.SetFinalizer(h, (*boringCMAC).finalize)
+	if C._goboringcrypto_CMAC_Init(h.ctx, unsafe.Pointer(base(c.key)), C.size_t(len(c.key)), cipher, nil) != 1 {
+		return nil, NewOpenSSLError("CMAC_Init failed")
+	}
+	runtime.KeepAlive(h)
+	return h, nil
+}
+
+type boringCMAC struct {
+	ctx *C.GO_CMAC_CTX
+}
+
+func (h *boringCMAC) finalize() {
+	C._goboringcrypto_CMAC_CTX_free(h.ctx)
+}
+
+func (h *boringCMAC) Reset() {
+	// A nil key, cipher and engine restart the computation with the key
+	// already set.
+	if C._goboringcrypto_CMAC_Init(h.ctx, nil, 0, nil, nil) != 1 {
+		panic("boringcrypto: CMAC_Init failed")
+	}
+	runtime.KeepAlive(h)
+}
+
+func (h *boringCMAC) Write(p []byte) (int, error) {
+	if len(p) > 0 && C._goboringcrypto_CMAC_Update(h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) != 1 {
+		panic("boringcrypto: CMAC_Update failed")
+	}
+	runtime.KeepAlive(h)
+	return len(p), nil
+}
+
+func (h *boringCMAC) Size() int { return aesBlockSize }
+
+func (h *boringCMAC) BlockSize() int { return aesBlockSize }
+
+func (h *boringCMAC) Sum(in []byte) []byte {
+	// Finish a copy of the context, because Sum must not change the
+	// underlying state.
+	ctx := C._goboringcrypto_CMAC_CTX_new()
+	if ctx == nil {
+		panic("boringcrypto: CMAC_CTX_new failed")
+	}
+	defer C._goboringcrypto_CMAC_CTX_free(ctx)
+	if C._goboringcrypto_CMAC_CTX_copy(ctx, h.ctx) != 1 {
+		panic("boringcrypto: CMAC_CTX_copy failed")
+	}
+	runtime.KeepAlive(h)
+	var sum [aesBlockSize]byte
+	var n C.size_t
+	if C._goboringcrypto_CMAC_Final(ctx, (*C.uchar)(unsafe.Pointer(&sum[0])), &n) != 1 || n != aesBlockSize {
+		panic("boringcrypto: CMAC_Final failed")
+	}
+	return append(in, sum[:]...)
+}
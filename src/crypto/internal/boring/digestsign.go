@@ -0,0 +1,382 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"crypto"
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// digestSignCtx is the state shared by DigestSigner and DigestVerifier:
+// an EVP_MD_CTX set up by EVP_DigestSignInit or EVP_DigestVerifyInit,
+// and the EVP_PKEY it refers to.
+type digestSignCtx struct {
+	ctx  *C.GO_EVP_MD_CTX
+	pkey *C.GO_EVP_PKEY
+}
+
+// newDigestSignCtx takes ownership of pkey. If setup is not nil, it is
+// called with the EVP_PKEY_CTX of the operation to set parameters
+// such as the padding mode.
+func newDigestSignCtx(pkey *C.GO_EVP_PKEY, h crypto.Hash, verify bool, setup func(*C.GO_EVP_PKEY_CTX) error) (*digestSignCtx, error) {
+	d := &digestSignCtx{pkey: pkey}
+	// Note: Because of the finalizer, any time d.ctx or d.pkey is passed
+	// to cgo, that call must be followed by a call to runtime.KeepAlive(d),
+	// to make sure d is not collected (and finalized) before the cgo
+	// call returns.
+	runtime.SetFinalizer(d, (*digestSignCtx).finalize)
+
+	md := cryptoHashToMD(h)
+	if md == nil {
+		return nil, errors.New("boringcrypto: unsupported hash function")
+	}
+	d.ctx = C._goboringcrypto_EVP_MD_CTX_create()
+	if d.ctx == nil {
+		return nil, NewOpenSSLError("EVP_MD_CTX_create failed")
+	}
+	var pctx *C.GO_EVP_PKEY_CTX
+	if verify {
+		if C._goboringcrypto_EVP_DigestVerifyInit(d.ctx, &pctx, md, nil, d.pkey) != 1 {
+			return nil, NewOpenSSLError("EVP_DigestVerifyInit failed")
+		}
+	} else {
+		if C._goboringcrypto_EVP_DigestSignInit(d.ctx, &pctx, md, nil, d.pkey) != 1 {
+			return nil, NewOpenSSLError("EVP_DigestSignInit failed")
+		}
+	}
+	if setup != nil {
+		if err := setup(pctx); err != nil {
+			return nil, err
+		}
+	}
+	runtime.KeepAlive(d)
+	return d, nil
+}
+
+func (d *digestSignCtx) finalize() {
+	if d.ctx != nil {
+		C._goboringcrypto_EVP_MD_CTX_free(d.ctx)
+	}
+	C._goboringcrypto_EVP_PKEY_free(d.pkey)
+}
+
+func (d *digestSignCtx) Write(p []byte) (int, error) {
+	if len(p) > 0 && C._goboringcrypto_EVP_DigestUpdate(d.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) != 1 {
+		panic("boringcrypto: EVP_DigestUpdate failed")
+	}
+	runtime.KeepAlive(d)
+	return len(p), nil
+}
+
+// A DigestSigner hashes a message written to it and signs the digest,
+// both inside OpenSSL, so that the message does not have to be held
+// in memory.
+type DigestSigner struct {
+	*digestSignCtx
+}
+
+// Sign returns the signature of the message written so far.
+// It does not change the underlying state, so more data may be
+// written and Sign called again.
+func (s *DigestSigner) Sign() ([]byte, error) {
+	var sigLen C.size_t
+	if C._goboringcrypto_EVP_DigestSignFinal(s.ctx, nil, &sigLen) != 1 {
+		return nil, NewOpenSSLError("EVP_DigestSignFinal failed")
+	}
+	sig := make([]byte, sigLen)
+	if C._goboringcrypto_EVP_DigestSignFinal(s.ctx, (*C.uchar)(unsafe.Pointer(&sig[0])), &sigLen) != 1 {
+		return nil, NewOpenSSLError("EVP_DigestSignFinal failed")
+	}
+	runtime.KeepAlive(s)
+	return sig[:sigLen], nil
+}
+
+// A DigestVerifier is the verifying counterpart of DigestSigner.
+type DigestVerifier struct {
+	*digestSignCtx
+}
+
+// Verify checks that sig is a valid signature of the message written
+// so far. Like DigestSigner.Sign, it does not change the underlying state.
+func (v *DigestVerifier) Verify(sig []byte) error {
+	if C._goboringcrypto_EVP_DigestVerifyFinal(v.ctx, base(sig), C.size_t(len(sig))) != 1 {
+		return NewOpenSSLError("EVP_DigestVerifyFinal failed")
+	}
+	runtime.KeepAlive(v)
+	return nil
+}
+
+// pairwiseTest is the pairwise consistency test run on private keys
+// when they are generated, imported or loaded: it signs a fixed message
+// with priv and verifies the signature with pub, both through
+// EVP_DigestSign and EVP_DigestVerify with SHA-256. pub may be priv, or
+// its public half for keys that stay in a device. setup is as for
+// newDigestSignCtx.
+func pairwiseTest(priv, pub *C.GO_EVP_PKEY, setup func(*C.GO_EVP_PKEY_CTX) error) error {
+	msg := []byte("boringcrypto: pairwise consistency test")
+	sign := func() ([]byte, error) {
+		if C._goboringcrypto_EVP_PKEY_up_ref(priv) != 1 {
+			return nil, NewOpenSSLError("EVP_PKEY_up_ref failed")
+		}
+		d, err := newDigestSignCtx(priv, crypto.SHA256, false, setup)
+		if err != nil {
+			return nil, err
+		}
+		s := &DigestSigner{d}
+		s.Write(msg)
+		return s.Sign()
+	}
+	sig, err := sign()
+	if err != nil {
+		return err
+	}
+	if C._goboringcrypto_EVP_PKEY_up_ref(pub) != 1 {
+		return NewOpenSSLError("EVP_PKEY_up_ref failed")
+	}
+	d, err := newDigestSignCtx(pub, crypto.SHA256, true, setup)
+	if err != nil {
+		return err
+	}
+	v := &DigestVerifier{d}
+	v.Write(msg)
+	if v.Verify(sig) != nil {
+		clearErrors()
+		return errors.New("boringcrypto: pairwise consistency test failed")
+	}
+	return nil
+}
+
+func newRSAPKey(withKey func(func(*C.GO_RSA) C.int) C.int) (*C.GO_EVP_PKEY, error) {
+	pkey := C._goboringcrypto_EVP_PKEY_new()
+	if pkey == nil {
+		return nil, NewOpenSSLError("EVP_PKEY_new failed")
+	}
+	if withKey(func(key *C.GO_RSA) C.int {
+		return C._goboringcrypto_EVP_PKEY_set1_RSA(pkey, key)
+	}) == 0 {
+		C._goboringcrypto_EVP_PKEY_free(pkey)
+		return nil, NewOpenSSLError("EVP_PKEY_set1_RSA failed")
+	}
+	return pkey, nil
+}
+
+func newECPKey(key *C.GO_EC_KEY) (*C.GO_EVP_PKEY, error) {
+	pkey := C._goboringcrypto_EVP_PKEY_new()
+	if pkey == nil {
+		return nil, NewOpenSSLError("EVP_PKEY_new failed")
+	}
+	if C._goboringcrypto_EVP_PKEY_set1_EC_KEY(pkey, key) != 1 {
+		C._goboringcrypto_EVP_PKEY_free(pkey)
+		return nil, NewOpenSSLError("EVP_PKEY_set1_EC_KEY failed")
+	}
+	return pkey, nil
+}
+
+func setupRSAPKCS1v15(pctx *C.GO_EVP_PKEY_CTX) error {
+	if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_padding(pctx, C.GO_RSA_PKCS1_PADDING) <= 0 {
+		return NewOpenSSLError("EVP_PKEY_CTX_set_rsa_padding failed")
+	}
+	return nil
+}
+
+// NewDigestSignerRSAPKCS1v15 returns a DigestSigner computing
+// RSASSA-PKCS1-v1_5 signatures with priv over the h digest of a message.
+func NewDigestSignerRSAPKCS1v15(priv *PrivateKeyRSA, h crypto.Hash) (*DigestSigner, error) {
+	pkey, err := priv.newPKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDigestSignCtx(pkey, h, false, setupRSAPKCS1v15)
+	if err != nil {
+		return nil, err
+	}
+	return &DigestSigner{d}, nil
+}
+
+// NewDigestVerifierRSAPKCS1v15 returns a DigestVerifier checking
+// RSASSA-PKCS1-v1_5 signatures with pub over the h digest of a message.
+func NewDigestVerifierRSAPKCS1v15(pub *PublicKeyRSA, h crypto.Hash) (*DigestVerifier, error) {
+	pkey, err := pub.newPKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDigestSignCtx(pkey, h, true, setupRSAPKCS1v15)
+	if err != nil {
+		return nil, err
+	}
+	return &DigestVerifier{d}, nil
+}
+
+// setupRSAPSS returns a setup function for RSASSA-PSS with MGF1 using
+// the same hash as the message digest, and the given salt length.
+func setupRSAPSS(h crypto.Hash, saltLen int) func(*C.GO_EVP_PKEY_CTX) error {
+	return func(pctx *C.GO_EVP_PKEY_CTX) error {
+		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_padding(pctx, C.GO_RSA_PKCS1_PSS_PADDING) <= 0 {
+			return NewOpenSSLError("EVP_PKEY_CTX_set_rsa_padding failed")
+		}
+		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_pss_saltlen(pctx, C.int(saltLen)) <= 0 {
+			return NewOpenSSLError("EVP_PKEY_set_rsa_pss_saltlen failed")
+		}
+		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_mgf1_md(pctx, cryptoHashToMD(h)) <= 0 {
+			return NewOpenSSLError("EVP_PKEY_set_rsa_mgf1_md failed")
+		}
+		return nil
+	}
+}
+
+// NewDigestSignerRSAPSS returns a DigestSigner computing RSASSA-PSS
+// signatures with priv over the h digest of a message. As with
+// SignRSAPSS, a saltLen of 0 means a salt as long as the digest.
+func NewDigestSignerRSAPSS(priv *PrivateKeyRSA, h crypto.Hash, saltLen int) (*DigestSigner, error) {
+	if saltLen == 0 {
+		saltLen = -1
+	}
+	pkey, err := priv.newPKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDigestSignCtx(pkey, h, false, setupRSAPSS(h, saltLen))
+	if err != nil {
+		return nil, err
+	}
+	return &DigestSigner{d}, nil
+}
+
+// NewDigestVerifierRSAPSS returns a DigestVerifier checking RSASSA-PSS
+// signatures with pub over the h digest of a message. As with
+// VerifyRSAPSS, a saltLen of 0 means the salt length is recovered
+// from the signature.
+func NewDigestVerifierRSAPSS(pub *PublicKeyRSA, h crypto.Hash, saltLen int) (*DigestVerifier, error) {
+	if saltLen == 0 {
+		saltLen = -2 // auto-recover
+	}
+	pkey, err := pub.newPKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDigestSignCtx(pkey, h, true, setupRSAPSS(h, saltLen))
+	if err != nil {
+		return nil, err
+	}
+	return &DigestVerifier{d}, nil
+}
+
+// NewDigestSignerECDSA returns a DigestSigner computing ASN.1-encoded
+// ECDSA signatures with priv over the h digest of a message.
+func NewDigestSignerECDSA(priv *PrivateKeyECDSA, h crypto.Hash) (*DigestSigner, error) {
+	pkey, err := priv.newPKey()
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDigestSignCtx(pkey, h, false, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DigestSigner{d}, nil
+}
+
+// NewDigestVerifierECDSA returns a DigestVerifier checking ASN.1-encoded
+// ECDSA signatures with pub over the h digest of a message.
+func NewDigestVerifierECDSA(pub *PublicKeyECDSA, h crypto.Hash) (*DigestVerifier, error) {
+	pkey, err := newECPKey(pub.key)
+	runtime.KeepAlive(pub)
+	if err != nil {
+		return nil, err
+	}
+	d, err := newDigestSignCtx(pkey, h, true, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DigestVerifier{d}, nil
+}
+
+// signPKey signs hashed, a digest computed with md, with pkey using
+// EVP_PKEY_sign. md may be nil if it is unknown. For RSA keys, padding
+// selects PKCS #1 v1.5 or PSS, the latter with the given salt length
+// and MGF1 using md; it is 0 for EC keys.
+func signPKey(pkey *C.GO_EVP_PKEY, md *C.GO_EVP_MD, hashed []byte, padding C.int, saltLen int) ([]byte, error) {
+	ctx, err := newSignatureCtx(pkey, signInit, md, padding, saltLen)
+	if err != nil {
+		return nil, err
+	}
+	defer C._goboringcrypto_EVP_PKEY_CTX_free(ctx)
+	var sigLen C.size_t
+	if C._goboringcrypto_EVP_PKEY_sign(ctx, nil, &sigLen, base(hashed), C.size_t(len(hashed))) != 1 {
+		return nil, NewOpenSSLError("EVP_PKEY_sign failed")
+	}
+	sig := make([]byte, sigLen)
+	if C._goboringcrypto_EVP_PKEY_sign(ctx, base(sig), &sigLen, base(hashed), C.size_t(len(hashed))) != 1 {
+		return nil, NewOpenSSLError("EVP_PKEY_sign failed")
+	}
+	return sig[:sigLen], nil
+}
+
+// verifyPKey checks that sig is a signature of hashed with pkey using
+// EVP_PKEY_verify. The other parameters are as for signPKey.
+func verifyPKey(pkey *C.GO_EVP_PKEY, md *C.GO_EVP_MD, hashed, sig []byte, padding C.int, saltLen int) error {
+	ctx, err := newSignatureCtx(pkey, verifyInit, md, padding, saltLen)
+	if err != nil {
+		return err
+	}
+	defer C._goboringcrypto_EVP_PKEY_CTX_free(ctx)
+	if C._goboringcrypto_EVP_PKEY_verify(ctx, base(sig), C.uint(len(sig)), base(hashed), C.size_t(len(hashed))) != 1 {
+		return NewOpenSSLError("EVP_PKEY_verify failed")
+	}
+	return nil
+}
+
+// newSignatureCtx returns an EVP_PKEY_CTX for pkey, set up by init and
+// the parameters of signPKey, which the caller must free.
+func newSignatureCtx(pkey *C.GO_EVP_PKEY, init func(*C.GO_EVP_PKEY_CTX) C.int,
+	md *C.GO_EVP_MD, padding C.int, saltLen int) (ctx *C.GO_EVP_PKEY_CTX, err error) {
+	ctx = C._goboringcrypto_EVP_PKEY_CTX_new(pkey, nil)
+	if ctx == nil {
+		return nil, NewOpenSSLError("EVP_PKEY_CTX_new failed")
+	}
+	defer func() {
+		if err != nil {
+			C._goboringcrypto_EVP_PKEY_CTX_free(ctx)
+			ctx = nil
+		}
+	}()
+	if init(ctx) != 1 {
+		return nil, NewOpenSSLError("EVP_PKEY_sign_init/verify_init failed")
+	}
+	if padding != 0 {
+		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_padding(ctx, padding) <= 0 {
+			return nil, NewOpenSSLError("EVP_PKEY_CTX_set_rsa_padding failed")
+		}
+	}
+	if md != nil && C._goboringcrypto_EVP_PKEY_CTX_set_signature_md(ctx, md) <= 0 {
+		return nil, NewOpenSSLError("EVP_PKEY_CTX_set_signature_md failed")
+	}
+	if padding == C.GO_RSA_PKCS1_PSS_PADDING {
+		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_pss_saltlen(ctx, C.int(saltLen)) <= 0 {
+			return nil, NewOpenSSLError("EVP_PKEY_set_rsa_pss_saltlen failed")
+		}
+		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_mgf1_md(ctx, md) <= 0 {
+			return nil, NewOpenSSLError("EVP_PKEY_set_rsa_mgf1_md failed")
+		}
+	}
+	return ctx, nil
+}
+
+func signInit(ctx *C.GO_EVP_PKEY_CTX) C.int {
+	return C._goboringcrypto_EVP_PKEY_sign_init(ctx)
+}
+
+func verifyInit(ctx *C.GO_EVP_PKEY_CTX) C.int {
+	return C._goboringcrypto_EVP_PKEY_verify_init(ctx)
+}
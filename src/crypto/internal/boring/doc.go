@@ -7,8 +7,17 @@
 // If BoringCrypto is not available, the functions in this package all panic.
 package boring
 
+import "hash"
+
 // Enabled returns whether or not the boring package is enabled. When
 // the boring package is enabled that means FIPS mode is enabled.
 func Enabled() bool {
 	return enabled
 }
+
+// A Cloner is a hash that can copy its running state, such as the hashes
+// and HMACs returned by this package. Callers that fork a running hash
+// type-assert for it instead of writing the input again.
+type Cloner interface {
+	Clone() hash.Hash
+}
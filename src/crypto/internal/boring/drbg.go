@@ -0,0 +1,159 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// TestDRBG is an HMAC_DRBG instantiated with SHA-256, as specified in
+// NIST SP 800-90A, section 10.1.2. Its output is entirely determined by
+// the inputs it is seeded with, which makes it suitable for known-answer
+// tests and unsuitable for anything else. Reseed counters and prediction
+// resistance are not implemented.
+//
+// A TestDRBG is safe for concurrent use by multiple goroutines.
+type TestDRBG struct {
+	mu   sync.Mutex
+	k, v []byte
+}
+
+// testDRBGMaxRequest is the maximum number of bytes returned by a single
+// HMAC_DRBG generate call (2^19 bits).
+const testDRBGMaxRequest = 1 << 16
+
+// NewTestDRBG returns a TestDRBG instantiated with the given entropy input,
+// nonce and personalization string.
+func NewTestDRBG(entropy, nonce, personalization []byte) *TestDRBG {
+	d := &TestDRBG{
+		k: make([]byte, 32),
+		v: make([]byte, 32),
+	}
+	for i := range d.v {
+		d.v[i] = 0x01
+	}
+	d.update(entropy, nonce, personalization)
+	return d
+}
+
+func (d *TestDRBG) hmac(data ...[]byte) []byte {
+	h := NewHMAC(NewSHA256, d.k)
+	for _, b := range data {
+		h.Write(b)
+	}
+	return h.Sum(nil)
+}
+
+// update implements HMAC_DRBG_Update with provided_data being the
+// concatenation of data.
+func (d *TestDRBG) update(data ...[]byte) {
+	empty := true
+	for _, b := range data {
+		if len(b) > 0 {
+			empty = false
+		}
+	}
+	d.k = d.hmac(append([][]byte{d.v, {0x00}}, data...)...)
+	d.v = d.hmac(d.v)
+	if empty {
+		return
+	}
+	d.k = d.hmac(append([][]byte{d.v, {0x01}}, data...)...)
+	d.v = d.hmac(d.v)
+}
+
+// Reseed mixes fresh entropy input and optional additional input into
+// the state of d.
+func (d *TestDRBG) Reseed(entropy, additional []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.update(entropy, additional)
+}
+
+// Generate fills out with the output of one HMAC_DRBG generate call
+// using the optional additional input. It panics if len(out) exceeds
+// the maximum request size of 65536 bytes.
+func (d *TestDRBG) Generate(out, additional []byte) {
+	if len(out) > testDRBGMaxRequest {
+		panic("boringcrypto: TestDRBG request too large")
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.generate(out, additional)
+}
+
+func (d *TestDRBG) generate(out, additional []byte) {
+	if len(additional) > 0 {
+		d.update(additional)
+	}
+	for n := 0; n < len(out); {
+		d.v = d.hmac(d.v)
+		n += copy(out[n:], d.v)
+	}
+	d.update(additional)
+}
+
+// Read implements io.Reader, splitting large reads into several
+// generate calls. It never returns an error.
+func (d *TestDRBG) Read(b []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for n := 0; n < len(b); n += testDRBGMaxRequest {
+		end := n + testDRBGMaxRequest
+		if end > len(b) {
+			end = len(b)
+		}
+		d.generate(b[n:end], nil)
+	}
+	return len(b), nil
+}
+
+var (
+	// testRandMu is held for as long as a TestDRBG is installed,
+	// so that concurrent callers of SetTestDRBG take turns.
+	testRandMu sync.Mutex
+	testRand   unsafe.Pointer // *TestDRBG
+)
+
+// SetTestDRBG installs d as the source of all random bytes drawn by
+// OpenSSL, including RSA-PSS salts, ECDSA nonces and key generation,
+// and returns a function that restores the previous source.
+// The replacement is process-wide: a second call blocks until the first
+// installation has been restored.
+//
+// SetTestDRBG must only be called from tests; it panics otherwise.
+func SetTestDRBG(d *TestDRBG) (restore func()) {
+	name := runtime_arg0()
+	if !hasSuffix(name, "_test") && !hasSuffix(name, ".test") {
+		panic("boringcrypto: SetTestDRBG called outside of a test")
+	}
+	testRandMu.Lock()
+	atomic.StorePointer(&testRand, unsafe.Pointer(d))
+	if C._goboringcrypto_install_test_rand() != 1 {
+		atomic.StorePointer(&testRand, nil)
+		testRandMu.Unlock()
+		panic("boringcrypto: RAND_set_rand_method failed")
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			defer testRandMu.Unlock()
+			if C._goboringcrypto_restore_openssl_rand() != 1 {
+				panic("boringcrypto: RAND_set_rand_method failed")
+			}
+			atomic.StorePointer(&testRand, nil)
+		})
+	}
+}
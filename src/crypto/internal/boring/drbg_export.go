@@ -0,0 +1,32 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// Files containing //export directives may only declare C functions in
+// their preamble, so the callback lives apart from the rest of drbg.go.
+
+import "C"
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+//export _goboringcrypto_test_rand_bytes
+func _goboringcrypto_test_rand_bytes(buf *C.uchar, num C.int) C.int {
+	d := (*TestDRBG)(atomic.LoadPointer(&testRand))
+	if d == nil || num < 0 {
+		return 0
+	}
+	if num > 0 {
+		d.Read((*[1 << 30]byte)(unsafe.Pointer(buf))[:num:num])
+	}
+	return 1
+}
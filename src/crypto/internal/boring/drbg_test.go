@@ -0,0 +1,70 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+// TestTestDRBG checks the first HMAC_DRBG SHA-256 vector, without
+// prediction resistance, from NIST CAVP's HMAC_DRBG.rsp.
+func TestTestDRBG(t *testing.T) {
+	if !Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	entropy := decodeHex(t, "ca851911349384bffe89de1cbdc46e6831e44d34a4fb935ee285dd14b71a7488")
+	nonce := decodeHex(t, "659ba96c601dc69fc902940805ec0ca8")
+	want := decodeHex(t, "e528e9abf2dece54d47c7e75e5fe302149f817ea9fb4bee6f4199697d04d5b89"+
+		"d54fbb978a15b5c443c9ec21036d2460b6f73ebad0dc2aba6e624abf07745bc1"+
+		"07694bb7547bb0995f70de25d6b29e2d3011bb19d27676c07162c8b5ccde0668"+
+		"961df86803482cb37ed6d5c0bb8d50cf1f50d476aa0458bdaba806f48be9dcb8")
+
+	d := NewTestDRBG(entropy, nonce, nil)
+	got := make([]byte, len(want))
+	d.Generate(got, nil)
+	d.Generate(got, nil)
+	if !bytes.Equal(got, want) {
+		t.Errorf("got %x, want %x", got, want)
+	}
+}
+
+func TestSetTestDRBG(t *testing.T) {
+	if !Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	seed := []byte("test DRBG seed for SetTestDRBG..")
+	want := make([]byte, 64)
+	NewTestDRBG(seed, nil, nil).Generate(want, nil)
+
+	restore := SetTestDRBG(NewTestDRBG(seed, nil, nil))
+	got := make([]byte, 64)
+	RandReader.Read(got)
+	restore()
+	if !bytes.Equal(got, want) {
+		t.Errorf("RandReader with test DRBG = %x, want %x", got, want)
+	}
+
+	RandReader.Read(got)
+	if bytes.Equal(got, want) {
+		t.Error("RandReader still uses the test DRBG after restore")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"encoding/asn1"
 	"errors"
 	"math/big"
+	"os"
 	"runtime"
 	"unsafe"
 )
@@ -27,10 +28,29 @@ type ecdsaSignature struct {
 
 type PrivateKeyECDSA struct {
 	key *C.GO_EC_KEY
+	// pkey is set for keys parsed or loaded into the module with
+	// ParsePrivateKey or LoadPrivateKey. Signing then goes through pkey,
+	// and key only has the public point.
+	pkey *C.GO_EVP_PKEY
 }
 
 func (k *PrivateKeyECDSA) finalize() {
 	C._goboringcrypto_EC_KEY_free(k.key)
+	if k.pkey != nil {
+		C._goboringcrypto_EVP_PKEY_free(k.pkey)
+	}
+}
+
+// newPKey returns an EVP_PKEY for k, which the caller must free.
+func (k *PrivateKeyECDSA) newPKey() (*C.GO_EVP_PKEY, error) {
+	defer runtime.KeepAlive(k)
+	if k.pkey == nil {
+		return newECPKey(k.key)
+	}
+	if C._goboringcrypto_EVP_PKEY_up_ref(k.pkey) != 1 {
+		return nil, NewOpenSSLError("EVP_PKEY_up_ref failed")
+	}
+	return k.pkey, nil
 }
 
 type PublicKeyECDSA struct {
@@ -42,12 +62,12 @@ func (k *PublicKeyECDSA) finalize() {
 }
 
 var errUnknownCurve = errors.New("boringcrypto: unknown elliptic curve")
-var errUnsupportedCurve = errors.New("boringcrypto: unsupported elliptic curve")
+var errP224Signing = errors.New("boringcrypto: P-224 signing is not permitted; set GOLANG_FIPS_P224_SIGN=1 to allow it")
 
 func curveNID(curve string) (C.int, error) {
 	switch curve {
 	case "P-224":
-		return 0, errUnsupportedCurve
+		return C.GO_NID_secp224r1, nil
 	case "P-256":
 		return C.GO_NID_X9_62_prime256v1, nil
 	case "P-384":
@@ -58,6 +78,17 @@ func curveNID(curve string) (C.int, error) {
 	return 0, errUnknownCurve
 }
 
+// checkSigningCurve reports whether private keys on curve may be generated
+// and used for signing. P-224 remains approved for verifying existing
+// signatures, but creating new ones with it is a policy decision, so it is
+// only allowed when GOLANG_FIPS_P224_SIGN=1.
+func checkSigningCurve(curve string) error {
+	if curve == "P-224" && os.Getenv("GOLANG_FIPS_P224_SIGN") != "1" {
+		return errP224Signing
+	}
+	return nil
+}
+
 func NewPublicKeyECDSA(curve string, X, Y *big.Int) (*PublicKeyECDSA, error) {
 	key, err := newECKey(curve, X, Y)
 	if err != nil {
@@ -106,6 +137,9 @@ func newECKey(curve string, X, Y *big.Int) (*C.GO_EC_KEY, error) {
 }
 
 func NewPrivateKeyECDSA(curve string, X, Y *big.Int, D *big.Int) (*PrivateKeyECDSA, error) {
+	if err := checkSigningCurve(curve); err != nil {
+		return nil, err
+	}
 	key, err := newECKey(curve, X, Y)
 	if err != nil {
 		return nil, err
@@ -119,7 +153,11 @@ func NewPrivateKeyECDSA(curve string, X, Y *big.Int, D *big.Int) (*PrivateKeyECD
 		C._goboringcrypto_EC_KEY_free(key)
 		return nil, NewOpenSSLError("EC_KEY_set_private_key failed")
 	}
-	k := &PrivateKeyECDSA{key}
+	if err := checkKeyECDSA(key); err != nil {
+		C._goboringcrypto_EC_KEY_free(key)
+		return nil, err
+	}
+	k := &PrivateKeyECDSA{key: key}
 	// Note: Because of the finalizer, any time k.key is passed to cgo,
 	// that call must be followed by a call to runtime.KeepAlive(k),
 	// to make sure k is not collected (and finalized) before the cgo
@@ -128,6 +166,21 @@ func NewPrivateKeyECDSA(curve string, X, Y *big.Int, D *big.Int) (*PrivateKeyECD
 	return k, nil
 }
 
+// checkKeyECDSA validates a newly generated or imported private key with
+// EC_KEY_check_key, which also confirms that the public point matches the
+// private scalar, and then runs a pairwise consistency test.
+func checkKeyECDSA(key *C.GO_EC_KEY) error {
+	if C._goboringcrypto_EC_KEY_check_key(key) != 1 {
+		return NewOpenSSLError("EC_KEY_check_key failed")
+	}
+	pkey, err := newECPKey(key)
+	if err != nil {
+		return err
+	}
+	defer C._goboringcrypto_EVP_PKEY_free(pkey)
+	return pairwiseTest(pkey, pkey, nil)
+}
+
 func SignECDSA(priv *PrivateKeyECDSA, hash []byte, h crypto.Hash) (r, s *big.Int, err error) {
 	// We could use ECDSA_do_sign instead but would need to convert
 	// the resulting BIGNUMs to *big.Int form. If we're going to do a
@@ -145,6 +198,18 @@ func SignECDSA(priv *PrivateKeyECDSA, hash []byte, h crypto.Hash) (r, s *big.Int
 }
 
 func SignMarshalECDSA(priv *PrivateKeyECDSA, hash []byte, h crypto.Hash) ([]byte, error) {
+	if priv.pkey != nil {
+		if h == crypto.Hash(0) {
+			defer runtime.KeepAlive(priv)
+			return signPKey(priv.pkey, nil, hash, 0, 0)
+		}
+		s, err := NewDigestSignerECDSA(priv, h)
+		if err != nil {
+			return nil, err
+		}
+		s.Write(hash)
+		return s.Sign()
+	}
 	size := C._goboringcrypto_ECDSA_size(priv.key)
 	sig := make([]byte, size)
 	var sigLen C.uint
@@ -187,7 +252,88 @@ func VerifyECDSA(pub *PublicKeyECDSA, msg []byte, r, s *big.Int, h crypto.Hash)
 	return ok
 }
 
+// ecdsaRawSize returns the length of each half of a raw signature with
+// key, the byte length of the curve order. For the supported curves, it
+// is that of the field.
+func ecdsaRawSize(key *C.GO_EC_KEY) int {
+	return (int(C._goboringcrypto_EC_GROUP_get_degree(C._goboringcrypto_EC_KEY_get0_group(key))) + 7) / 8
+}
+
+// SignRawECDSA signs hash with ECDSA_do_sign and returns the signature in
+// the IEEE P1363 format, r || s, each zero-padded to the byte length of
+// the curve order.
+func SignRawECDSA(priv *PrivateKeyECDSA, hash []byte) ([]byte, error) {
+	defer runtime.KeepAlive(priv)
+	if priv.pkey != nil {
+		return signRawPKey(priv, hash)
+	}
+	sig := C._goboringcrypto_ECDSA_do_sign(base(hash), C.size_t(len(hash)), priv.key)
+	if sig == nil {
+		return nil, NewOpenSSLError("ECDSA_do_sign failed")
+	}
+	defer C._goboringcrypto_ECDSA_SIG_free(sig)
+	var r, s *C.GO_BIGNUM
+	C._goboringcrypto_ECDSA_SIG_get0(sig, &r, &s)
+	size := ecdsaRawSize(priv.key)
+	if int(C._goboringcrypto_BN_num_bytes(r)) > size || int(C._goboringcrypto_BN_num_bytes(s)) > size {
+		return nil, errors.New("boringcrypto: ECDSA signature too large")
+	}
+	out := make([]byte, 2*size)
+	// BN_bn2bin writes the minimal big-endian form, so the halves are
+	// right-aligned to leave the zero padding in front.
+	C._goboringcrypto_BN_bn2bin(r, base(out[size-int(C._goboringcrypto_BN_num_bytes(r)):]))
+	C._goboringcrypto_BN_bn2bin(s, base(out[2*size-int(C._goboringcrypto_BN_num_bytes(s)):]))
+	return out, nil
+}
+
+// signRawPKey is SignRawECDSA for keys used through their EVP_PKEY,
+// which only return ASN.1-encoded signatures.
+func signRawPKey(priv *PrivateKeyECDSA, hash []byte) ([]byte, error) {
+	der, err := signPKey(priv.pkey, nil, hash, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	var esig ecdsaSignature
+	if _, err := asn1.Unmarshal(der, &esig); err != nil {
+		return nil, err
+	}
+	size := ecdsaRawSize(priv.key)
+	if len(esig.R.Bytes()) > size || len(esig.S.Bytes()) > size {
+		return nil, errors.New("boringcrypto: ECDSA signature too large")
+	}
+	out := make([]byte, 2*size)
+	esig.R.FillBytes(out[:size])
+	esig.S.FillBytes(out[size:])
+	return out, nil
+}
+
+// VerifyRawECDSA verifies sig, an IEEE P1363 signature as returned by
+// SignRawECDSA, of hash with ECDSA_do_verify.
+func VerifyRawECDSA(pub *PublicKeyECDSA, hash, sig []byte) bool {
+	defer runtime.KeepAlive(pub)
+	size := ecdsaRawSize(pub.key)
+	if len(sig) != 2*size {
+		return false
+	}
+	esig := C._goboringcrypto_ECDSA_SIG_new()
+	if esig == nil {
+		return false
+	}
+	defer C._goboringcrypto_ECDSA_SIG_free(esig)
+	r := C._goboringcrypto_BN_bin2bn(base(sig[:size]), C.size_t(size), nil)
+	s := C._goboringcrypto_BN_bin2bn(base(sig[size:]), C.size_t(size), nil)
+	if r == nil || s == nil || C._goboringcrypto_ECDSA_SIG_set0(esig, r, s) != 1 {
+		C._goboringcrypto_BN_free(r)
+		C._goboringcrypto_BN_free(s)
+		return false
+	}
+	return C._goboringcrypto_ECDSA_do_verify(base(hash), C.size_t(len(hash)), esig, pub.key) == 1
+}
+
 func GenerateKeyECDSA(curve string) (X, Y, D *big.Int, err error) {
+	if err := checkSigningCurve(curve); err != nil {
+		return nil, nil, nil, err
+	}
 	nid, err := curveNID(curve)
 	if err != nil {
 		return nil, nil, nil, err
@@ -200,6 +346,9 @@ func GenerateKeyECDSA(curve string) (X, Y, D *big.Int, err error) {
 	if C._goboringcrypto_EC_KEY_generate_key(key) == 0 {
 		return nil, nil, nil, NewOpenSSLError("EC_KEY_generate_key failed")
 	}
+	if err := checkKeyECDSA(key); err != nil {
+		return nil, nil, nil, err
+	}
 	group := C._goboringcrypto_EC_KEY_get0_group(key)
 	pt := C._goboringcrypto_EC_KEY_get0_public_key(key)
 	bd := C._goboringcrypto_EC_KEY_get0_private_key(key)
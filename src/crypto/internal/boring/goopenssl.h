@@ -15,6 +15,21 @@
 #include <openssl/ossl_typ.h>
 
 #define unlikely(x) __builtin_expect(!!(x), 0)
+#ifdef GO_OPENSSL_STATIC
+// With the openssl_static build tag libcrypto is linked in statically,
+// and the shim functions call it directly.
+#define DEFINEFUNC(ret, func, args, argscall)     \
+	static inline ret _goboringcrypto_##func args \
+	{                                             \
+		return func argscall;                     \
+	}
+
+#define DEFINEFUNCINTERNAL(ret, func, args, argscall)     \
+	static inline ret _goboringcrypto_internal_##func args \
+	{                                                      \
+		return func argscall;                              \
+	}
+#else
 #define DEFINEFUNC(ret, func, args, argscall)        \
 	typedef ret(*_goboringcrypto_PTR_##func) args;   \
 	static _goboringcrypto_PTR_##func _g_##func = 0; \
@@ -38,6 +53,7 @@
 		}                                            \
 		return _g_internal_##func argscall;                   \
 	}
+#endif
 
 #define DEFINEMACRO(ret, func, args, argscall)    \
 	static inline ret _goboringcrypto_##func args \
@@ -51,14 +67,20 @@ static void* handle;
 static void*
 _goboringcrypto_DLOPEN_OPENSSL(void)
 {
+#ifdef GO_OPENSSL_STATIC
+	// Nothing to load; return a non-NULL handle.
+	handle = &handle;
+#endif
 	if (handle)
 	{
 		return handle;
 	}
 #if OPENSSL_VERSION_NUMBER < 0x10100000L
 	handle = dlopen("libcrypto.so.10", RTLD_NOW | RTLD_GLOBAL);
-#else
+#elif OPENSSL_VERSION_NUMBER < 0x30000000L
 	handle = dlopen("libcrypto.so.1.1", RTLD_NOW | RTLD_GLOBAL);
+#else
+	handle = dlopen("libcrypto.so.3", RTLD_NOW | RTLD_GLOBAL);
 #endif
 	return handle;
 }
@@ -66,13 +88,24 @@ _goboringcrypto_DLOPEN_OPENSSL(void)
 #include <openssl/opensslv.h>
 #include <openssl/ssl.h>
 
-DEFINEFUNCINTERNAL(int, OPENSSL_init, (void), ())
+DEFINEFUNCINTERNAL(void, OPENSSL_init, (void), ())
 
 static void
 _goboringcrypto_OPENSSL_setup(void) {
 	_goboringcrypto_internal_OPENSSL_init();
 }
 
+// OpenSSL_version_num replaced SSLeay in OpenSSL 1.1.0.
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+DEFINEFUNCINTERNAL(unsigned long, SSLeay, (void), ())
+static inline unsigned long
+_goboringcrypto_OpenSSL_version_num(void) {
+	return _goboringcrypto_internal_SSLeay();
+}
+#else
+DEFINEFUNC(unsigned long, OpenSSL_version_num, (void), ())
+#endif
+
 #include <openssl/err.h>
 DEFINEFUNCINTERNAL(void, ERR_print_errors_fp, (FILE* fp), (fp))
 DEFINEFUNCINTERNAL(unsigned long, ERR_get_error, (void), ())
@@ -112,8 +145,23 @@ _goboringcrypto_CRYPTO_set_locking_callback(void (*locking_function)(int mode, i
 
 int _goboringcrypto_OPENSSL_thread_setup(void);
 
+// OpenSSL 3.0 removed FIPS_mode and FIPS_mode_set; the FIPS setting is
+// a property of the default library context instead.
+#if OPENSSL_VERSION_NUMBER < 0x30000000L
 DEFINEFUNC(int, FIPS_mode, (void), ())
 DEFINEFUNC(int, FIPS_mode_set, (int r), (r))
+#else
+DEFINEFUNCINTERNAL(int, EVP_default_properties_is_fips_enabled, (OSSL_LIB_CTX *libctx), (libctx))
+DEFINEFUNCINTERNAL(int, EVP_default_properties_enable_fips, (OSSL_LIB_CTX *libctx, int enable), (libctx, enable))
+static inline int
+_goboringcrypto_FIPS_mode(void) {
+	return _goboringcrypto_internal_EVP_default_properties_is_fips_enabled(NULL);
+}
+static inline int
+_goboringcrypto_FIPS_mode_set(int r) {
+	return _goboringcrypto_internal_EVP_default_properties_enable_fips(NULL, r);
+}
+#endif
 
 #include <openssl/rand.h>
 
@@ -121,9 +169,8 @@ DEFINEFUNC(int, RAND_set_rand_method, (const RAND_METHOD *rand), (rand))
 DEFINEFUNC(RAND_METHOD*, RAND_get_rand_method, (void), ())
 DEFINEFUNC(int, RAND_bytes, (uint8_t * arg0, size_t arg1), (arg0, arg1))
 
-int _goboringcrypto_stub_openssl_rand(void);
+int _goboringcrypto_install_test_rand(void);
 int _goboringcrypto_restore_openssl_rand(void);
-int fbytes(unsigned char *buf, int num);
 
 
 #include <openssl/obj_mac.h>
@@ -147,28 +194,21 @@ enum
 
 typedef SHA_CTX GO_SHA_CTX;
 
+// The low-level SHA functions implement EVP_md5_sha1 on OpenSSL 1.0.2
+// (see openssl_port_evp_md5_sha1.c) and keep a serializable copy of the
+// digest state where EVP_MD_CTX does not expose it (see evpHash in
+// sha.go). Everything else goes through the EVP_MD API.
 DEFINEFUNC(int, SHA1_Init, (GO_SHA_CTX * arg0), (arg0))
 DEFINEFUNC(int, SHA1_Update, (GO_SHA_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
 DEFINEFUNC(int, SHA1_Final, (uint8_t * arg0, GO_SHA_CTX *arg1), (arg0, arg1))
-
-typedef SHA256_CTX GO_SHA256_CTX;
-
-DEFINEFUNC(int, SHA224_Init, (GO_SHA256_CTX * arg0), (arg0))
-DEFINEFUNC(int, SHA224_Update, (GO_SHA256_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
-DEFINEFUNC(int, SHA224_Final, (uint8_t * arg0, GO_SHA256_CTX *arg1), (arg0, arg1))
-
-DEFINEFUNC(int, SHA256_Init, (GO_SHA256_CTX * arg0), (arg0))
-DEFINEFUNC(int, SHA256_Update, (GO_SHA256_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
-DEFINEFUNC(int, SHA256_Final, (uint8_t * arg0, GO_SHA256_CTX *arg1), (arg0, arg1))
-
-typedef SHA512_CTX GO_SHA512_CTX;
-DEFINEFUNC(int, SHA384_Init, (GO_SHA512_CTX * arg0), (arg0))
-DEFINEFUNC(int, SHA384_Update, (GO_SHA512_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
-DEFINEFUNC(int, SHA384_Final, (uint8_t * arg0, GO_SHA512_CTX *arg1), (arg0, arg1))
-
-DEFINEFUNC(int, SHA512_Init, (GO_SHA512_CTX * arg0), (arg0))
-DEFINEFUNC(int, SHA512_Update, (GO_SHA512_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
-DEFINEFUNC(int, SHA512_Final, (uint8_t * arg0, GO_SHA512_CTX *arg1), (arg0, arg1))
+DEFINEFUNC(int, SHA224_Init, (SHA256_CTX * arg0), (arg0))
+DEFINEFUNC(int, SHA256_Init, (SHA256_CTX * arg0), (arg0))
+DEFINEFUNC(int, SHA256_Update, (SHA256_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
+DEFINEFUNC(int, SHA256_Final, (uint8_t * arg0, SHA256_CTX *arg1), (arg0, arg1))
+DEFINEFUNC(int, SHA384_Init, (SHA512_CTX * arg0), (arg0))
+DEFINEFUNC(int, SHA512_Init, (SHA512_CTX * arg0), (arg0))
+DEFINEFUNC(int, SHA512_Update, (SHA512_CTX * arg0, const void *arg1, size_t arg2), (arg0, arg1, arg2))
+DEFINEFUNC(int, SHA512_Final, (uint8_t * arg0, SHA512_CTX *arg1), (arg0, arg1))
 
 #include <openssl/evp.h>
 
@@ -180,9 +220,110 @@ DEFINEFUNC(const GO_EVP_MD *, EVP_sha224, (void), ())
 DEFINEFUNC(const GO_EVP_MD *, EVP_sha256, (void), ())
 DEFINEFUNC(const GO_EVP_MD *, EVP_sha384, (void), ())
 DEFINEFUNC(const GO_EVP_MD *, EVP_sha512, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD*, EVP_md5_sha1, (void), ())
+
+// OpenSSL 3.0 renamed the EVP_MD accessors and kept the old names only
+// as macros, which dlsym cannot find.
+#if OPENSSL_VERSION_NUMBER < 0x30000000L
 DEFINEFUNC(int, EVP_MD_type, (const GO_EVP_MD *arg0), (arg0))
 DEFINEFUNCINTERNAL(size_t, EVP_MD_size, (const GO_EVP_MD *arg0), (arg0))
-DEFINEFUNCINTERNAL(const GO_EVP_MD*, EVP_md5_sha1, (void), ())
+DEFINEFUNC(int, EVP_MD_block_size, (const GO_EVP_MD *arg0), (arg0))
+#else
+DEFINEFUNCINTERNAL(int, EVP_MD_get_type, (const GO_EVP_MD *arg0), (arg0))
+DEFINEFUNCINTERNAL(int, EVP_MD_get_size, (const GO_EVP_MD *arg0), (arg0))
+DEFINEFUNCINTERNAL(int, EVP_MD_get_block_size, (const GO_EVP_MD *arg0), (arg0))
+static inline int
+_goboringcrypto_EVP_MD_type(const GO_EVP_MD *arg0) {
+	return _goboringcrypto_internal_EVP_MD_get_type(arg0);
+}
+static inline size_t
+_goboringcrypto_internal_EVP_MD_size(const GO_EVP_MD *arg0) {
+	return _goboringcrypto_internal_EVP_MD_get_size(arg0);
+}
+static inline int
+_goboringcrypto_EVP_MD_block_size(const GO_EVP_MD *arg0) {
+	return _goboringcrypto_internal_EVP_MD_get_block_size(arg0);
+}
+#endif
+
+// SHA-3 and SHAKE are only available starting with OpenSSL 1.1.1.
+// Return NULL on older versions so callers can fall back to Go.
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_sha3_224, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_sha3_256, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_sha3_384, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_sha3_512, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_shake128, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_shake256, (void), ())
+
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_sha3_224(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_sha3_224();
+#endif
+}
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_sha3_256(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_sha3_256();
+#endif
+}
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_sha3_384(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_sha3_384();
+#endif
+}
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_sha3_512(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_sha3_512();
+#endif
+}
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_shake128(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_shake128();
+#endif
+}
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_shake256(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_shake256();
+#endif
+}
+
+// SHA-512/224 and SHA-512/256 are only available starting with OpenSSL 1.1.1.
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_sha512_224, (void), ())
+DEFINEFUNCINTERNAL(const GO_EVP_MD *, EVP_sha512_256, (void), ())
+
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_sha512_224(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_sha512_224();
+#endif
+}
+static inline const GO_EVP_MD *
+_goboringcrypto_EVP_sha512_256(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	return _goboringcrypto_internal_EVP_sha512_256();
+#endif
+}
 
 # include <openssl/md5.h>
 DEFINEFUNCINTERNAL(int, MD5_Init, (MD5_CTX *c), (c))
@@ -250,7 +391,7 @@ _goboringcrypto_HMAC_CTX_new(void) {
 #endif
 }
 
-DEFINEFUNCINTERNAL(void, HMAC_CTX_reset, (GO_HMAC_CTX * arg0), (arg0))
+DEFINEFUNCINTERNAL(int, HMAC_CTX_reset, (GO_HMAC_CTX * arg0), (arg0))
 static inline void
 _goboringcrypto_HMAC_CTX_reset(GO_HMAC_CTX* ctx) {
 #if OPENSSL_VERSION_NUMBER < 0x10100000L
@@ -273,6 +414,13 @@ DEFINEFUNC(int, EVP_CipherInit_ex,
 DEFINEFUNC(int, EVP_CipherUpdate,
 		   (EVP_CIPHER_CTX * ctx, unsigned char *out, int *outl, const unsigned char *in, int inl),
 		   (ctx, out, outl, in, inl))
+DEFINEFUNC(int, EVP_CIPHER_CTX_set_padding, (EVP_CIPHER_CTX *x, int padding), (x, padding))
+DEFINEFUNC(void, EVP_CIPHER_CTX_set_flags, (EVP_CIPHER_CTX *ctx, int flags), (ctx, flags))
+
+enum
+{
+	GO_EVP_CIPHER_CTX_FLAG_WRAP_ALLOW = EVP_CIPHER_CTX_FLAG_WRAP_ALLOW
+};
 
 void _goboringcrypto_EVP_AES_ctr128_enc(EVP_CIPHER_CTX *ctx, const uint8_t *in, uint8_t *out, size_t len);
 
@@ -344,8 +492,10 @@ DEFINEFUNC(int, EC_KEY_set_private_key, (GO_EC_KEY * arg0, const GO_BIGNUM *arg1
 DEFINEFUNC(int, EC_KEY_set_public_key, (GO_EC_KEY * arg0, const GO_EC_POINT *arg1), (arg0, arg1))
 DEFINEFUNC(const GO_BIGNUM *, EC_KEY_get0_private_key, (const GO_EC_KEY *arg0), (arg0))
 DEFINEFUNC(const GO_EC_POINT *, EC_KEY_get0_public_key, (const GO_EC_KEY *arg0), (arg0))
+DEFINEFUNC(int, EC_GROUP_get_curve_name, (const GO_EC_GROUP *arg0), (arg0))
+DEFINEFUNC(int, EC_GROUP_get_degree, (const GO_EC_GROUP *arg0), (arg0))
 
-// TODO: EC_KEY_check_fips?
+DEFINEFUNC(int, EC_KEY_check_key, (const GO_EC_KEY *arg0), (arg0))
 
 #include <openssl/ecdsa.h>
 
@@ -357,6 +507,39 @@ DEFINEFUNC(GO_ECDSA_SIG *, ECDSA_do_sign, (const uint8_t *arg0, size_t arg1, con
 DEFINEFUNC(int, ECDSA_do_verify, (const uint8_t *arg0, size_t arg1, const GO_ECDSA_SIG *arg2, const GO_EC_KEY *arg3), (arg0, arg1, arg2, arg3))
 DEFINEFUNC(size_t, ECDSA_size, (const GO_EC_KEY *arg0), (arg0))
 
+DEFINEFUNCINTERNAL(void, ECDSA_SIG_get0,
+		   (const GO_ECDSA_SIG *sig, const GO_BIGNUM **pr, const GO_BIGNUM **ps),
+		   (sig, pr, ps))
+static inline void
+_goboringcrypto_ECDSA_SIG_get0(const GO_ECDSA_SIG *sig, const GO_BIGNUM **pr, const GO_BIGNUM **ps) {
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+	if (pr)
+		*pr = sig->r;
+	if (ps)
+		*ps = sig->s;
+#else
+	_goboringcrypto_internal_ECDSA_SIG_get0(sig, pr, ps);
+#endif
+}
+
+DEFINEFUNCINTERNAL(int, ECDSA_SIG_set0,
+		   (GO_ECDSA_SIG *sig, GO_BIGNUM *r, GO_BIGNUM *s),
+		   (sig, r, s))
+static inline int
+_goboringcrypto_ECDSA_SIG_set0(GO_ECDSA_SIG *sig, GO_BIGNUM *r, GO_BIGNUM *s) {
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+	if (r == NULL || s == NULL)
+		return 0;
+	_goboringcrypto_BN_clear_free(sig->r);
+	_goboringcrypto_BN_clear_free(sig->s);
+	sig->r = r;
+	sig->s = s;
+	return 1;
+#else
+	return _goboringcrypto_internal_ECDSA_SIG_set0(sig, r, s);
+#endif
+}
+
 DEFINEFUNCINTERNAL(int, ECDSA_sign, 
 	(int type, const unsigned char *dgst, size_t dgstlen, unsigned char *sig, unsigned int *siglen, EC_KEY *eckey),
 	(type, dgst, dgstlen, sig, siglen, eckey))
@@ -398,14 +581,14 @@ DEFINEFUNC(int, EVP_DigestUpdate,
 	(EVP_MD_CTX* ctx, const void *d, size_t cnt),
 	(ctx, d, cnt))
 DEFINEFUNC(int, EVP_DigestSignFinal,
-	(EVP_MD_CTX* ctx, unsigned char *sig, unsigned int *siglen),
+	(EVP_MD_CTX* ctx, unsigned char *sig, size_t *siglen),
 	(ctx, sig, siglen))
 
 DEFINEFUNC(int, EVP_DigestVerifyInit,
 	(EVP_MD_CTX* ctx, EVP_PKEY_CTX **pctx, const EVP_MD *type, ENGINE *e, const EVP_PKEY *pkey),
 	(ctx, pctx, type, e, pkey))
 DEFINEFUNC(int, EVP_DigestVerifyFinal,
-	(EVP_MD_CTX* ctx, const uint8_t *sig, unsigned int siglen),
+	(EVP_MD_CTX* ctx, const uint8_t *sig, size_t siglen),
 	(ctx, sig, siglen))
 
 int _goboringcrypto_EVP_sign(EVP_MD* md, EVP_PKEY_CTX *ctx, const uint8_t *msg, size_t msgLen, uint8_t *sig, unsigned int *slen, EVP_PKEY *eckey);
@@ -421,6 +604,49 @@ static inline void _goboringcrypto_EVP_MD_CTX_free(EVP_MD_CTX *ctx) {
 #endif
 }
 
+typedef EVP_MD_CTX GO_EVP_MD_CTX;
+
+DEFINEFUNC(int, EVP_DigestInit_ex,
+	(GO_EVP_MD_CTX *ctx, const GO_EVP_MD *type, ENGINE *impl),
+	(ctx, type, impl))
+DEFINEFUNC(int, EVP_DigestFinal_ex,
+	(GO_EVP_MD_CTX *ctx, uint8_t *md, unsigned int *s),
+	(ctx, md, s))
+DEFINEFUNC(int, EVP_MD_CTX_copy_ex,
+	(GO_EVP_MD_CTX *out, const GO_EVP_MD_CTX *in),
+	(out, in))
+
+// _goboringcrypto_EVP_MD_CTX_md_data returns the internal state of
+// the digest running in ctx: a SHA_CTX, SHA256_CTX or SHA512_CTX for
+// the SHA-1 and SHA-2 families. The provider digests of OpenSSL 3 keep
+// their state private, with no accessor, so it returns NULL there and
+// evpHash keeps its own copy of the state.
+#if OPENSSL_VERSION_NUMBER >= 0x10100000L && OPENSSL_VERSION_NUMBER < 0x30000000L
+DEFINEFUNCINTERNAL(void *, EVP_MD_CTX_md_data, (const GO_EVP_MD_CTX *ctx), (ctx))
+#endif
+static inline void *
+_goboringcrypto_EVP_MD_CTX_md_data(const GO_EVP_MD_CTX *ctx) {
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+	return ctx->md_data;
+#elif OPENSSL_VERSION_NUMBER < 0x30000000L
+	return _goboringcrypto_internal_EVP_MD_CTX_md_data(ctx);
+#else
+	return NULL;
+#endif
+}
+
+DEFINEFUNCINTERNAL(int, EVP_DigestFinalXOF,
+	(GO_EVP_MD_CTX *ctx, uint8_t *md, size_t len),
+	(ctx, md, len))
+static inline int
+_goboringcrypto_EVP_DigestFinalXOF(GO_EVP_MD_CTX *ctx, uint8_t *md, size_t len) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return 0;
+#else
+	return _goboringcrypto_internal_EVP_DigestFinalXOF(ctx, md, len);
+#endif
+}
+
 int _goboringcrypto_ECDSA_sign(EVP_MD *md, const uint8_t *arg1, size_t arg2, uint8_t *arg3, unsigned int *arg4, GO_EC_KEY *arg5);
 int _goboringcrypto_ECDSA_verify(EVP_MD *md, const uint8_t *arg1, size_t arg2, const uint8_t *arg3, unsigned int arg4, GO_EC_KEY *arg5);
 
@@ -645,6 +871,17 @@ DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_cbc, (void), ())
 DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_ctr, (void), ())
 DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_ecb, (void), ())
 DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_gcm, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_128_wrap, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_192_wrap, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_wrap, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_128_wrap_pad, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_192_wrap_pad, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_wrap_pad, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_128_xts, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_xts, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_128_ccm, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_192_ccm, (void), ())
+DEFINEFUNC(const EVP_CIPHER*, EVP_aes_256_ccm, (void), ())
 
 DEFINEFUNC(void, EVP_CIPHER_CTX_free, (EVP_CIPHER_CTX* arg0), (arg0))
 DEFINEFUNC(int, EVP_CIPHER_CTX_ctrl, (EVP_CIPHER_CTX *ctx, int type, int arg, void *ptr), (ctx, type, arg, ptr))
@@ -662,11 +899,80 @@ int _goboringcrypto_EVP_CIPHER_CTX_open(
 	uint8_t *nonce, int nonce_len,
 	uint8_t *plaintext, size_t *plaintext_len);
 
+int _goboringcrypto_EVP_CIPHER_CTX_seal_ccm(
+	uint8_t *out, uint8_t *nonce, int nonce_len,
+	uint8_t *aad, int aad_len,
+	uint8_t *plaintext, int plaintext_len,
+	int tag_len, uint8_t *key, int key_size);
+
+int _goboringcrypto_EVP_CIPHER_CTX_open_ccm(
+	uint8_t *ciphertext, int ciphertext_len,
+	uint8_t *aad, int aad_len,
+	uint8_t *tag, int tag_len,
+	uint8_t *nonce, int nonce_len,
+	uint8_t *key, int key_size,
+	uint8_t *plaintext);
+
+#include <openssl/cmac.h>
+
+typedef CMAC_CTX GO_CMAC_CTX;
+
+DEFINEFUNC(GO_CMAC_CTX *, CMAC_CTX_new, (void), ())
+DEFINEFUNC(void, CMAC_CTX_free, (GO_CMAC_CTX *ctx), (ctx))
+DEFINEFUNC(int, CMAC_CTX_copy, (GO_CMAC_CTX *out, const GO_CMAC_CTX *in), (out, in))
+DEFINEFUNC(int, CMAC_Init,
+	(GO_CMAC_CTX *ctx, const void *key, size_t keylen, const EVP_CIPHER *cipher, ENGINE *impl),
+	(ctx, key, keylen, cipher, impl))
+DEFINEFUNC(int, CMAC_Update, (GO_CMAC_CTX *ctx, const void *data, size_t dlen), (ctx, data, dlen))
+DEFINEFUNC(int, CMAC_Final, (GO_CMAC_CTX *ctx, unsigned char *out, size_t *poutlen), (ctx, out, poutlen))
+
 typedef EVP_PKEY GO_EVP_PKEY;
 
 DEFINEFUNC(GO_EVP_PKEY *, EVP_PKEY_new, (void), ())
 DEFINEFUNC(void, EVP_PKEY_free, (GO_EVP_PKEY * arg0), (arg0))
 DEFINEFUNC(int, EVP_PKEY_set1_RSA, (GO_EVP_PKEY * arg0, GO_RSA *arg1), (arg0, arg1))
+DEFINEFUNC(int, EVP_PKEY_set1_EC_KEY, (GO_EVP_PKEY * arg0, GO_EC_KEY *arg1), (arg0, arg1))
+DEFINEFUNC(GO_RSA *, EVP_PKEY_get1_RSA, (GO_EVP_PKEY * arg0), (arg0))
+DEFINEFUNC(GO_EC_KEY *, EVP_PKEY_get1_EC_KEY, (GO_EVP_PKEY * arg0), (arg0))
+DEFINEFUNCINTERNAL(GO_EVP_PKEY *, d2i_AutoPrivateKey, (GO_EVP_PKEY **arg0, const unsigned char **arg1, long arg2), (arg0, arg1, arg2))
+
+// d2i_AutoPrivateKey advances its input pointer, which Go cannot pass
+// by address.
+static inline GO_EVP_PKEY *
+_goboringcrypto_d2i_AutoPrivateKey(const unsigned char *der, long len) {
+	return _goboringcrypto_internal_d2i_AutoPrivateKey(NULL, &der, len);
+}
+
+DEFINEFUNC(int, i2d_PUBKEY, (GO_EVP_PKEY *a, unsigned char **pp), (a, pp))
+DEFINEFUNCINTERNAL(GO_EVP_PKEY *, d2i_PUBKEY, (GO_EVP_PKEY **a, const unsigned char **pp, long length), (a, pp, length))
+
+static inline GO_EVP_PKEY *
+_goboringcrypto_d2i_PUBKEY(const unsigned char *der, long len) {
+	return _goboringcrypto_internal_d2i_PUBKEY(NULL, &der, len);
+}
+
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+DEFINEFUNCINTERNAL(int, CRYPTO_add_lock,
+	(int *pointer, int amount, int type, const char *file, int line),
+	(pointer, amount, type, file, line))
+#else
+DEFINEFUNCINTERNAL(int, EVP_PKEY_up_ref, (GO_EVP_PKEY *pkey), (pkey))
+#endif
+
+static inline int
+_goboringcrypto_EVP_PKEY_up_ref(GO_EVP_PKEY *pkey) {
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+	_goboringcrypto_internal_CRYPTO_add_lock(&pkey->references, 1, CRYPTO_LOCK_EVP_PKEY, __FILE__, __LINE__);
+	return 1;
+#else
+	return _goboringcrypto_internal_EVP_PKEY_up_ref(pkey);
+#endif
+}
+
+// _goboringcrypto_EVP_PKEY_dup_public returns a new EVP_PKEY with only
+// the public half of pkey, re-encoded so that it works even if pkey
+// cannot export its private half. It returns NULL on failure.
+GO_EVP_PKEY *_goboringcrypto_EVP_PKEY_dup_public(GO_EVP_PKEY *pkey);
 DEFINEFUNC(int, EVP_PKEY_verify,
 	(EVP_PKEY_CTX *ctx, const unsigned char *sig, unsigned int siglen, const unsigned char *tbs, size_t tbslen),
 	(ctx, sig, siglen, tbs, tbslen))
@@ -707,7 +1013,7 @@ _goboringcrypto_EVP_PKEY_CTX_set_rsa_oaep_md(GO_EVP_PKEY_CTX *ctx, const GO_EVP_
 static inline int
 _goboringcrypto_EVP_PKEY_CTX_set_rsa_pss_saltlen(GO_EVP_PKEY_CTX * arg0, int arg1) {
 	return _goboringcrypto_EVP_PKEY_CTX_ctrl(arg0, EVP_PKEY_RSA, 
-		(EVP_PKEY_OP_SIGN|EVP_PKEY_OP_VERIFY), 
+		EVP_PKEY_OP_TYPE_SIG,
 		EVP_PKEY_CTRL_RSA_PSS_SALTLEN, 
 		arg1, NULL);
 }
@@ -736,3 +1042,129 @@ DEFINEFUNC(int, EVP_PKEY_verify_init, (GO_EVP_PKEY_CTX * arg0), (arg0))
 DEFINEFUNC(int, EVP_PKEY_sign,
 		   (GO_EVP_PKEY_CTX * arg0, uint8_t *arg1, size_t *arg2, const uint8_t *arg3, size_t arg4),
 		   (arg0, arg1, arg2, arg3, arg4))
+
+// The SP 800-108 and SP 800-56C KDFs are only available through the
+// EVP_KDF API of OpenSSL 3.0 and later. _goboringcrypto_KDF_supported
+// reports whether the library in use has it, so callers can fall back
+// to Go on older versions.
+#if OPENSSL_VERSION_NUMBER >= 0x30000000L
+#include <openssl/kdf.h>
+#include <openssl/core_names.h>
+#include <openssl/params.h>
+
+DEFINEFUNC(EVP_KDF *, EVP_KDF_fetch, (OSSL_LIB_CTX *libctx, const char *algorithm, const char *properties), (libctx, algorithm, properties))
+DEFINEFUNC(void, EVP_KDF_free, (EVP_KDF *kdf), (kdf))
+DEFINEFUNC(EVP_KDF_CTX *, EVP_KDF_CTX_new, (EVP_KDF *kdf), (kdf))
+DEFINEFUNC(void, EVP_KDF_CTX_free, (EVP_KDF_CTX *ctx), (ctx))
+DEFINEFUNC(int, EVP_KDF_derive, (EVP_KDF_CTX *ctx, unsigned char *key, size_t keylen, const OSSL_PARAM params[]), (ctx, key, keylen, params))
+DEFINEFUNC(const char *, EVP_MD_get0_name, (const EVP_MD *md), (md))
+DEFINEFUNC(OSSL_PARAM, OSSL_PARAM_construct_utf8_string, (const char *key, char *buf, size_t bsize), (key, buf, bsize))
+DEFINEFUNC(OSSL_PARAM, OSSL_PARAM_construct_octet_string, (const char *key, void *buf, size_t bsize), (key, buf, bsize))
+DEFINEFUNC(OSSL_PARAM, OSSL_PARAM_construct_int, (const char *key, int *buf), (key, buf))
+DEFINEFUNC(OSSL_PARAM, OSSL_PARAM_construct_end, (void), ())
+#endif
+
+static inline int
+_goboringcrypto_KDF_supported(void) {
+#if OPENSSL_VERSION_NUMBER < 0x30000000L
+	return 0;
+#elif defined(GO_OPENSSL_STATIC)
+	return 1;
+#else
+	return dlsym(handle, "EVP_KDF_fetch") != NULL;
+#endif
+}
+
+int _goboringcrypto_KDF_derive(const char *kdf, const char *mac,
+	const GO_EVP_MD *md, int aes_key_size,
+	uint8_t *key, size_t key_len, uint8_t *salt, size_t salt_len,
+	uint8_t *info, size_t info_len, uint8_t *out, size_t out_len);
+
+// Private keys held outside the library, such as in an HSM, are loaded
+// through an ENGINE or, from OpenSSL 1.1.1 on, an OSSL_STORE loader.
+// _goboringcrypto_OSSL_STORE_supported reports whether the library in
+// use has OSSL_STORE.
+#ifndef OPENSSL_NO_ENGINE
+#include <openssl/engine.h>
+
+DEFINEFUNC(ENGINE *, ENGINE_by_id, (const char *id), (id))
+DEFINEFUNC(int, ENGINE_init, (ENGINE *e), (e))
+DEFINEFUNC(int, ENGINE_finish, (ENGINE *e), (e))
+DEFINEFUNC(int, ENGINE_free, (ENGINE *e), (e))
+DEFINEFUNC(GO_EVP_PKEY *, ENGINE_load_private_key,
+	(ENGINE *e, const char *key_id, UI_METHOD *ui_method, void *callback_data),
+	(e, key_id, ui_method, callback_data))
+#endif
+
+#if OPENSSL_VERSION_NUMBER >= 0x10101000L
+#include <openssl/store.h>
+
+DEFINEFUNC(OSSL_STORE_CTX *, OSSL_STORE_open,
+	(const char *uri, const UI_METHOD *ui_method, void *ui_data, OSSL_STORE_post_process_info_fn post_process, void *post_process_data),
+	(uri, ui_method, ui_data, post_process, post_process_data))
+DEFINEFUNC(int, OSSL_STORE_expect, (OSSL_STORE_CTX *ctx, int expected_type), (ctx, expected_type))
+DEFINEFUNC(OSSL_STORE_INFO *, OSSL_STORE_load, (OSSL_STORE_CTX *ctx), (ctx))
+DEFINEFUNC(int, OSSL_STORE_eof, (OSSL_STORE_CTX *ctx), (ctx))
+DEFINEFUNC(int, OSSL_STORE_error, (OSSL_STORE_CTX *ctx), (ctx))
+DEFINEFUNC(int, OSSL_STORE_close, (OSSL_STORE_CTX *ctx), (ctx))
+DEFINEFUNC(int, OSSL_STORE_INFO_get_type, (const OSSL_STORE_INFO *info), (info))
+DEFINEFUNC(GO_EVP_PKEY *, OSSL_STORE_INFO_get1_PKEY, (const OSSL_STORE_INFO *info), (info))
+DEFINEFUNC(void, OSSL_STORE_INFO_free, (OSSL_STORE_INFO *info), (info))
+#endif
+
+static inline int
+_goboringcrypto_OSSL_STORE_supported(void) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return 0;
+#elif defined(GO_OPENSSL_STATIC)
+	return 1;
+#else
+	return dlsym(handle, "OSSL_STORE_open") != NULL;
+#endif
+}
+
+GO_EVP_PKEY *_goboringcrypto_ENGINE_load_private_key_by_id(const char *engine_id, const char *key_id);
+GO_EVP_PKEY *_goboringcrypto_OSSL_STORE_load_private_key(const char *uri);
+
+// The MAC of a TLS CBC record must be checked in time independent of
+// the length of its padding, or it leaks the plaintext ("Lucky
+// Thirteen"). OpenSSL 3.0 does this in its HMAC when given the length of
+// the whole record; _goboringcrypto_EVP_MAC_supported reports whether the
+// library in use has it. Older versions use a port of the
+// ssl3_cbc_digest_record function of libssl, but only outside FIPS mode,
+// since it relies on the low-level SHA-1 and SHA-256 functions.
+#if OPENSSL_VERSION_NUMBER >= 0x30000000L
+DEFINEFUNC(EVP_MAC *, EVP_MAC_fetch, (OSSL_LIB_CTX *libctx, const char *algorithm, const char *properties), (libctx, algorithm, properties))
+DEFINEFUNC(void, EVP_MAC_free, (EVP_MAC *mac), (mac))
+DEFINEFUNC(EVP_MAC_CTX *, EVP_MAC_CTX_new, (EVP_MAC *mac), (mac))
+DEFINEFUNC(void, EVP_MAC_CTX_free, (EVP_MAC_CTX *ctx), (ctx))
+DEFINEFUNC(int, EVP_MAC_init,
+	(EVP_MAC_CTX *ctx, const unsigned char *key, size_t keylen, const OSSL_PARAM params[]),
+	(ctx, key, keylen, params))
+DEFINEFUNC(int, EVP_MAC_update, (EVP_MAC_CTX *ctx, const unsigned char *data, size_t datalen), (ctx, data, datalen))
+DEFINEFUNC(int, EVP_MAC_final,
+	(EVP_MAC_CTX *ctx, unsigned char *out, size_t *outl, size_t outsize),
+	(ctx, out, outl, outsize))
+DEFINEFUNC(OSSL_PARAM, OSSL_PARAM_construct_size_t, (const char *key, size_t *buf), (key, buf))
+#endif
+
+DEFINEFUNC(void, SHA1_Transform, (GO_SHA_CTX *c, const uint8_t *data), (c, data))
+DEFINEFUNC(void, SHA256_Transform, (SHA256_CTX *c, const uint8_t *data), (c, data))
+
+static inline int
+_goboringcrypto_EVP_MAC_supported(void) {
+#if OPENSSL_VERSION_NUMBER < 0x30000000L
+	return 0;
+#elif defined(GO_OPENSSL_STATIC)
+	return 1;
+#else
+	return dlsym(handle, "EVP_MAC_fetch") != NULL;
+#endif
+}
+
+int _goboringcrypto_EVP_MAC_TLS_CBC_HMAC(const GO_EVP_MD *md,
+	const uint8_t *key, size_t key_len, const uint8_t *header,
+	const uint8_t *data, size_t data_len, size_t record_len, uint8_t *out);
+int _goboringcrypto_ssl3_cbc_digest_record(const GO_EVP_MD *md,
+	const uint8_t *key, size_t key_len, const uint8_t *header,
+	const uint8_t *data, size_t data_len, size_t record_len, uint8_t *out);
@@ -22,17 +22,8 @@ import (
 // hashToMD converts a hash.Hash implementation from this package
 // to a BoringCrypto *C.GO_EVP_MD.
 func hashToMD(h hash.Hash) *C.GO_EVP_MD {
-	switch h.(type) {
-	case *sha1Hash:
-		return C._goboringcrypto_EVP_sha1()
-	case *sha224Hash:
-		return C._goboringcrypto_EVP_sha224()
-	case *sha256Hash:
-		return C._goboringcrypto_EVP_sha256()
-	case *sha384Hash:
-		return C._goboringcrypto_EVP_sha384()
-	case *sha512Hash:
-		return C._goboringcrypto_EVP_sha512()
+	if h, ok := h.(*evpHash); ok {
+		return h.md
 	}
 	return nil
 }
@@ -55,10 +46,28 @@ func cryptoHashToMD(ch crypto.Hash) *C.GO_EVP_MD {
 		return C._goboringcrypto_EVP_sha384()
 	case crypto.SHA512:
 		return C._goboringcrypto_EVP_sha512()
+	case crypto.SHA512_224:
+		return C._goboringcrypto_EVP_sha512_224()
+	case crypto.SHA512_256:
+		return C._goboringcrypto_EVP_sha512_256()
+	case crypto.SHA3_224:
+		return C._goboringcrypto_EVP_sha3_224()
+	case crypto.SHA3_256:
+		return C._goboringcrypto_EVP_sha3_256()
+	case crypto.SHA3_384:
+		return C._goboringcrypto_EVP_sha3_384()
+	case crypto.SHA3_512:
+		return C._goboringcrypto_EVP_sha3_512()
 	}
 	return nil
 }
 
+// SupportsHash reports whether the OpenSSL library in use
+// implements the hash function ch.
+func SupportsHash(ch crypto.Hash) bool {
+	return cryptoHashToMD(ch) != nil
+}
+
 // NewHMAC returns a new HMAC using BoringCrypto.
 // The function h must return a hash implemented by
 // BoringCrypto (for example, h could be boring.NewSHA256).
@@ -129,6 +138,9 @@ func (h *boringHMAC) Reset() {
 
 func (h *boringHMAC) finalize() {
 	C._goboringcrypto_HMAC_CTX_free(h.ctx)
+	if h.ctx2 != nil {
+		C._goboringcrypto_HMAC_CTX_free(h.ctx2)
+	}
 }
 
 func (h *boringHMAC) Write(p []byte) (int, error) {
@@ -156,11 +168,100 @@ func (h *boringHMAC) Sum(in []byte) []byte {
 	// that Sum has no effect on the underlying stream.
 	// In particular it is OK to Sum, then Write more, then Sum again,
 	// and the second Sum acts as if the first didn't happen.
-	h.ctx2 = C._goboringcrypto_HMAC_CTX_new()
+	// The copy is kept in h.ctx2 for the next Sum.
+	if h.ctx2 == nil {
+		h.ctx2 = C._goboringcrypto_HMAC_CTX_new()
+	}
 	if C._goboringcrypto_HMAC_CTX_copy_ex(h.ctx2, h.ctx) == 0 {
 		panic("boringcrypto: HMAC_CTX_copy_ex failed")
 	}
 	C._goboringcrypto_HMAC_Final(h.ctx2, (*C.uint8_t)(unsafe.Pointer(&h.sum[0])), nil)
-	C._goboringcrypto_HMAC_CTX_free(h.ctx2)
+	runtime.KeepAlive(h)
 	return append(in, h.sum...)
 }
+
+// Clone returns a new HMAC with the same key and running state as h,
+// copied with HMAC_CTX_copy, so that callers can fork an HMAC without
+// writing its input again.
+func (h *boringHMAC) Clone() hash.Hash {
+	c := &boringHMAC{
+		md:          h.md,
+		size:        h.size,
+		blockSize:   h.blockSize,
+		key:         h.key,
+		ctx:         C._goboringcrypto_HMAC_CTX_new(),
+		needCleanup: true,
+	}
+	runtime.SetFinalizer(c, (*boringHMAC).finalize)
+	if C._goboringcrypto_HMAC_CTX_copy_ex(c.ctx, h.ctx) == 0 {
+		panic("boringcrypto: HMAC_CTX_copy_ex failed")
+	}
+	runtime.KeepAlive(h)
+	runtime.KeepAlive(c)
+	return c
+}
+
+// ConstantTimeTLSSum appends to out the MAC of a received TLS CBC record:
+// the HMAC of the 13-byte header (sequence number and record header, with
+// the length set to n) followed by record[:n]. record is the whole
+// decrypted record, so record[n:] is the MAC followed by at most 256
+// bytes of padding. All of it is hashed so that the time taken does not
+// depend on the secret n. The state of h is neither used nor changed.
+//
+// ConstantTimeTLSSum returns nil unless h uses SHA-1 or SHA-256, the
+// hashes of the TLS CBC cipher suites, and record is long enough to hold
+// the MAC and at least one byte of padding. It also returns nil if
+// SupportsConstantTimeTLSMAC reports false.
+func (h *boringHMAC) ConstantTimeTLSSum(out, header, record []byte, n int) []byte {
+	if C._goboringcrypto_EVP_MAC_supported() == 1 {
+		return h.tlsSum(out, header, record, n, true)
+	}
+	if !portTLSSumAllowed() {
+		return nil
+	}
+	return h.tlsSum(out, header, record, n, false)
+}
+
+// SupportsConstantTimeTLSMAC reports whether the HMAC returned by NewHMAC
+// implements ConstantTimeTLSSum in the current mode. It does not when the
+// library has no provider HMAC and is in FIPS mode, and crypto/tls then
+// does not negotiate the CBC cipher suites.
+func SupportsConstantTimeTLSMAC() bool {
+	return C._goboringcrypto_EVP_MAC_supported() == 1 || portTLSSumAllowed()
+}
+
+// portTLSSumAllowed reports whether the port of ssl3_cbc_digest_record
+// may be used. It calls the low-level SHA-1 and SHA-256 functions, which
+// are not an approved interface of the FIPS module and which the 1.0.2
+// FIPS module refuses in FIPS mode.
+func portTLSSumAllowed() bool {
+	return C._goboringcrypto_FIPS_mode() != fipsOn
+}
+
+// tlsSum implements ConstantTimeTLSSum, with the provider HMAC of
+// OpenSSL 3.0 if useEVPMAC is set and the port of ssl3_cbc_digest_record
+// otherwise.
+func (h *boringHMAC) tlsSum(out, header, record []byte, n int, useEVPMAC bool) []byte {
+	if h.md != C._goboringcrypto_EVP_sha1() && h.md != C._goboringcrypto_EVP_sha256() {
+		return nil
+	}
+	if len(h.key) > h.blockSize || len(record) <= h.size {
+		return nil
+	}
+	if len(header) != 13 {
+		panic("boringcrypto: invalid TLS record header length")
+	}
+	sum := make([]byte, C.EVP_MAX_MD_SIZE)
+	var ok C.int
+	if useEVPMAC {
+		ok = C._goboringcrypto_EVP_MAC_TLS_CBC_HMAC(h.md, base(h.key), C.size_t(len(h.key)),
+			base(header), base(record), C.size_t(n), C.size_t(len(record)), base(sum))
+	} else {
+		ok = C._goboringcrypto_ssl3_cbc_digest_record(h.md, base(h.key), C.size_t(len(h.key)),
+			base(header), base(record), C.size_t(n), C.size_t(len(record)), base(sum))
+	}
+	if ok == 0 {
+		panic("boringcrypto: TLS CBC record MAC failed")
+	}
+	return append(out, sum[:h.size]...)
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+import (
+	"bytes"
+	"crypto"
+	"hash"
+	"testing"
+)
+
+func TestConstantTimeTLSSum(t *testing.T) {
+	if !Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	record := make([]byte, 600)
+	for i := range record {
+		record[i] = byte(i)
+	}
+	for _, newHash := range []func() hash.Hash{NewSHA1, NewSHA256} {
+		size := newHash().Size()
+		key := bytes.Repeat([]byte{0x42}, size)
+		h := NewHMAC(newHash, key).(*boringHMAC)
+		for _, recordLen := range []int{size + 1, 64, 100, 300, 600} {
+			// The padding of a record is at most 256 bytes long.
+			n := recordLen - size - 256
+			if n < 0 {
+				n = 0
+			}
+			for ; n+size <= recordLen; n++ {
+				header := []byte{0, 0, 0, 0, 0, 0, 0, 1, 23, 3, 3, byte(n >> 8), byte(n)}
+				ref := NewHMAC(newHash, key)
+				ref.Write(header)
+				ref.Write(record[:n])
+				want := ref.Sum(nil)
+
+				// Without the provider HMAC, a library in FIPS mode
+				// has no constant-time MAC, and says so.
+				if got := h.ConstantTimeTLSSum(nil, header, record[:recordLen], n); !bytes.Equal(got, want) && (got != nil || SupportsConstantTimeTLSMAC()) {
+					t.Fatalf("size %d, record %d, n %d: ConstantTimeTLSSum = %x, want %x", size, recordLen, n, got, want)
+				}
+				if !portTLSSumAllowed() {
+					continue
+				}
+				if got := h.tlsSum(nil, header, record[:recordLen], n, false); !bytes.Equal(got, want) {
+					t.Fatalf("size %d, record %d, n %d: ssl3_cbc_digest_record = %x, want %x", size, recordLen, n, got, want)
+				}
+			}
+		}
+	}
+
+	h := NewHMAC(NewSHA384, nil).(*boringHMAC)
+	if got := h.ConstantTimeTLSSum(nil, make([]byte, 13), record, 0); got != nil {
+		t.Errorf("ConstantTimeTLSSum with SHA-384 = %x, want nil", got)
+	}
+	h = NewHMAC(NewSHA256, nil).(*boringHMAC)
+	if got := h.ConstantTimeTLSSum(nil, make([]byte, 13), record[:h.Size()], 0); got != nil {
+		t.Errorf("ConstantTimeTLSSum without padding = %x, want nil", got)
+	}
+}
+
+func TestClone(t *testing.T) {
+	if !Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	hashes := map[string]func() hash.Hash{
+		"SHA1":        NewSHA1,
+		"SHA256":      NewSHA256,
+		"SHA512":      NewSHA512,
+		"HMAC-SHA256": func() hash.Hash { return NewHMAC(NewSHA256, []byte("key")) },
+	}
+	if SupportsHash(crypto.SHA3_256) {
+		hashes["SHA3-256"] = NewSHA3_256
+	}
+	if SupportsSHAKE() {
+		hashes["SHAKE128"] = NewSHAKE128
+	}
+	for name, newHash := range hashes {
+		h := newHash()
+		h.Write([]byte("foo"))
+		c := h.(Cloner).Clone()
+		if got, want := c.Sum(nil), h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%s: clone Sum = %x, want %x", name, got, want)
+		}
+
+		// The clone and the original must evolve independently.
+		h.Write([]byte("bar"))
+		c.Write([]byte("baz"))
+		for _, test := range []struct {
+			h   hash.Hash
+			msg string
+		}{{h, "foobar"}, {c, "foobaz"}} {
+			want := newHash()
+			want.Write([]byte(test.msg))
+			if got := test.h.Sum(nil); !bytes.Equal(got, want.Sum(nil)) {
+				t.Errorf("%s: Sum after writing %q = %x, want %x", name, test.msg, got, want.Sum(nil))
+			}
+		}
+	}
+}
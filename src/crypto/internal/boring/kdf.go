@@ -0,0 +1,83 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"errors"
+	"hash"
+	"unsafe"
+)
+
+// SupportsKDF reports whether the OpenSSL library in use implements the
+// key derivation functions below with the hash h, or with AES-CMAC if h
+// is nil. The KDFs need the EVP_KDF API of OpenSSL 3.0.
+func SupportsKDF(h func() hash.Hash) bool {
+	if C._goboringcrypto_KDF_supported() != 1 {
+		return false
+	}
+	return h == nil || hashToMD(h()) != nil
+}
+
+// KBKDFCounterHMAC implements the SP 800-108 KDF in counter mode with
+// HMAC, a 32-bit counter before the fixed input data, and the given fixed
+// input data.
+func KBKDFCounterHMAC(h func() hash.Hash, key, fixedInput []byte, length int) ([]byte, error) {
+	return kdfDerive("KBKDF", "HMAC", hashToMD(h()), 0, key, fixedInput, nil, length)
+}
+
+// KBKDFCounterCMAC is like KBKDFCounterHMAC, with AES-CMAC keyed with key.
+func KBKDFCounterCMAC(key, fixedInput []byte, length int) ([]byte, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, errors.New("boringcrypto: invalid AES-CMAC key size")
+	}
+	return kdfDerive("KBKDF", "CMAC", nil, len(key)*8, key, fixedInput, nil, length)
+}
+
+// SSKDFHash implements the SP 800-56C one-step KDF with the hash h.
+func SSKDFHash(h func() hash.Hash, secret, fixedInfo []byte, length int) ([]byte, error) {
+	return kdfDerive("SSKDF", "", hashToMD(h()), 0, secret, nil, fixedInfo, length)
+}
+
+// SSKDFHMAC implements the SP 800-56C one-step KDF with HMAC keyed with
+// salt. An empty salt selects the module's default salt.
+func SSKDFHMAC(h func() hash.Hash, secret, salt, fixedInfo []byte, length int) ([]byte, error) {
+	return kdfDerive("SSKDF", "HMAC", hashToMD(h()), 0, secret, salt, fixedInfo, length)
+}
+
+// X963KDF implements the ANSI X9.63 KDF with the hash h.
+func X963KDF(h func() hash.Hash, secret, sharedInfo []byte, length int) ([]byte, error) {
+	return kdfDerive("X963KDF", "", hashToMD(h()), 0, secret, nil, sharedInfo, length)
+}
+
+func kdfDerive(kdf, mac string, md *C.GO_EVP_MD, aesKeySize int, key, salt, info []byte, length int) ([]byte, error) {
+	if md == nil && aesKeySize == 0 {
+		return nil, errors.New("boringcrypto: unsupported KDF hash")
+	}
+	ckdf := C.CString(kdf)
+	defer C.free(unsafe.Pointer(ckdf))
+	var cmac *C.char
+	if mac != "" {
+		cmac = C.CString(mac)
+		defer C.free(unsafe.Pointer(cmac))
+	}
+
+	out := make([]byte, length)
+	if C._goboringcrypto_KDF_derive(ckdf, cmac, md, C.int(aesKeySize),
+		base(key), C.size_t(len(key)), base(salt), C.size_t(len(salt)),
+		base(info), C.size_t(len(info)), base(out), C.size_t(len(out))) != 1 {
+		return nil, NewOpenSSLError(kdf + " derivation failed")
+	}
+	return out, nil
+}
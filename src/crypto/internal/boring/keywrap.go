@@ -0,0 +1,97 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// The key wrap modes below implement SP 800-38F KW (RFC 3394) and KWP
+// (RFC 5649) with the module's EVP_aes_*_wrap and EVP_aes_*_wrap_pad
+// ciphers. Callers are expected to have checked the input lengths.
+
+var errKeyWrap = errors.New("boringcrypto: key unwrap failed")
+
+// Wrap wraps plaintext with AES-KW.
+func (c *aesCipher) Wrap(plaintext []byte) ([]byte, error) {
+	return c.keyWrap(plaintext, false, true, len(plaintext)+8)
+}
+
+// Unwrap unwraps ciphertext with AES-KW.
+func (c *aesCipher) Unwrap(ciphertext []byte) ([]byte, error) {
+	return c.keyWrap(ciphertext, false, false, len(ciphertext)-8)
+}
+
+// WrapPad wraps plaintext with AES-KWP.
+func (c *aesCipher) WrapPad(plaintext []byte) ([]byte, error) {
+	return c.keyWrap(plaintext, true, true, (len(plaintext)+7)/8*8+8)
+}
+
+// UnwrapPad unwraps ciphertext with AES-KWP.
+func (c *aesCipher) UnwrapPad(ciphertext []byte) ([]byte, error) {
+	return c.keyWrap(ciphertext, true, false, len(ciphertext)-8)
+}
+
+func (c *aesCipher) keyWrap(in []byte, pad, encrypt bool, outLen int) ([]byte, error) {
+	var cipher *C.EVP_CIPHER
+	switch len(c.key) * 8 {
+	case 128:
+		cipher = C._goboringcrypto_EVP_aes_128_wrap()
+		if pad {
+			cipher = C._goboringcrypto_EVP_aes_128_wrap_pad()
+		}
+	case 192:
+		cipher = C._goboringcrypto_EVP_aes_192_wrap()
+		if pad {
+			cipher = C._goboringcrypto_EVP_aes_192_wrap_pad()
+		}
+	case 256:
+		cipher = C._goboringcrypto_EVP_aes_256_wrap()
+		if pad {
+			cipher = C._goboringcrypto_EVP_aes_256_wrap_pad()
+		}
+	}
+
+	ctx := C._goboringcrypto_EVP_CIPHER_CTX_new()
+	if ctx == nil {
+		return nil, NewOpenSSLError("EVP_CIPHER_CTX_new failed")
+	}
+	defer C._goboringcrypto_EVP_CIPHER_CTX_free(ctx)
+	C._goboringcrypto_EVP_CIPHER_CTX_set_flags(ctx, C.GO_EVP_CIPHER_CTX_FLAG_WRAP_ALLOW)
+
+	mode := C.int(C.GO_AES_DECRYPT)
+	if encrypt {
+		mode = C.GO_AES_ENCRYPT
+	}
+	if C._goboringcrypto_EVP_CipherInit_ex(ctx, cipher, nil, (*C.uchar)(unsafe.Pointer(base(c.key))), nil, mode) != 1 {
+		return nil, NewOpenSSLError("EVP_CipherInit_ex failed")
+	}
+
+	// EVP_CipherUpdate assumes room for the input plus a block of output,
+	// whatever the mode actually produces.
+	out := make([]byte, len(in)+2*aesBlockSize)
+	var n C.int
+	if C._goboringcrypto_EVP_CipherUpdate(ctx, (*C.uchar)(unsafe.Pointer(base(out))), &n, (*C.uchar)(unsafe.Pointer(base(in))), C.int(len(in))) <= 0 {
+		if !encrypt {
+			return nil, errKeyWrap
+		}
+		return nil, NewOpenSSLError("EVP_CipherUpdate failed")
+	}
+	runtime.KeepAlive(c)
+	if int(n) > outLen {
+		panic("boringcrypto: internal confusion about key wrap output length")
+	}
+	return out[:n], nil
+}
@@ -44,7 +44,48 @@ func NewSHA256() hash.Hash { panic("boringcrypto: not available") }
 func NewSHA384() hash.Hash { panic("boringcrypto: not available") }
 func NewSHA512() hash.Hash { panic("boringcrypto: not available") }
 
+func NewSHA512_224() hash.Hash { panic("boringcrypto: not available") }
+func NewSHA512_256() hash.Hash { panic("boringcrypto: not available") }
+
+func NewSHA3_224() hash.Hash { panic("boringcrypto: not available") }
+func NewSHA3_256() hash.Hash { panic("boringcrypto: not available") }
+func NewSHA3_384() hash.Hash { panic("boringcrypto: not available") }
+func NewSHA3_512() hash.Hash { panic("boringcrypto: not available") }
+func NewSHAKE128() hash.Hash { panic("boringcrypto: not available") }
+func NewSHAKE256() hash.Hash { panic("boringcrypto: not available") }
+
+func SupportsHash(h crypto.Hash) bool { return false }
+func SupportsSHAKE() bool             { return false }
+
 func NewHMAC(h func() hash.Hash, key []byte) hash.Hash { panic("boringcrypto: not available") }
+func SupportsConstantTimeTLSMAC() bool                 { return false }
+
+func SupportsKDF(h func() hash.Hash) bool { return false }
+func KBKDFCounterHMAC(h func() hash.Hash, key, fixedInput []byte, length int) ([]byte, error) {
+	panic("boringcrypto: not available")
+}
+func KBKDFCounterCMAC(key, fixedInput []byte, length int) ([]byte, error) {
+	panic("boringcrypto: not available")
+}
+func SSKDFHash(h func() hash.Hash, secret, fixedInfo []byte, length int) ([]byte, error) {
+	panic("boringcrypto: not available")
+}
+func SSKDFHMAC(h func() hash.Hash, secret, salt, fixedInfo []byte, length int) ([]byte, error) {
+	panic("boringcrypto: not available")
+}
+func X963KDF(h func() hash.Hash, secret, sharedInfo []byte, length int) ([]byte, error) {
+	panic("boringcrypto: not available")
+}
+
+type TestDRBG struct{ _ int }
+
+func NewTestDRBG(entropy, nonce, personalization []byte) *TestDRBG {
+	panic("boringcrypto: not available")
+}
+func (*TestDRBG) Reseed(entropy, additional []byte) { panic("boringcrypto: not available") }
+func (*TestDRBG) Generate(out, additional []byte)   { panic("boringcrypto: not available") }
+func (*TestDRBG) Read(b []byte) (int, error)        { panic("boringcrypto: not available") }
+func SetTestDRBG(d *TestDRBG) (restore func())      { panic("boringcrypto: not available") }
 
 func NewAESCipher(key []byte) (cipher.Block, error) { panic("boringcrypto: not available") }
 
@@ -69,11 +110,17 @@ func SignMarshalECDSA(priv *PrivateKeyECDSA, hash []byte, h crypto.Hash) ([]byte
 func VerifyECDSA(pub *PublicKeyECDSA, hash []byte, r, s *big.Int, h crypto.Hash) bool {
 	panic("boringcrypto: not available")
 }
+func SignRawECDSA(priv *PrivateKeyECDSA, hash []byte) ([]byte, error) {
+	panic("boringcrypto: not available")
+}
+func VerifyRawECDSA(pub *PublicKeyECDSA, hash, sig []byte) bool {
+	panic("boringcrypto: not available")
+}
 
 type PublicKeyRSA struct{ _ int }
 type PrivateKeyRSA struct{ _ int }
 
-func DecryptRSAOAEP(h hash.Hash, priv *PrivateKeyRSA, ciphertext, label []byte) ([]byte, error) {
+func DecryptRSAOAEP(h, mgfHash hash.Hash, priv *PrivateKeyRSA, ciphertext, label []byte) ([]byte, error) {
 	panic("boringcrypto: not available")
 }
 func DecryptRSAPKCS1(priv *PrivateKeyRSA, ciphertext []byte) ([]byte, error) {
@@ -82,7 +129,7 @@ func DecryptRSAPKCS1(priv *PrivateKeyRSA, ciphertext []byte) ([]byte, error) {
 func DecryptRSANoPadding(priv *PrivateKeyRSA, ciphertext []byte) ([]byte, error) {
 	panic("boringcrypto: not available")
 }
-func EncryptRSAOAEP(h hash.Hash, pub *PublicKeyRSA, msg, label []byte) ([]byte, error) {
+func EncryptRSAOAEP(h, mgfHash hash.Hash, pub *PublicKeyRSA, msg, label []byte) ([]byte, error) {
 	panic("boringcrypto: not available")
 }
 func EncryptRSAPKCS1(pub *PublicKeyRSA, msg []byte) ([]byte, error) {
@@ -110,3 +157,40 @@ func VerifyRSAPKCS1v15(pub *PublicKeyRSA, h crypto.Hash, hashed, sig []byte, msg
 func VerifyRSAPSS(pub *PublicKeyRSA, h crypto.Hash, hashed, sig []byte, saltLen int) error {
 	panic("boringcrypto: not available")
 }
+
+type DigestSigner struct{ _ int }
+type DigestVerifier struct{ _ int }
+
+func (*DigestSigner) Write(p []byte) (int, error)   { panic("boringcrypto: not available") }
+func (*DigestSigner) Sign() ([]byte, error)         { panic("boringcrypto: not available") }
+func (*DigestVerifier) Write(p []byte) (int, error) { panic("boringcrypto: not available") }
+func (*DigestVerifier) Verify(sig []byte) error     { panic("boringcrypto: not available") }
+
+func NewDigestSignerRSAPKCS1v15(priv *PrivateKeyRSA, h crypto.Hash) (*DigestSigner, error) {
+	panic("boringcrypto: not available")
+}
+func NewDigestVerifierRSAPKCS1v15(pub *PublicKeyRSA, h crypto.Hash) (*DigestVerifier, error) {
+	panic("boringcrypto: not available")
+}
+func NewDigestSignerRSAPSS(priv *PrivateKeyRSA, h crypto.Hash, saltLen int) (*DigestSigner, error) {
+	panic("boringcrypto: not available")
+}
+func NewDigestVerifierRSAPSS(pub *PublicKeyRSA, h crypto.Hash, saltLen int) (*DigestVerifier, error) {
+	panic("boringcrypto: not available")
+}
+func NewDigestSignerECDSA(priv *PrivateKeyECDSA, h crypto.Hash) (*DigestSigner, error) {
+	panic("boringcrypto: not available")
+}
+func NewDigestVerifierECDSA(pub *PublicKeyECDSA, h crypto.Hash) (*DigestVerifier, error) {
+	panic("boringcrypto: not available")
+}
+
+func ParsePrivateKey(der []byte) (interface{}, error) { panic("boringcrypto: not available") }
+func (*PrivateKeyRSA) PublicKey() (N, E *big.Int)     { panic("boringcrypto: not available") }
+func (*PrivateKeyECDSA) PublicKey() (curve string, X, Y *big.Int, err error) {
+	panic("boringcrypto: not available")
+}
+func LoadPrivateKey(uri string) (interface{}, error) { panic("boringcrypto: not available") }
+func LoadEnginePrivateKey(engine, keyID string) (interface{}, error) {
+	panic("boringcrypto: not available")
+}
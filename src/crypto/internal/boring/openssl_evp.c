@@ -9,6 +9,7 @@
 int
 _goboringcrypto_EVP_sign(EVP_MD* md, EVP_PKEY_CTX *ctx, const uint8_t *msg, size_t msgLen, uint8_t *sig, unsigned int *slen, EVP_PKEY *key) {
     EVP_MD_CTX *mdctx = NULL;
+    size_t len;
     int ret = 0;
 
     if (!(mdctx = _goboringcrypto_EVP_MD_CTX_create()))
@@ -21,11 +22,12 @@ _goboringcrypto_EVP_sign(EVP_MD* md, EVP_PKEY_CTX *ctx, const uint8_t *msg, size
         goto err;
 
     /* Obtain the signature length */
-    if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, NULL, slen))
+    if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, NULL, &len))
         goto err;
     /* Obtain the signature */
-    if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, sig, slen))
+    if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, sig, &len))
         goto err;
+    *slen = len;
 
     /* Success */
     ret = 1;
@@ -0,0 +1,70 @@
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+#include "goboringcrypto.h"
+#include <string.h>
+
+// _goboringcrypto_KDF_derive runs the EVP_KDF named kdf. For the MAC-based
+// KDFs, mac names the MAC, keyed with a digest (md) or, for CMAC, with
+// AES of aes_key_size bits. The salt and info parameters are passed
+// through when non-empty. For KBKDF, the fixed input data is passed as the
+// salt, with the separator and length fields turned off, so that callers
+// control its layout. It returns 1 on success and 0 otherwise.
+int _goboringcrypto_KDF_derive(const char *kdf, const char *mac,
+	const GO_EVP_MD *md, int aes_key_size,
+	uint8_t *key, size_t key_len, uint8_t *salt, size_t salt_len,
+	uint8_t *info, size_t info_len, uint8_t *out, size_t out_len) {
+#if OPENSSL_VERSION_NUMBER < 0x30000000L
+	return 0;
+#else
+	EVP_KDF *k;
+	EVP_KDF_CTX *ctx;
+	OSSL_PARAM params[9], *p = params;
+	int zero = 0;
+	int ret = 0;
+
+	if (mac != NULL) {
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_utf8_string(OSSL_KDF_PARAM_MAC, (char *)mac, 0);
+	}
+	if (md != NULL) {
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_utf8_string(OSSL_KDF_PARAM_DIGEST,
+			(char *)_goboringcrypto_EVP_MD_get0_name(md), 0);
+	}
+	switch (aes_key_size) {
+	case 128:
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_utf8_string(OSSL_KDF_PARAM_CIPHER, "AES-128-CBC", 0);
+		break;
+	case 192:
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_utf8_string(OSSL_KDF_PARAM_CIPHER, "AES-192-CBC", 0);
+		break;
+	case 256:
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_utf8_string(OSSL_KDF_PARAM_CIPHER, "AES-256-CBC", 0);
+		break;
+	}
+	*p++ = _goboringcrypto_OSSL_PARAM_construct_octet_string(OSSL_KDF_PARAM_KEY, key, key_len);
+	if (salt_len > 0) {
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_octet_string(OSSL_KDF_PARAM_SALT, salt, salt_len);
+	}
+	if (info_len > 0) {
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_octet_string(OSSL_KDF_PARAM_INFO, info, info_len);
+	}
+	if (strcmp(kdf, OSSL_KDF_NAME_KBKDF) == 0) {
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_int(OSSL_KDF_PARAM_KBKDF_USE_L, &zero);
+		*p++ = _goboringcrypto_OSSL_PARAM_construct_int(OSSL_KDF_PARAM_KBKDF_USE_SEPARATOR, &zero);
+	}
+	*p = _goboringcrypto_OSSL_PARAM_construct_end();
+
+	if ((k = _goboringcrypto_EVP_KDF_fetch(NULL, kdf, NULL)) == NULL) {
+		return 0;
+	}
+	if ((ctx = _goboringcrypto_EVP_KDF_CTX_new(k)) != NULL) {
+		ret = _goboringcrypto_EVP_KDF_derive(ctx, out, out_len, params) == 1;
+		_goboringcrypto_EVP_KDF_CTX_free(ctx);
+	}
+	_goboringcrypto_EVP_KDF_free(k);
+	return ret;
+#endif
+}
@@ -0,0 +1,79 @@
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+#include "goboringcrypto.h"
+
+// _goboringcrypto_ENGINE_load_private_key_by_id loads key_id with the
+// ENGINE named engine_id, which is initialized for the call. The key keeps
+// its own reference to the engine. It returns NULL on failure.
+GO_EVP_PKEY *_goboringcrypto_ENGINE_load_private_key_by_id(const char *engine_id, const char *key_id) {
+#ifdef OPENSSL_NO_ENGINE
+	return NULL;
+#else
+	ENGINE *e = _goboringcrypto_ENGINE_by_id(engine_id);
+	if (e == NULL) {
+		return NULL;
+	}
+	if (!_goboringcrypto_ENGINE_init(e)) {
+		_goboringcrypto_ENGINE_free(e);
+		return NULL;
+	}
+	GO_EVP_PKEY *pkey = _goboringcrypto_ENGINE_load_private_key(e, key_id, NULL, NULL);
+	_goboringcrypto_ENGINE_finish(e);
+	_goboringcrypto_ENGINE_free(e);
+	return pkey;
+#endif
+}
+
+// _goboringcrypto_OSSL_STORE_load_private_key returns the first private
+// key found at uri, or NULL if there is none or loading fails.
+GO_EVP_PKEY *_goboringcrypto_OSSL_STORE_load_private_key(const char *uri) {
+#if OPENSSL_VERSION_NUMBER < 0x10101000L
+	return NULL;
+#else
+	OSSL_STORE_CTX *ctx = _goboringcrypto_OSSL_STORE_open(uri, NULL, NULL, NULL, NULL);
+	if (ctx == NULL) {
+		return NULL;
+	}
+	// Not all loaders can filter by type, so this is only a hint.
+	_goboringcrypto_OSSL_STORE_expect(ctx, OSSL_STORE_INFO_PKEY);
+	GO_EVP_PKEY *pkey = NULL;
+	while (pkey == NULL && !_goboringcrypto_OSSL_STORE_eof(ctx)) {
+		OSSL_STORE_INFO *info = _goboringcrypto_OSSL_STORE_load(ctx);
+		if (info == NULL) {
+			if (_goboringcrypto_OSSL_STORE_error(ctx)) {
+				break;
+			}
+			continue;
+		}
+		if (_goboringcrypto_OSSL_STORE_INFO_get_type(info) == OSSL_STORE_INFO_PKEY) {
+			pkey = _goboringcrypto_OSSL_STORE_INFO_get1_PKEY(info);
+		}
+		_goboringcrypto_OSSL_STORE_INFO_free(info);
+	}
+	_goboringcrypto_OSSL_STORE_close(ctx);
+	return pkey;
+#endif
+}
+
+GO_EVP_PKEY *_goboringcrypto_EVP_PKEY_dup_public(GO_EVP_PKEY *pkey) {
+	int len = _goboringcrypto_i2d_PUBKEY(pkey, NULL);
+	if (len <= 0) {
+		return NULL;
+	}
+	unsigned char *der = malloc(len);
+	if (der == NULL) {
+		return NULL;
+	}
+	// i2d_PUBKEY advances p past the encoding.
+	unsigned char *p = der;
+	GO_EVP_PKEY *pub = NULL;
+	if (_goboringcrypto_i2d_PUBKEY(pkey, &p) == len) {
+		pub = _goboringcrypto_d2i_PUBKEY(der, len);
+	}
+	free(der);
+	return pub;
+}
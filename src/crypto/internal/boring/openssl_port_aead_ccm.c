@@ -0,0 +1,142 @@
+// This file contains the CCM counterpart of openssl_port_aead_gcm.c.
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+#include "goboringcrypto.h"
+#include <openssl/err.h>
+
+static const EVP_CIPHER *
+_goboringcrypto_EVP_aes_ccm(int key_size) {
+	switch (key_size) {
+	case 128:
+		return _goboringcrypto_EVP_aes_128_ccm();
+	case 192:
+		return _goboringcrypto_EVP_aes_192_ccm();
+	case 256:
+		return _goboringcrypto_EVP_aes_256_ccm();
+	}
+	return NULL;
+}
+
+int _goboringcrypto_EVP_CIPHER_CTX_seal_ccm(
+		uint8_t *out, uint8_t *nonce, int nonce_len,
+		uint8_t *aad, int aad_len,
+		uint8_t *plaintext, int plaintext_len,
+		int tag_len, uint8_t *key, int key_size) {
+
+	EVP_CIPHER_CTX *ctx;
+	const EVP_CIPHER *cipher;
+	int len;
+	int ret = 0;
+
+	if (plaintext_len == 0) {
+		plaintext = "";
+	}
+
+	if (!(cipher = _goboringcrypto_EVP_aes_ccm(key_size))) {
+		return 0;
+	}
+	if (!(ctx = _goboringcrypto_EVP_CIPHER_CTX_new())) {
+		return 0;
+	}
+
+	// The nonce and tag lengths must be set before the key and nonce.
+	if (!_goboringcrypto_EVP_EncryptInit_ex(ctx, cipher, NULL, NULL, NULL)) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_CIPHER_CTX_ctrl(ctx, EVP_CTRL_CCM_SET_IVLEN, nonce_len, NULL)) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_CIPHER_CTX_ctrl(ctx, EVP_CTRL_CCM_SET_TAG, tag_len, NULL)) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_EncryptInit_ex(ctx, NULL, NULL, key, nonce)) {
+		goto err;
+	}
+
+	// CCM needs the plaintext length ahead of the AAD.
+	if (!_goboringcrypto_EVP_EncryptUpdate(ctx, NULL, &len, NULL, plaintext_len)) {
+		goto err;
+	}
+	if (aad_len > 0 && !_goboringcrypto_EVP_EncryptUpdate(ctx, NULL, &len, aad, aad_len)) {
+		goto err;
+	}
+
+	if (!_goboringcrypto_EVP_EncryptUpdate(ctx, out, &len, plaintext, plaintext_len) || len != plaintext_len) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_EncryptFinal_ex(ctx, out + len, &len)) {
+		goto err;
+	}
+
+	if (!_goboringcrypto_EVP_CIPHER_CTX_ctrl(ctx, EVP_CTRL_CCM_GET_TAG, tag_len, out + plaintext_len)) {
+		goto err;
+	}
+	ret = 1;
+
+err:
+	_goboringcrypto_EVP_CIPHER_CTX_free(ctx);
+	return ret;
+}
+
+int _goboringcrypto_EVP_CIPHER_CTX_open_ccm(
+		uint8_t *ciphertext, int ciphertext_len,
+		uint8_t *aad, int aad_len,
+		uint8_t *tag, int tag_len,
+		uint8_t *nonce, int nonce_len,
+		uint8_t *key, int key_size,
+		uint8_t *plaintext) {
+
+	EVP_CIPHER_CTX *ctx;
+	const EVP_CIPHER *cipher;
+	uint8_t empty;
+	int len;
+	int ret = 0;
+
+	// With a NULL output, EVP_DecryptUpdate would take the message for
+	// AAD, so empty messages need non-NULL buffers.
+	if (ciphertext_len == 0) {
+		ciphertext = &empty;
+		plaintext = &empty;
+	}
+
+	if (!(cipher = _goboringcrypto_EVP_aes_ccm(key_size))) {
+		return 0;
+	}
+	if (!(ctx = _goboringcrypto_EVP_CIPHER_CTX_new())) {
+		return 0;
+	}
+
+	if (!_goboringcrypto_EVP_DecryptInit_ex(ctx, cipher, NULL, NULL, NULL)) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_CIPHER_CTX_ctrl(ctx, EVP_CTRL_CCM_SET_IVLEN, nonce_len, NULL)) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_CIPHER_CTX_ctrl(ctx, EVP_CTRL_CCM_SET_TAG, tag_len, tag)) {
+		goto err;
+	}
+	if (!_goboringcrypto_EVP_DecryptInit_ex(ctx, NULL, NULL, key, nonce)) {
+		goto err;
+	}
+
+	if (!_goboringcrypto_EVP_DecryptUpdate(ctx, NULL, &len, NULL, ciphertext_len)) {
+		goto err;
+	}
+	if (aad_len > 0 && !_goboringcrypto_EVP_DecryptUpdate(ctx, NULL, &len, aad, aad_len)) {
+		goto err;
+	}
+
+	// Unlike GCM, CCM checks the tag in the final EVP_DecryptUpdate, and
+	// EVP_DecryptFinal_ex must not be called.
+	if (_goboringcrypto_EVP_DecryptUpdate(ctx, plaintext, &len, ciphertext, ciphertext_len) > 0 && len == ciphertext_len) {
+		ret = 1;
+	}
+
+err:
+	_goboringcrypto_EVP_CIPHER_CTX_free(ctx);
+	return ret;
+}
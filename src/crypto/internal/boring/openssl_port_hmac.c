@@ -8,10 +8,15 @@
 #include "goboringcrypto.h"
 
 // Not in OpenSSL 1.1.  However, HMAC_CTX_copy expects an initialized
-// target in OpenSSL 1.1.
+// target in OpenSSL 1.1.  Before OpenSSL 1.1, HMAC_CTX_copy re-initializes
+// the digest contexts of dest without freeing their state, so dest is
+// cleaned up first; boringHMAC.Sum copies into the same dest every time.
 int
 _goboringcrypto_HMAC_CTX_copy_ex(GO_HMAC_CTX *dest, const GO_HMAC_CTX *src)
 {
+#if OPENSSL_VERSION_NUMBER < 0x10100000L
+  _goboringcrypto_HMAC_CTX_reset(dest);
+#endif
   // HMAC_CTX_copy lacks the const qualifier for the second parameter.
   return _goboringcrypto_HMAC_CTX_copy(dest, (GO_HMAC_CTX *) src);
 }
@@ -23,7 +23,7 @@ int _goboringcrypto_RSA_digest_and_sign_pss_mgf1(GO_RSA *rsa, unsigned int *out_
 		const uint8_t *in, size_t in_len, EVP_MD *md, const EVP_MD *mgf1_md, int salt_len)
 {
 	EVP_PKEY_CTX *ctx;
-	unsigned int siglen;
+	size_t siglen;
 
 	EVP_PKEY *key = _goboringcrypto_EVP_PKEY_new();
 	if (!_goboringcrypto_EVP_PKEY_assign_RSA(key, rsa))
@@ -56,11 +56,12 @@ int _goboringcrypto_RSA_digest_and_sign_pss_mgf1(GO_RSA *rsa, unsigned int *out_
 		goto err;
 
 	/* Obtain the signature length */
-	if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, NULL, out_len))
+	if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, NULL, &siglen))
 		goto err;
 	/* Obtain the signature */
-	if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, out, out_len))
+	if (1 != _goboringcrypto_EVP_DigestSignFinal(mdctx, out, &siglen))
 		goto err;
+	*out_len = siglen;
 
 	ret = 1;
 
@@ -0,0 +1,357 @@
+// This file contains a port of the constant-time TLS CBC record MAC.
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+// The following is a partial port of ssl/s3_cbc.c from the OpenSSL 3.0
+// branch, for libraries without the TLS mode of the provider HMAC. Only
+// TLS (not SSLv3) with HMAC-SHA1 and HMAC-SHA256 is supported, and the
+// constant-time helpers of include/internal/constant_time.h are inlined.
+
+#include "goboringcrypto.h"
+
+/*
+ * Copyright 2012-2021 The OpenSSL Project Authors. All Rights Reserved.
+ *
+ * Licensed under the Apache License 2.0 (the "License").  You may not use
+ * this file except in compliance with the License.  You can obtain a copy
+ * in the file LICENSE in the source distribution or at
+ * https://www.openssl.org/source/license.html
+ */
+
+#include <string.h>
+#include <openssl/sha.h>
+
+#define MAX_HASH_BLOCK_SIZE 64
+#define MAX_HASH_BIT_COUNT_BYTES 8
+
+static inline size_t constant_time_msb_s(size_t a)
+{
+    return 0 - (a >> (sizeof(a) * 8 - 1));
+}
+
+static inline size_t constant_time_lt_s(size_t a, size_t b)
+{
+    return constant_time_msb_s(a ^ ((a ^ b) | ((a - b) ^ b)));
+}
+
+static inline size_t constant_time_ge_s(size_t a, size_t b)
+{
+    return ~constant_time_lt_s(a, b);
+}
+
+static inline unsigned char constant_time_ge_8_s(size_t a, size_t b)
+{
+    return (unsigned char)constant_time_ge_s(a, b);
+}
+
+static inline size_t constant_time_is_zero_s(size_t a)
+{
+    return constant_time_msb_s(~a & (a - 1));
+}
+
+static inline unsigned char constant_time_eq_8_s(size_t a, size_t b)
+{
+    return (unsigned char)constant_time_is_zero_s(a ^ b);
+}
+
+static inline unsigned char constant_time_select_8(unsigned char mask,
+                                                   unsigned char a,
+                                                   unsigned char b)
+{
+    return (unsigned char)((mask & a) | (~mask & b));
+}
+
+#define l2n(l,c)        (*((c)++)=(unsigned char)(((l)>>24)&0xff), \
+                         *((c)++)=(unsigned char)(((l)>>16)&0xff), \
+                         *((c)++)=(unsigned char)(((l)>> 8)&0xff), \
+                         *((c)++)=(unsigned char)(((l)    )&0xff))
+
+/*
+ * These functions serialize the state of a hash and thus perform the
+ * standard "final" operation without adding the padding and length that
+ * such a function typically does.
+ */
+static void tls1_sha1_final_raw(void *ctx, unsigned char *md_out)
+{
+    SHA_CTX *sha1 = ctx;
+    l2n(sha1->h0, md_out);
+    l2n(sha1->h1, md_out);
+    l2n(sha1->h2, md_out);
+    l2n(sha1->h3, md_out);
+    l2n(sha1->h4, md_out);
+}
+
+static void tls1_sha256_final_raw(void *ctx, unsigned char *md_out)
+{
+    SHA256_CTX *sha256 = ctx;
+    unsigned i;
+
+    for (i = 0; i < 8; i++) {
+        l2n(sha256->h[i], md_out);
+    }
+}
+
+static void tls1_sha1_transform(void *ctx, const unsigned char *block)
+{
+    _goboringcrypto_SHA1_Transform(ctx, block);
+}
+
+static void tls1_sha256_transform(void *ctx, const unsigned char *block)
+{
+    _goboringcrypto_SHA256_Transform(ctx, block);
+}
+
+/*
+ * _goboringcrypto_ssl3_cbc_digest_record computes the MAC of a decrypted
+ * CBC record in constant time, even though the end of the record, given
+ * by data_size, is secret.
+ *
+ *   md: the hash function, EVP_sha1() or EVP_sha256().
+ *   mac_secret: the HMAC key, at most a block long.
+ *   header: the 13-byte, TLS record header.
+ *   data: the record data itself, less the MAC and padding.
+ *   data_size: the secret, reported length of the data once the MAC and
+ *     padding have been removed.
+ *   data_plus_mac_plus_padding_size: the public length of the whole
+ *     record, including the MAC and padding, all of which may be read
+ *     from data.
+ *   md_out: the digest output. At most EVP_MAX_MD_SIZE bytes are written.
+ *
+ * It returns 1 on success and 0 otherwise.
+ */
+int _goboringcrypto_ssl3_cbc_digest_record(const GO_EVP_MD *md,
+	const uint8_t *mac_secret, size_t mac_secret_length,
+	const uint8_t *header, const uint8_t *data, size_t data_size,
+	size_t data_plus_mac_plus_padding_size, uint8_t *md_out)
+{
+    union {
+        double align;
+        unsigned char c[sizeof(SHA256_CTX)];
+    } md_state;
+    void (*md_final_raw) (void *ctx, unsigned char *md_out);
+    void (*md_transform) (void *ctx, const unsigned char *block);
+    size_t md_size, md_block_size = 64;
+    size_t header_length, variance_blocks,
+        len, max_mac_bytes, num_blocks,
+        num_starting_blocks, k, mac_end_offset, c, index_a, index_b;
+    size_t bits;          /* at most 18 bits */
+    unsigned char length_bytes[MAX_HASH_BIT_COUNT_BYTES];
+    /* hmac_pad is the masked HMAC key. */
+    unsigned char hmac_pad[MAX_HASH_BLOCK_SIZE];
+    unsigned char first_block[MAX_HASH_BLOCK_SIZE];
+    unsigned char mac_out[EVP_MAX_MD_SIZE];
+    size_t i, j;
+    unsigned md_out_size_u;
+    GO_EVP_MD_CTX *md_ctx = NULL;
+    /*
+     * mdLengthSize is the number of bytes in the length field that
+     * terminates the hash.
+     */
+    size_t md_length_size = 8;
+    int ret = 0;
+
+    /*
+     * This is a, hopefully redundant, check that allows us to forget about
+     * many possible overflows later in this function.
+     */
+    if (data_plus_mac_plus_padding_size >= 1024 * 1024)
+        return 0;
+
+    if (md == _goboringcrypto_EVP_sha1()) {
+        if (_goboringcrypto_SHA1_Init((SHA_CTX *)md_state.c) <= 0)
+            return 0;
+        md_final_raw = tls1_sha1_final_raw;
+        md_transform = tls1_sha1_transform;
+        md_size = 20;
+    } else if (md == _goboringcrypto_EVP_sha256()) {
+        if (_goboringcrypto_SHA256_Init((SHA256_CTX *)md_state.c) <= 0)
+            return 0;
+        md_final_raw = tls1_sha256_final_raw;
+        md_transform = tls1_sha256_transform;
+        md_size = 32;
+    } else {
+        return 0;
+    }
+
+    if (mac_secret_length > md_block_size
+            || data_size > data_plus_mac_plus_padding_size
+            || data_plus_mac_plus_padding_size < md_size + 1)
+        return 0;
+
+    header_length = 13;
+
+    /*
+     * variance_blocks is the number of blocks of the hash that we have to
+     * calculate in constant time because they could be altered by the
+     * padding value. The padding can be up to 255 bytes, plus the
+     * length byte, and the MAC itself comes after the data.
+     */
+    variance_blocks = ((255 + 1 + md_size + md_block_size - 1)
+                       / md_block_size) + 1;
+    /*
+     * From now on we're dealing with the MAC, which conceptually has 13
+     * bytes of `header' before the start of the data.
+     */
+    len = data_plus_mac_plus_padding_size + header_length;
+    /*
+     * max_mac_bytes contains the maximum bytes of bytes in the MAC,
+     * including |header|, assuming that there's no padding.
+     */
+    max_mac_bytes = len - md_size - 1;
+    /* num_blocks is the maximum number of hash blocks. */
+    num_blocks =
+        (max_mac_bytes + 1 + md_length_size + md_block_size -
+         1) / md_block_size;
+    /*
+     * In order to calculate the MAC in constant time we have to handle the
+     * final blocks specially because the padding value could cause the end
+     * to appear somewhere in the final |variance_blocks| blocks and we can't
+     * leak where. However, |num_starting_blocks| worth of data can be hashed
+     * right away because no padding value can affect whether they are
+     * plaintext.
+     */
+    num_starting_blocks = 0;
+    /*
+     * k is the starting byte offset into the conceptual header||data where
+     * we start processing.
+     */
+    k = 0;
+    /*
+     * mac_end_offset is the index just past the end of the data to be MACed.
+     */
+    mac_end_offset = data_size + header_length;
+    /*
+     * c is the index of the 0x80 byte in the final hash block that contains
+     * application data.
+     */
+    c = mac_end_offset % md_block_size;
+    /*
+     * index_a is the hash block number that contains the 0x80 terminating
+     * value.
+     */
+    index_a = mac_end_offset / md_block_size;
+    /*
+     * index_b is the hash block number that contains the 64-bit hash length,
+     * in bits.
+     */
+    index_b = (mac_end_offset + md_length_size) / md_block_size;
+
+    if (num_blocks > variance_blocks) {
+        num_starting_blocks = num_blocks - variance_blocks;
+        k = md_block_size * num_starting_blocks;
+    }
+
+    /*
+     * bits is the hash-length in bits. It includes the additional hash block
+     * for the masked HMAC key.
+     */
+    bits = 8 * mac_end_offset;
+    bits += 8 * md_block_size;
+
+    /* Compute the initial HMAC block. */
+    memset(hmac_pad, 0, md_block_size);
+    memcpy(hmac_pad, mac_secret, mac_secret_length);
+    for (i = 0; i < md_block_size; i++)
+        hmac_pad[i] ^= 0x36;
+
+    md_transform(md_state.c, hmac_pad);
+
+    memset(length_bytes, 0, md_length_size - 4);
+    length_bytes[md_length_size - 4] = (unsigned char)(bits >> 24);
+    length_bytes[md_length_size - 3] = (unsigned char)(bits >> 16);
+    length_bytes[md_length_size - 2] = (unsigned char)(bits >> 8);
+    length_bytes[md_length_size - 1] = (unsigned char)bits;
+
+    if (k > 0) {
+        /* k is a multiple of md_block_size. */
+        memcpy(first_block, header, 13);
+        memcpy(first_block + 13, data, md_block_size - 13);
+        md_transform(md_state.c, first_block);
+        for (i = 1; i < k / md_block_size; i++)
+            md_transform(md_state.c, data + md_block_size * i - 13);
+    }
+
+    memset(mac_out, 0, sizeof(mac_out));
+
+    /*
+     * We now process the final hash blocks. For each block, we construct it
+     * in constant time. If the |i==index_a| then we'll include the 0x80
+     * bytes and zero pad etc. For each block we selectively copy it, in
+     * constant time, to |mac_out|.
+     */
+    for (i = num_starting_blocks; i <= num_starting_blocks + variance_blocks;
+         i++) {
+        unsigned char block[MAX_HASH_BLOCK_SIZE];
+        unsigned char is_block_a = constant_time_eq_8_s(i, index_a);
+        unsigned char is_block_b = constant_time_eq_8_s(i, index_b);
+        for (j = 0; j < md_block_size; j++) {
+            unsigned char b = 0, is_past_c, is_past_cp1;
+            if (k < header_length)
+                b = header[k];
+            else if (k < data_plus_mac_plus_padding_size + header_length)
+                b = data[k - header_length];
+            k++;
+
+            is_past_c = is_block_a & constant_time_ge_8_s(j, c);
+            is_past_cp1 = is_block_a & constant_time_ge_8_s(j, c + 1);
+            /*
+             * If this is the block containing the end of the application
+             * data, and we are at the offset for the 0x80 value, then
+             * overwrite b with 0x80.
+             */
+            b = constant_time_select_8(is_past_c, 0x80, b);
+            /*
+             * If this block contains the end of the application data
+             * and we're past the 0x80 value then just write zero.
+             */
+            b = b & ~is_past_cp1;
+            /*
+             * If this is index_b (the final block), but not index_a (the end
+             * of the data), then the 64-bit length didn't fit into index_a
+             * and we're having to add an extra block of zeros.
+             */
+            b &= ~is_block_b | is_block_a;
+
+            /*
+             * The final bytes of one of the blocks contains the length.
+             */
+            if (j >= md_block_size - md_length_size) {
+                /* If this is index_b, write a length byte. */
+                b = constant_time_select_8(is_block_b,
+                                           length_bytes[j -
+                                                        (md_block_size -
+                                                         md_length_size)], b);
+            }
+            block[j] = b;
+        }
+
+        md_transform(md_state.c, block);
+        md_final_raw(md_state.c, block);
+        /* If this is index_b, copy the hash value to |mac_out|. */
+        for (j = 0; j < md_size; j++)
+            mac_out[j] |= block[j] & is_block_b;
+    }
+
+    md_ctx = _goboringcrypto_EVP_MD_CTX_create();
+    if (md_ctx == NULL)
+        goto err;
+
+    /* Complete the HMAC in the standard manner. */
+    for (i = 0; i < md_block_size; i++)
+        hmac_pad[i] ^= 0x6a;   /* 0x36 ^ 0x5c */
+
+    if (_goboringcrypto_EVP_DigestInit_ex(md_ctx, md, NULL) <= 0
+            || _goboringcrypto_EVP_DigestUpdate(md_ctx, hmac_pad, md_block_size) <= 0
+            || _goboringcrypto_EVP_DigestUpdate(md_ctx, mac_out, md_size) <= 0
+            || _goboringcrypto_EVP_DigestFinal_ex(md_ctx, md_out, &md_out_size_u) <= 0)
+        goto err;
+
+    ret = 1;
+ err:
+    if (md_ctx != NULL)
+        _goboringcrypto_EVP_MD_CTX_free(md_ctx);
+    return ret;
+}
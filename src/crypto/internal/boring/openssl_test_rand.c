@@ -0,0 +1,44 @@
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+#include "goboringcrypto.h"
+#include "_cgo_export.h"
+#include <openssl/rand.h>
+
+static RAND_METHOD test_rand;
+static const RAND_METHOD *old_rand;
+
+static int test_rand_bytes(unsigned char *buf, int num)
+{
+    return _goboringcrypto_test_rand_bytes(buf, num);
+}
+
+int _goboringcrypto_install_test_rand(void)
+{
+    /* save old rand method */
+    if ((old_rand = _goboringcrypto_RAND_get_rand_method()) == NULL)
+        return 0;
+
+    test_rand.seed = old_rand->seed;
+    test_rand.cleanup = old_rand->cleanup;
+    test_rand.add = old_rand->add;
+    test_rand.status = old_rand->status;
+    /* draw all output, including private and pseudorandom bytes, from the test DRBG */
+    test_rand.bytes = test_rand_bytes;
+    test_rand.pseudorand = test_rand_bytes;
+    /* set new RAND_METHOD */
+    if (!_goboringcrypto_RAND_set_rand_method(&test_rand))
+        return 0;
+    return 1;
+}
+
+int _goboringcrypto_restore_openssl_rand(void)
+{
+    if (!_goboringcrypto_RAND_set_rand_method(old_rand))
+        return 0;
+    else
+        return 1;
+}
@@ -0,0 +1,46 @@
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+#include "goboringcrypto.h"
+
+// _goboringcrypto_EVP_MAC_TLS_CBC_HMAC writes to out the HMAC of the
+// 13-byte header followed by the first data_len bytes of data, which holds
+// the record_len bytes of a decrypted TLS CBC record: its content, MAC
+// and padding. The HMAC of the providers reads all of them, so that the
+// time taken does not depend on data_len. It returns 1 on success and 0
+// otherwise.
+int _goboringcrypto_EVP_MAC_TLS_CBC_HMAC(const GO_EVP_MD *md,
+	const uint8_t *key, size_t key_len, const uint8_t *header,
+	const uint8_t *data, size_t data_len, size_t record_len, uint8_t *out) {
+#if OPENSSL_VERSION_NUMBER < 0x30000000L
+	return 0;
+#else
+	EVP_MAC *mac;
+	EVP_MAC_CTX *ctx;
+	OSSL_PARAM params[3];
+	size_t out_len;
+	int ret = 0;
+
+	params[0] = _goboringcrypto_OSSL_PARAM_construct_utf8_string(OSSL_MAC_PARAM_DIGEST,
+		(char *)_goboringcrypto_EVP_MD_get0_name(md), 0);
+	params[1] = _goboringcrypto_OSSL_PARAM_construct_size_t(OSSL_MAC_PARAM_TLS_DATA_SIZE, &record_len);
+	params[2] = _goboringcrypto_OSSL_PARAM_construct_end();
+
+	if ((mac = _goboringcrypto_EVP_MAC_fetch(NULL, "HMAC", NULL)) == NULL) {
+		return 0;
+	}
+	if ((ctx = _goboringcrypto_EVP_MAC_CTX_new(mac)) != NULL) {
+		// The first update must be exactly the header.
+		ret = _goboringcrypto_EVP_MAC_init(ctx, key, key_len, params) == 1 &&
+			_goboringcrypto_EVP_MAC_update(ctx, header, 13) == 1 &&
+			_goboringcrypto_EVP_MAC_update(ctx, data, data_len) == 1 &&
+			_goboringcrypto_EVP_MAC_final(ctx, out, &out_len, EVP_MAX_MD_SIZE) == 1;
+		_goboringcrypto_EVP_MAC_CTX_free(ctx);
+	}
+	_goboringcrypto_EVP_MAC_free(mac);
+	return ret;
+#endif
+}
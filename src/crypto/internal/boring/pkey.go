@@ -0,0 +1,197 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"errors"
+	"math/big"
+	"runtime"
+	"unsafe"
+)
+
+// ParsePrivateKey parses a DER-encoded PKCS #1 RSA, SEC 1 EC or
+// unencrypted PKCS #8 private key straight into the module, so that the
+// secret components never reach the Go heap. It returns a *PrivateKeyRSA
+// or a *PrivateKeyECDSA, which are checked like imported keys and then
+// used through the EVP_PKEY that holds them.
+func ParsePrivateKey(der []byte) (interface{}, error) {
+	if len(der) == 0 {
+		return nil, errors.New("boringcrypto: empty private key")
+	}
+	pkey := C._goboringcrypto_d2i_AutoPrivateKey((*C.uchar)(unsafe.Pointer(&der[0])), C.long(len(der)))
+	if pkey == nil {
+		return nil, NewOpenSSLError("d2i_AutoPrivateKey failed")
+	}
+	return newPrivateKey(pkey)
+}
+
+// LoadPrivateKey loads the first private key found at uri with an
+// OSSL_STORE loader, such as the built-in file loader or a PKCS #11 one.
+// The key may stay in the device that holds it: the module only uses it
+// through the EVP_PKEY of the loader, which also works for OpenSSL 3
+// provider keys that cannot be exported. It returns a *PrivateKeyRSA or
+// a *PrivateKeyECDSA.
+func LoadPrivateKey(uri string) (interface{}, error) {
+	if C._goboringcrypto_OSSL_STORE_supported() == 0 {
+		return nil, errors.New("boringcrypto: OSSL_STORE requires OpenSSL 1.1.1 or later")
+	}
+	curi := C.CString(uri)
+	defer C.free(unsafe.Pointer(curi))
+	pkey := C._goboringcrypto_OSSL_STORE_load_private_key(curi)
+	if pkey == nil {
+		return nil, NewOpenSSLError("OSSL_STORE failed to load a private key")
+	}
+	return newPKeyPrivateKey(pkey)
+}
+
+// LoadEnginePrivateKey is like LoadPrivateKey, but loads the key named
+// keyID with the ENGINE whose id is engine, as with the -engine and
+// -keyform engine options of the openssl command.
+func LoadEnginePrivateKey(engine, keyID string) (interface{}, error) {
+	cengine := C.CString(engine)
+	defer C.free(unsafe.Pointer(cengine))
+	ckeyID := C.CString(keyID)
+	defer C.free(unsafe.Pointer(ckeyID))
+	pkey := C._goboringcrypto_ENGINE_load_private_key_by_id(cengine, ckeyID)
+	if pkey == nil {
+		return nil, NewOpenSSLError("ENGINE_load_private_key failed")
+	}
+	return newPKeyPrivateKey(pkey)
+}
+
+// newPrivateKey returns the RSA or EC key held by pkey, which must have
+// its private components in memory, after checking it like an imported
+// key with RSA_check_key or EC_KEY_check_key. It takes ownership of pkey.
+func newPrivateKey(pkey *C.GO_EVP_PKEY) (interface{}, error) {
+	if key := C._goboringcrypto_EVP_PKEY_get1_RSA(pkey); key != nil {
+		ok := C._goboringcrypto_RSA_check_key(key) == 1
+		C._goboringcrypto_RSA_free(key)
+		if !ok {
+			err := NewOpenSSLError("RSA_check_key failed")
+			C._goboringcrypto_EVP_PKEY_free(pkey)
+			return nil, err
+		}
+		return newPKeyPrivateKey(pkey)
+	}
+	if key := C._goboringcrypto_EVP_PKEY_get1_EC_KEY(pkey); key != nil {
+		// Drop the error left by the failed EVP_PKEY_get1_RSA.
+		clearErrors()
+		ok := C._goboringcrypto_EC_KEY_check_key(key) == 1
+		C._goboringcrypto_EC_KEY_free(key)
+		if !ok {
+			err := NewOpenSSLError("EC_KEY_check_key failed")
+			C._goboringcrypto_EVP_PKEY_free(pkey)
+			return nil, err
+		}
+		return newPKeyPrivateKey(pkey)
+	}
+	clearErrors()
+	C._goboringcrypto_EVP_PKEY_free(pkey)
+	return nil, errors.New("boringcrypto: unsupported private key type")
+}
+
+// newPKeyPrivateKey returns the RSA or EC key held by pkey, which may be
+// in a device that does not expose the private components. It takes
+// ownership of pkey, which all private-key operations go through; the public
+// components are read from a copy of its public half, which also checks
+// the signature of the pairwise consistency test.
+func newPKeyPrivateKey(pkey *C.GO_EVP_PKEY) (interface{}, error) {
+	pub := C._goboringcrypto_EVP_PKEY_dup_public(pkey)
+	if pub == nil {
+		C._goboringcrypto_EVP_PKEY_free(pkey)
+		return nil, NewOpenSSLError("failed to read the public key")
+	}
+	defer C._goboringcrypto_EVP_PKEY_free(pub)
+
+	if key := C._goboringcrypto_EVP_PKEY_get1_RSA(pub); key != nil {
+		k := &PrivateKeyRSA{_key: key, pkey: pkey}
+		runtime.SetFinalizer(k, (*PrivateKeyRSA).finalize)
+		if err := pairwiseTest(pkey, pub, setupRSAPKCS1v15); err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+	if key := C._goboringcrypto_EVP_PKEY_get1_EC_KEY(pub); key != nil {
+		// Drop the error left by the failed EVP_PKEY_get1_RSA.
+		clearErrors()
+		k := &PrivateKeyECDSA{key: key, pkey: pkey}
+		runtime.SetFinalizer(k, (*PrivateKeyECDSA).finalize)
+		curve := curveName(C._goboringcrypto_EC_GROUP_get_curve_name(C._goboringcrypto_EC_KEY_get0_group(key)))
+		err := checkSigningCurve(curve)
+		if curve == "" {
+			err = errUnknownCurve
+		}
+		if err == nil {
+			err = pairwiseTest(pkey, pub, nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return k, nil
+	}
+	clearErrors()
+	C._goboringcrypto_EVP_PKEY_free(pkey)
+	return nil, errors.New("boringcrypto: unsupported private key type")
+}
+
+// clearErrors empties the OpenSSL error queue.
+func clearErrors() {
+	for C._goboringcrypto_internal_ERR_get_error() != 0 {
+	}
+}
+
+// curveName is the inverse of curveNID. It returns "" for unknown curves.
+func curveName(nid C.int) string {
+	for _, curve := range []string{"P-224", "P-256", "P-384", "P-521"} {
+		if n, _ := curveNID(curve); n == nid {
+			return curve
+		}
+	}
+	return ""
+}
+
+// PublicKey returns the public components of k.
+func (k *PrivateKeyRSA) PublicKey() (N, E *big.Int) {
+	k.withKey(func(key *C.GO_RSA) C.int {
+		var n, e *C.GO_BIGNUM
+		C._goboringcrypto_RSA_get0_key(key, &n, &e, nil)
+		N, E = bnToBig(n), bnToBig(e)
+		return 1
+	})
+	return N, E
+}
+
+// PublicKey returns the curve name and public point of k.
+func (k *PrivateKeyECDSA) PublicKey() (curve string, X, Y *big.Int, err error) {
+	defer runtime.KeepAlive(k)
+	group := C._goboringcrypto_EC_KEY_get0_group(k.key)
+	curve = curveName(C._goboringcrypto_EC_GROUP_get_curve_name(group))
+	if curve == "" {
+		return "", nil, nil, errUnknownCurve
+	}
+	bx := C._goboringcrypto_BN_new()
+	if bx == nil {
+		return "", nil, nil, NewOpenSSLError("BN_new failed")
+	}
+	defer C._goboringcrypto_BN_free(bx)
+	by := C._goboringcrypto_BN_new()
+	if by == nil {
+		return "", nil, nil, NewOpenSSLError("BN_new failed")
+	}
+	defer C._goboringcrypto_BN_free(by)
+	pt := C._goboringcrypto_EC_KEY_get0_public_key(k.key)
+	if C._goboringcrypto_EC_POINT_get_affine_coordinates_GFp(group, pt, bx, by, nil) == 0 {
+		return "", nil, nil, NewOpenSSLError("EC_POINT_get_affine_coordinates_GFp failed")
+	}
+	return curve, bnToBig(bx), bnToBig(by), nil
+}
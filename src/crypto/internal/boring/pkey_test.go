@@ -0,0 +1,71 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+import (
+	"crypto"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+)
+
+type pkcs1PrivateKey struct {
+	Version                     int
+	N, E, D, P, Q, Dp, Dq, Qinv *big.Int
+}
+
+func TestParsePrivateKeyRSA(t *testing.T) {
+	if !Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	N, E, D, P, Q, Dp, Dq, Qinv, err := GenerateKeyRSA(2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := asn1.Marshal(pkcs1PrivateKey{0, N, E, D, P, Q, Dp, Dq, Qinv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	k, err := ParsePrivateKey(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv := k.(*PrivateKeyRSA)
+	if priv.pkey == nil {
+		t.Fatal("parsed key is not used through its EVP_PKEY")
+	}
+
+	pub, err := NewPublicKeyRSA(N, E)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewSHA256()
+	h.Write([]byte("hello"))
+	hashed := h.Sum(nil)
+	sig, err := SignRSAPSS(priv, crypto.SHA256, hashed, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyRSAPSS(pub, crypto.SHA256, hashed, sig, 0); err != nil {
+		t.Error(err)
+	}
+
+	// A private exponent that does not match the rest of the key must
+	// fail the import checks.
+	bad := new(big.Int).Add(D, big.NewInt(2))
+	der, err = asn1.Marshal(pkcs1PrivateKey{0, N, E, bad, P, Q, Dp, Dq, Qinv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParsePrivateKey(der); err == nil {
+		t.Error("ParsePrivateKey accepted an inconsistent key")
+	}
+}
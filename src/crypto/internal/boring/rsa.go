@@ -36,6 +36,9 @@ func GenerateKeyRSA(bits int) (N, E, D, P, Q, Dp, Dq, Qinv *big.Int, err error)
 	if C._goboringcrypto_RSA_generate_key_fips(key, C.int(bits), nil) == 0 {
 		return bad(NewOpenSSLError("RSA_generate_key_fips failed"))
 	}
+	if err := checkKeyRSA(key, true); err != nil {
+		return bad(err)
+	}
 
 	var n, e, d, p, q, dp, dq, qinv *C.GO_BIGNUM
 	C._goboringcrypto_RSA_get0_key(key, &n, &e, &d)
@@ -78,6 +81,10 @@ func (k *PublicKeyRSA) withKey(f func(*C.GO_RSA) C.int) C.int {
 type PrivateKeyRSA struct {
 	// _key MUST NOT be accessed directly. Instead, use the withKey method.
 	_key *C.GO_RSA
+	// pkey is set for keys parsed or loaded into the module with
+	// ParsePrivateKey or LoadPrivateKey. Private-key operations then go
+	// through pkey, and _key only has the public components.
+	pkey *C.GO_EVP_PKEY
 }
 
 func NewPrivateKeyRSA(N, E, D, P, Q, Dp, Dq, Qinv *big.Int) (*PrivateKeyRSA, error) {
@@ -101,6 +108,10 @@ func NewPrivateKeyRSA(N, E, D, P, Q, Dp, Dq, Qinv *big.Int) (*PrivateKeyRSA, err
 		qinv = bigToBN(Qinv)
 		C._goboringcrypto_RSA_set0_crt_params(key, dp, dq, qinv)
 	}
+	if err := checkKeyRSA(key, P != nil && Q != nil); err != nil {
+		C._goboringcrypto_RSA_free(key)
+		return nil, err
+	}
 	k := &PrivateKeyRSA{_key: key}
 	runtime.SetFinalizer(k, (*PrivateKeyRSA).finalize)
 	return k, nil
@@ -108,6 +119,9 @@ func NewPrivateKeyRSA(N, E, D, P, Q, Dp, Dq, Qinv *big.Int) (*PrivateKeyRSA, err
 
 func (k *PrivateKeyRSA) finalize() {
 	C._goboringcrypto_RSA_free(k._key)
+	if k.pkey != nil {
+		C._goboringcrypto_EVP_PKEY_free(k.pkey)
+	}
 }
 
 func (k *PrivateKeyRSA) withKey(f func(*C.GO_RSA) C.int) C.int {
@@ -118,8 +132,63 @@ func (k *PrivateKeyRSA) withKey(f func(*C.GO_RSA) C.int) C.int {
 	return f(k._key)
 }
 
-func setupRSA(withKey func(func(*C.GO_RSA) C.int) C.int,
-	padding C.int, h hash.Hash, label []byte, saltLen int, ch crypto.Hash,
+// newPKey returns an EVP_PKEY for k, which the caller must free.
+func (k *PrivateKeyRSA) newPKey() (*C.GO_EVP_PKEY, error) {
+	if k.pkey == nil {
+		return newRSAPKey(k.withKey)
+	}
+	defer runtime.KeepAlive(k)
+	if C._goboringcrypto_EVP_PKEY_up_ref(k.pkey) != 1 {
+		return nil, NewOpenSSLError("EVP_PKEY_up_ref failed")
+	}
+	return k.pkey, nil
+}
+
+// newPKey returns an EVP_PKEY for k, which the caller must free.
+func (k *PublicKeyRSA) newPKey() (*C.GO_EVP_PKEY, error) {
+	return newRSAPKey(k.withKey)
+}
+
+// checkKeyRSA validates a newly generated or imported private key.
+// RSA_check_key needs the prime factors, so it only runs when they are
+// known. The pairwise consistency test always runs. It uses a PKCS #1
+// v1.5 signature, except for keys too small to hold one with SHA-256
+// (such as those of short tests), which sign a raw message instead.
+func checkKeyRSA(key *C.GO_RSA, hasFactors bool) error {
+	if hasFactors && C._goboringcrypto_RSA_check_key(key) != 1 {
+		return NewOpenSSLError("RSA_check_key failed")
+	}
+	pkey, err := newRSAPKey(func(f func(*C.GO_RSA) C.int) C.int { return f(key) })
+	if err != nil {
+		return err
+	}
+	defer C._goboringcrypto_EVP_PKEY_free(pkey)
+	// A SHA-256 DigestInfo is 51 bytes, plus 11 bytes of padding.
+	size := int(C._goboringcrypto_RSA_size(key))
+	if size >= 62 {
+		return pairwiseTest(pkey, pkey, setupRSAPKCS1v15)
+	}
+	if size < 2 {
+		return errors.New("boringcrypto: RSA key too small")
+	}
+	// The leading zero byte keeps msg below the modulus.
+	msg := make([]byte, size)
+	for i := 1; i < size; i++ {
+		msg[i] = byte(i)
+	}
+	sig, err := signPKey(pkey, nil, msg, C.GO_RSA_NO_PADDING, 0)
+	if err != nil {
+		return err
+	}
+	if verifyPKey(pkey, nil, msg, sig, C.GO_RSA_NO_PADDING, 0) != nil {
+		clearErrors()
+		return errors.New("boringcrypto: RSA pairwise consistency test failed")
+	}
+	return nil
+}
+
+func setupRSA(newPKey func() (*C.GO_EVP_PKEY, error),
+	padding C.int, h, mgfHash hash.Hash, label []byte, saltLen int, ch crypto.Hash,
 	init func(*C.GO_EVP_PKEY_CTX) C.int) (pkey *C.GO_EVP_PKEY, ctx *C.GO_EVP_PKEY_CTX, err error) {
 	defer func() {
 		if err != nil {
@@ -134,14 +203,9 @@ func setupRSA(withKey func(func(*C.GO_RSA) C.int) C.int,
 		}
 	}()
 
-	pkey = C._goboringcrypto_EVP_PKEY_new()
-	if pkey == nil {
-		return nil, nil, NewOpenSSLError("EVP_PKEY_new failed")
-	}
-	if withKey(func(key *C.GO_RSA) C.int {
-		return C._goboringcrypto_EVP_PKEY_set1_RSA(pkey, key)
-	}) == 0 {
-		return nil, nil, fail("EVP_PKEY_set1_RSA")
+	pkey, err = newPKey()
+	if err != nil {
+		return nil, nil, err
 	}
 	ctx = C._goboringcrypto_EVP_PKEY_CTX_new(pkey, nil)
 	if ctx == nil {
@@ -161,6 +225,16 @@ func setupRSA(withKey func(func(*C.GO_RSA) C.int) C.int,
 		if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_oaep_md(ctx, md) == 0 {
 			return nil, nil, NewOpenSSLError("EVP_PKEY_set_rsa_oaep_md failed")
 		}
+		// MGF1 defaults to the OAEP hash.
+		if mgfHash != nil {
+			mgfMD := hashToMD(mgfHash)
+			if mgfMD == nil {
+				return nil, nil, errors.New("crypto/rsa: unsupported hash function")
+			}
+			if C._goboringcrypto_EVP_PKEY_CTX_set_rsa_mgf1_md(ctx, mgfMD) == 0 {
+				return nil, nil, NewOpenSSLError("EVP_PKEY_set_rsa_mgf1_md failed")
+			}
+		}
 		// ctx takes ownership of label, so malloc a copy for BoringCrypto to free.
 		clabel := (*C.uint8_t)(C.malloc(C.size_t(len(label))))
 		if clabel == nil {
@@ -189,13 +263,13 @@ func setupRSA(withKey func(func(*C.GO_RSA) C.int) C.int,
 	return pkey, ctx, nil
 }
 
-func cryptRSA(withKey func(func(*C.GO_RSA) C.int) C.int,
-	padding C.int, h hash.Hash, label []byte, saltLen int, ch crypto.Hash,
+func cryptRSA(newPKey func() (*C.GO_EVP_PKEY, error),
+	padding C.int, h, mgfHash hash.Hash, label []byte, saltLen int, ch crypto.Hash,
 	init func(*C.GO_EVP_PKEY_CTX) C.int,
 	crypt func(*C.GO_EVP_PKEY_CTX, *C.uint8_t, *C.uint, *C.uint8_t, C.uint) C.int,
 	in []byte) ([]byte, error) {
 
-	pkey, ctx, err := setupRSA(withKey, padding, h, label, saltLen, ch, init)
+	pkey, ctx, err := setupRSA(newPKey, padding, h, mgfHash, label, saltLen, ch, init)
 	if err != nil {
 		return nil, err
 	}
@@ -213,28 +287,32 @@ func cryptRSA(withKey func(func(*C.GO_RSA) C.int) C.int,
 	return out[:outLen], nil
 }
 
-func DecryptRSAOAEP(h hash.Hash, priv *PrivateKeyRSA, ciphertext, label []byte) ([]byte, error) {
-	return cryptRSA(priv.withKey, C.GO_RSA_PKCS1_OAEP_PADDING, h, label, 0, 0, decryptInit, decrypt, ciphertext)
+// DecryptRSAOAEP decrypts ciphertext with RSA-OAEP, using h for the label
+// digest and mgfHash for MGF1. If mgfHash is nil, h is used for both.
+func DecryptRSAOAEP(h, mgfHash hash.Hash, priv *PrivateKeyRSA, ciphertext, label []byte) ([]byte, error) {
+	return cryptRSA(priv.newPKey, C.GO_RSA_PKCS1_OAEP_PADDING, h, mgfHash, label, 0, 0, decryptInit, decrypt, ciphertext)
 }
 
-func EncryptRSAOAEP(h hash.Hash, pub *PublicKeyRSA, msg, label []byte) ([]byte, error) {
-	return cryptRSA(pub.withKey, C.GO_RSA_PKCS1_OAEP_PADDING, h, label, 0, 0, encryptInit, encrypt, msg)
+// EncryptRSAOAEP encrypts msg with RSA-OAEP, using h for the label digest
+// and mgfHash for MGF1. If mgfHash is nil, h is used for both.
+func EncryptRSAOAEP(h, mgfHash hash.Hash, pub *PublicKeyRSA, msg, label []byte) ([]byte, error) {
+	return cryptRSA(pub.newPKey, C.GO_RSA_PKCS1_OAEP_PADDING, h, mgfHash, label, 0, 0, encryptInit, encrypt, msg)
 }
 
 func DecryptRSAPKCS1(priv *PrivateKeyRSA, ciphertext []byte) ([]byte, error) {
-	return cryptRSA(priv.withKey, C.GO_RSA_PKCS1_PADDING, nil, nil, 0, 0, decryptInit, decrypt, ciphertext)
+	return cryptRSA(priv.newPKey, C.GO_RSA_PKCS1_PADDING, nil, nil, nil, 0, 0, decryptInit, decrypt, ciphertext)
 }
 
 func EncryptRSAPKCS1(pub *PublicKeyRSA, msg []byte) ([]byte, error) {
-	return cryptRSA(pub.withKey, C.GO_RSA_PKCS1_PADDING, nil, nil, 0, 0, encryptInit, encrypt, msg)
+	return cryptRSA(pub.newPKey, C.GO_RSA_PKCS1_PADDING, nil, nil, nil, 0, 0, encryptInit, encrypt, msg)
 }
 
 func DecryptRSANoPadding(priv *PrivateKeyRSA, ciphertext []byte) ([]byte, error) {
-	return cryptRSA(priv.withKey, C.GO_RSA_NO_PADDING, nil, nil, 0, 0, decryptInit, decrypt, ciphertext)
+	return cryptRSA(priv.newPKey, C.GO_RSA_NO_PADDING, nil, nil, nil, 0, 0, decryptInit, decrypt, ciphertext)
 }
 
 func EncryptRSANoPadding(pub *PublicKeyRSA, msg []byte) ([]byte, error) {
-	return cryptRSA(pub.withKey, C.GO_RSA_NO_PADDING, nil, nil, 0, 0, encryptInit, encrypt, msg)
+	return cryptRSA(pub.newPKey, C.GO_RSA_NO_PADDING, nil, nil, nil, 0, 0, encryptInit, encrypt, msg)
 }
 
 // These dumb wrappers work around the fact that cgo functions cannot be used as values directly.
@@ -255,6 +333,9 @@ func encrypt(ctx *C.GO_EVP_PKEY_CTX, out *C.uint8_t, outLen *C.uint, in *C.uint8
 	return C._goboringcrypto_EVP_PKEY_encrypt(ctx, out, outLen, in, inLen)
 }
 
+// SignRSAPSS signs hashed, an h digest, with RSASSA-PSS through the
+// EVP_PKEY of priv, using MGF1 with h. A saltLen of 0 means a salt as
+// long as the digest.
 func SignRSAPSS(priv *PrivateKeyRSA, h crypto.Hash, hashed []byte, saltLen int) ([]byte, error) {
 	md := cryptoHashToMD(h)
 	if md == nil {
@@ -263,19 +344,16 @@ func SignRSAPSS(priv *PrivateKeyRSA, h crypto.Hash, hashed []byte, saltLen int)
 	if saltLen == 0 {
 		saltLen = -1
 	}
-	var out []byte
-	var outLen C.uint
-	if priv.withKey(func(key *C.GO_RSA) C.int {
-		out = make([]byte, C._goboringcrypto_RSA_size(key))
-		return C._goboringcrypto_RSA_sign_pss_mgf1(key, &outLen, base(out), C.uint(len(out)),
-			base(hashed), C.uint(len(hashed)), md, nil, C.int(saltLen))
-	}) == 0 {
-		return nil, fail("RSA_sign_pss_mgf1")
+	pkey, err := priv.newPKey()
+	if err != nil {
+		return nil, err
 	}
-
-	return out[:outLen], nil
+	defer C._goboringcrypto_EVP_PKEY_free(pkey)
+	return signPKey(pkey, md, hashed, C.GO_RSA_PKCS1_PSS_PADDING, saltLen)
 }
 
+// VerifyRSAPSS is the verifying counterpart of SignRSAPSS. A saltLen of
+// 0 means the salt length is recovered from the signature.
 func VerifyRSAPSS(pub *PublicKeyRSA, h crypto.Hash, hashed, sig []byte, saltLen int) error {
 	md := cryptoHashToMD(h)
 	if md == nil {
@@ -284,13 +362,12 @@ func VerifyRSAPSS(pub *PublicKeyRSA, h crypto.Hash, hashed, sig []byte, saltLen
 	if saltLen == 0 {
 		saltLen = -2 // auto-recover
 	}
-	if pub.withKey(func(key *C.GO_RSA) C.int {
-		return C._goboringcrypto_RSA_verify_pss_mgf1(key, base(hashed), C.uint(len(hashed)),
-			md, nil, C.int(saltLen), base(sig), C.uint(len(sig)))
-	}) == 0 {
-		return fail("RSA_verify_pss_mgf1")
+	pkey, err := pub.newPKey()
+	if err != nil {
+		return err
 	}
-	return nil
+	defer C._goboringcrypto_EVP_PKEY_free(pkey)
+	return verifyPKey(pkey, md, hashed, sig, C.GO_RSA_PKCS1_PSS_PADDING, saltLen)
 }
 
 func SignRSAPKCS1v15(priv *PrivateKeyRSA, h crypto.Hash, msg []byte, msgIsHashed bool) ([]byte, error) {
@@ -304,6 +381,10 @@ func SignRSAPKCS1v15(priv *PrivateKeyRSA, h crypto.Hash, msg []byte, msgIsHashed
 
 	if msgIsHashed {
 		PanicIfStrictFIPS("You must provide a raw unhashed message for PKCS1v15 signing and use HashSignPKCS1v15 instead of SignPKCS1v15")
+		if priv.pkey != nil {
+			defer runtime.KeepAlive(priv)
+			return signPKey(priv.pkey, md, msg, C.GO_RSA_PKCS1_PADDING, 0)
+		}
 		nid := C._goboringcrypto_EVP_MD_type(md)
 		if priv.withKey(func(key *C.GO_RSA) C.int {
 			out = make([]byte, C._goboringcrypto_RSA_size(key))
@@ -315,6 +396,14 @@ func SignRSAPKCS1v15(priv *PrivateKeyRSA, h crypto.Hash, msg []byte, msgIsHashed
 		return out[:outLen], nil
 	}
 
+	if priv.pkey != nil {
+		s, err := NewDigestSignerRSAPKCS1v15(priv, h)
+		if err != nil {
+			return nil, err
+		}
+		s.Write(msg)
+		return s.Sign()
+	}
 	if priv.withKey(func(key *C.GO_RSA) C.int {
 		return C._goboringcrypto_EVP_RSA_sign(md, base(msg), C.uint(len(msg)), base(out), &outLen, key)
 	}) == 0 {
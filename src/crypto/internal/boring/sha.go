@@ -15,424 +15,456 @@ import "C"
 import (
 	"errors"
 	"hash"
+	"runtime"
 	"unsafe"
 )
 
 // NewSHA1 returns a new SHA1 hash.
-func NewSHA1() hash.Hash {
-	h := new(sha1Hash)
-	h.Reset()
-	return h
-}
+func NewSHA1() hash.Hash { return newEVPHash(C._goboringcrypto_EVP_sha1(), sha1Marshaler) }
 
-type sha1Hash struct {
-	ctx C.GO_SHA_CTX
-	out [20]byte
-}
+// NewSHA224 returns a new SHA224 hash.
+func NewSHA224() hash.Hash { return newEVPHash(C._goboringcrypto_EVP_sha224(), sha224Marshaler) }
 
-type sha1Ctx struct {
-	h      [5]uint32
-	nl, nh uint32
-	x      [64]byte
-	nx     uint32
-}
+// NewSHA256 returns a new SHA256 hash.
+func NewSHA256() hash.Hash { return newEVPHash(C._goboringcrypto_EVP_sha256(), sha256Marshaler) }
 
-func (h *sha1Hash) Reset()               { C._goboringcrypto_SHA1_Init(&h.ctx) }
-func (h *sha1Hash) Size() int            { return 20 }
-func (h *sha1Hash) BlockSize() int       { return 64 }
-func (h *sha1Hash) Sum(in []byte) []byte { return append(in, h.sum()...) }
+// NewSHA384 returns a new SHA384 hash.
+func NewSHA384() hash.Hash { return newEVPHash(C._goboringcrypto_EVP_sha384(), sha384Marshaler) }
 
-func (h *sha1Hash) Write(p []byte) (int, error) {
-	if len(p) > 0 && C._goboringcrypto_SHA1_Update(&h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) == 0 {
-		panic("boringcrypto: SHA1_Update failed")
-	}
-	return len(p), nil
-}
+// NewSHA512 returns a new SHA512 hash.
+func NewSHA512() hash.Hash { return newEVPHash(C._goboringcrypto_EVP_sha512(), sha512Marshaler) }
 
-func (h0 *sha1Hash) sum() []byte {
-	h := *h0 // make copy so future Write+Sum is valid
-	if C._goboringcrypto_SHA1_Final((*C.uint8_t)(unsafe.Pointer(&h.out[0])), &h.ctx) == 0 {
-		panic("boringcrypto: SHA1_Final failed")
-	}
-	return h.out[:]
+// NewSHA512_224 returns a new SHA512/224 hash.
+func NewSHA512_224() hash.Hash {
+	return newSHA512Trunc(C._goboringcrypto_EVP_sha512_224(), sha512_224Marshaler)
 }
 
-const (
-	sha1Magic         = "sha\x01"
-	sha1MarshaledSize = len(sha1Magic) + 5*4 + 64 + 8
-)
-
-func (h *sha1Hash) MarshalBinary() ([]byte, error) {
-	d := (*sha1Ctx)(unsafe.Pointer(&h.ctx))
-	b := make([]byte, 0, sha1MarshaledSize)
-	b = append(b, sha1Magic...)
-	b = appendUint32(b, d.h[0])
-	b = appendUint32(b, d.h[1])
-	b = appendUint32(b, d.h[2])
-	b = appendUint32(b, d.h[3])
-	b = appendUint32(b, d.h[4])
-	b = append(b, d.x[:d.nx]...)
-	b = b[:len(b)+len(d.x)-int(d.nx)] // already zero
-	b = appendUint64(b, uint64(d.nl)>>3|uint64(d.nh)<<29)
-	return b, nil
+// NewSHA512_256 returns a new SHA512/256 hash.
+func NewSHA512_256() hash.Hash {
+	return newSHA512Trunc(C._goboringcrypto_EVP_sha512_256(), sha512_256Marshaler)
 }
 
-func (h *sha1Hash) UnmarshalBinary(b []byte) error {
-	if len(b) < len(sha1Magic) || string(b[:len(sha1Magic)]) != sha1Magic {
-		return errors.New("crypto/sha1: invalid hash state identifier")
+func newSHA512Trunc(md *C.GO_EVP_MD, m *hashMarshaler) hash.Hash {
+	if md == nil {
+		panic("boringcrypto: SHA-512/t not supported by this OpenSSL version")
 	}
-	if len(b) != sha1MarshaledSize {
-		return errors.New("crypto/sha1: invalid hash state size")
+	return newEVPHash(md, m)
+}
+
+// evpHash implements hash.Hash on top of an EVP_MD_CTX.
+// Any digest OpenSSL provides can be used with it;
+// see newEVPHash for how to make its state serializable.
+type evpHash struct {
+	md        *C.GO_EVP_MD
+	ctx       *C.GO_EVP_MD_CTX
+	ctx2      *C.GO_EVP_MD_CTX // scratch context for Sum
+	size      int
+	blockSize int
+	marshaler *hashMarshaler
+
+	// state is a copy of the digest state kept with the low-level
+	// SHA functions of marshaler, for libraries whose EVP_MD_CTX does
+	// not expose it (OpenSSL 3). After UnmarshalBinary, ctx cannot
+	// follow and the hash continues on state alone (detached).
+	state    unsafe.Pointer
+	detached bool
+}
+
+// newEVPHash returns a hash computing md. If m is not nil, the hash
+// implements encoding.BinaryMarshaler and encoding.BinaryUnmarshaler
+// using the state format described by m.
+func newEVPHash(md *C.GO_EVP_MD, m *hashMarshaler) *evpHash {
+	h := &evpHash{
+		md:        md,
+		size:      int(C._goboringcrypto_internal_EVP_MD_size(md)),
+		blockSize: int(C._goboringcrypto_EVP_MD_block_size(md)),
+		marshaler: m,
 	}
-	d := (*sha1Ctx)(unsafe.Pointer(&h.ctx))
-	b = b[len(sha1Magic):]
-	b, d.h[0] = consumeUint32(b)
-	b, d.h[1] = consumeUint32(b)
-	b, d.h[2] = consumeUint32(b)
-	b, d.h[3] = consumeUint32(b)
-	b, d.h[4] = consumeUint32(b)
-	b = b[copy(d.x[:], b):]
-	b, n := consumeUint64(b)
-	d.nl = uint32(n << 3)
-	d.nh = uint32(n >> 29)
-	d.nx = uint32(n) % 64
-	return nil
-}
-
-// NewSHA224 returns a new SHA224 hash.
-func NewSHA224() hash.Hash {
-	h := new(sha224Hash)
+	h.ctx = C._goboringcrypto_EVP_MD_CTX_create()
+	h.ctx2 = C._goboringcrypto_EVP_MD_CTX_create()
+	if h.ctx == nil || h.ctx2 == nil {
+		h.finalize()
+		panic("boringcrypto: EVP_MD_CTX_create failed")
+	}
+	// Note: Because of the finalizer, any time h.ctx or h.ctx2 is passed
+	// to cgo, that call must be followed by a call to runtime.KeepAlive(h),
+	// to make sure h is not collected (and finalized) before the cgo
+	// call returns.
+	runtime.SetFinalizer(h, (*evpHash).finalize)
 	h.Reset()
+	if m != nil && C._goboringcrypto_EVP_MD_CTX_md_data(h.ctx) == nil {
+		h.state = C.malloc(m.stateSize)
+		if h.state == nil {
+			panic("boringcrypto: malloc failed")
+		}
+		m.init(h.state)
+	}
 	return h
 }
 
-type sha224Hash struct {
-	ctx C.GO_SHA256_CTX
-	out [224 / 8]byte
+func (h *evpHash) finalize() {
+	if h.ctx != nil {
+		C._goboringcrypto_EVP_MD_CTX_free(h.ctx)
+	}
+	if h.ctx2 != nil {
+		C._goboringcrypto_EVP_MD_CTX_free(h.ctx2)
+	}
+	if h.state != nil {
+		C.free(h.state)
+	}
 }
 
-func (h *sha224Hash) Reset()               { C._goboringcrypto_SHA224_Init(&h.ctx) }
-func (h *sha224Hash) Size() int            { return 224 / 8 }
-func (h *sha224Hash) BlockSize() int       { return 64 }
-func (h *sha224Hash) Sum(in []byte) []byte { return append(in, h.sum()...) }
+func (h *evpHash) Reset() {
+	if C._goboringcrypto_EVP_DigestInit_ex(h.ctx, h.md, nil) != 1 {
+		panic("boringcrypto: EVP_DigestInit_ex failed")
+	}
+	if h.state != nil {
+		h.marshaler.init(h.state)
+		h.detached = false
+	}
+	runtime.KeepAlive(h)
+}
 
-func (h *sha224Hash) Write(p []byte) (int, error) {
-	if len(p) > 0 && C._goboringcrypto_SHA224_Update(&h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) == 0 {
-		panic("boringcrypto: SHA224_Update failed")
+func (h *evpHash) Size() int      { return h.size }
+func (h *evpHash) BlockSize() int { return h.blockSize }
+
+func (h *evpHash) Write(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if !h.detached && C._goboringcrypto_EVP_DigestUpdate(h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) != 1 {
+		panic("boringcrypto: EVP_DigestUpdate failed")
+	}
+	if h.state != nil {
+		h.marshaler.update(h.state, p)
 	}
+	runtime.KeepAlive(h)
 	return len(p), nil
 }
 
-func (h0 *sha224Hash) sum() []byte {
-	h := *h0 // make copy so future Write+Sum is valid
-	if C._goboringcrypto_SHA224_Final((*C.uint8_t)(unsafe.Pointer(&h.out[0])), &h.ctx) == 0 {
-		panic("boringcrypto: SHA224_Final failed")
+// copyCtx copies the running state into h.ctx2 so that it can be
+// finalized without affecting future writes to h.
+func (h *evpHash) copyCtx() {
+	if C._goboringcrypto_EVP_MD_CTX_copy_ex(h.ctx2, h.ctx) != 1 {
+		panic("boringcrypto: EVP_MD_CTX_copy_ex failed")
 	}
-	return h.out[:]
 }
 
-// NewSHA256 returns a new SHA256 hash.
-func NewSHA256() hash.Hash {
-	h := new(sha256Hash)
-	h.Reset()
-	return h
+func (h *evpHash) Sum(in []byte) []byte {
+	if h.detached {
+		return append(in, h.sumState()...)
+	}
+	out := make([]byte, h.size)
+	h.copyCtx()
+	if C._goboringcrypto_EVP_DigestFinal_ex(h.ctx2, base(out), nil) != 1 {
+		panic("boringcrypto: EVP_DigestFinal_ex failed")
+	}
+	runtime.KeepAlive(h)
+	return append(in, out...)
 }
 
-type sha256Hash struct {
-	ctx C.GO_SHA256_CTX
-	out [256 / 8]byte
+// sumState finalizes a copy of h.state, for a detached hash.
+func (h *evpHash) sumState() []byte {
+	m := h.marshaler
+	state := C.malloc(m.stateSize)
+	if state == nil {
+		panic("boringcrypto: malloc failed")
+	}
+	defer C.free(state)
+	copyState(state, h.state, m.stateSize)
+	var out [64]byte
+	m.final(out[:], state)
+	runtime.KeepAlive(h)
+	return out[:h.size]
 }
 
-func (h *sha256Hash) Reset()               { C._goboringcrypto_SHA256_Init(&h.ctx) }
-func (h *sha256Hash) Size() int            { return 256 / 8 }
-func (h *sha256Hash) BlockSize() int       { return 64 }
-func (h *sha256Hash) Sum(in []byte) []byte { return append(in, h.sum()...) }
-
-func (h *sha256Hash) Write(p []byte) (int, error) {
-	if len(p) > 0 && C._goboringcrypto_SHA256_Update(&h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) == 0 {
-		panic("boringcrypto: SHA256_Update failed")
-	}
-	return len(p), nil
+func copyState(dst, src unsafe.Pointer, n C.size_t) {
+	copy((*[1 << 10]byte)(dst)[:n:n], (*[1 << 10]byte)(src)[:n:n])
 }
 
-func (h0 *sha256Hash) sum() []byte {
-	h := *h0 // make copy so future Write+Sum is valid
-	if C._goboringcrypto_SHA256_Final((*C.uint8_t)(unsafe.Pointer(&h.out[0])), &h.ctx) == 0 {
-		panic("boringcrypto: SHA256_Final failed")
-	}
-	return h.out[:]
+// Clone returns a new hash with the same running state as h, copied with
+// EVP_MD_CTX_copy_ex, so that callers can fork a hash without writing
+// its input again. It works for every digest, unlike MarshalBinary.
+func (h *evpHash) Clone() hash.Hash {
+	return h.clone()
 }
 
-const (
-	magic224         = "sha\x02"
-	magic256         = "sha\x03"
-	marshaledSize256 = len(magic256) + 8*4 + 64 + 8
+func (h *evpHash) clone() *evpHash {
+	c := newEVPHash(h.md, h.marshaler)
+	c.size = h.size
+	if C._goboringcrypto_EVP_MD_CTX_copy_ex(c.ctx, h.ctx) != 1 {
+		panic("boringcrypto: EVP_MD_CTX_copy_ex failed")
+	}
+	if h.state != nil {
+		copyState(c.state, h.state, h.marshaler.stateSize)
+		c.detached = h.detached
+	}
+	runtime.KeepAlive(h)
+	runtime.KeepAlive(c)
+	return c
+}
+
+// A hashMarshaler describes how to serialize the state of a digest.
+//
+// The format is the one used by the Go implementation of the same
+// digest (a magic string, the chaining values, the partial block and
+// the message length), so a state marshaled here can be unmarshaled
+// by, say, crypto/sha256 with BoringCrypto disabled, and vice versa.
+// The OpenSSL side is read from the digest's internal state (see
+// _goboringcrypto_EVP_MD_CTX_md_data in goopenssl.h), whose layout is
+// the public SHA_CTX, SHA256_CTX or SHA512_CTX from openssl/sha.h.
+// OpenSSL 3 does not expose the state of its provider digests, so there
+// evpHash keeps the same structure up to date with the low-level
+// functions below, at the cost of hashing the input twice.
+type hashMarshaler struct {
+	pkg       string // package name for error messages
+	magic     string
+	size      int // size of the marshaled state, including magic
+	marshal   func(b []byte, state unsafe.Pointer) []byte
+	unmarshal func(b []byte, state unsafe.Pointer)
+
+	stateSize C.size_t
+	init      func(state unsafe.Pointer)
+	update    func(state unsafe.Pointer, p []byte)
+	final     func(out []byte, state unsafe.Pointer) // out has room for 64 bytes
+}
+
+var (
+	sha1Marshaler = &hashMarshaler{"crypto/sha1", "sha\x01", marshaledSize1, marshalSHA1, unmarshalSHA1,
+		C.sizeof_GO_SHA_CTX, initSHA1, updateSHA1, finalSHA1}
+	sha224Marshaler = &hashMarshaler{"crypto/sha256", "sha\x02", marshaledSize256, marshalSHA256, unmarshalSHA256,
+		C.sizeof_SHA256_CTX, initSHA224, updateSHA256, finalSHA256}
+	sha256Marshaler = &hashMarshaler{"crypto/sha256", "sha\x03", marshaledSize256, marshalSHA256, unmarshalSHA256,
+		C.sizeof_SHA256_CTX, initSHA256, updateSHA256, finalSHA256}
+	sha384Marshaler = &hashMarshaler{"crypto/sha512", "sha\x04", marshaledSize512, marshalSHA512, unmarshalSHA512,
+		C.sizeof_SHA512_CTX, initSHA384, updateSHA512, finalSHA512}
+	sha512_224Marshaler = &hashMarshaler{"crypto/sha512", "sha\x05", marshaledSize512, marshalSHA512, unmarshalSHA512,
+		C.sizeof_SHA512_CTX, initSHA512_224, updateSHA512, finalSHA512}
+	sha512_256Marshaler = &hashMarshaler{"crypto/sha512", "sha\x06", marshaledSize512, marshalSHA512, unmarshalSHA512,
+		C.sizeof_SHA512_CTX, initSHA512_256, updateSHA512, finalSHA512}
+	sha512Marshaler = &hashMarshaler{"crypto/sha512", "sha\x07", marshaledSize512, marshalSHA512, unmarshalSHA512,
+		C.sizeof_SHA512_CTX, initSHA512, updateSHA512, finalSHA512}
 )
 
-type sha256Ctx struct {
-	h      [8]uint32
-	nl, nh uint32
-	x      [64]byte
-	nx     uint32
-}
-
-func (h *sha224Hash) MarshalBinary() ([]byte, error) {
-	d := (*sha256Ctx)(unsafe.Pointer(&h.ctx))
-	b := make([]byte, 0, marshaledSize256)
-	b = append(b, magic224...)
-	b = appendUint32(b, d.h[0])
-	b = appendUint32(b, d.h[1])
-	b = appendUint32(b, d.h[2])
-	b = appendUint32(b, d.h[3])
-	b = appendUint32(b, d.h[4])
-	b = appendUint32(b, d.h[5])
-	b = appendUint32(b, d.h[6])
-	b = appendUint32(b, d.h[7])
-	b = append(b, d.x[:d.nx]...)
-	b = b[:len(b)+len(d.x)-int(d.nx)] // already zero
-	b = appendUint64(b, uint64(d.nl)>>3|uint64(d.nh)<<29)
-	return b, nil
+// digestState returns the structure that MarshalBinary and
+// UnmarshalBinary operate on.
+func (h *evpHash) digestState() unsafe.Pointer {
+	if h.state != nil {
+		return h.state
+	}
+	return C._goboringcrypto_EVP_MD_CTX_md_data(h.ctx)
 }
 
-func (h *sha256Hash) MarshalBinary() ([]byte, error) {
-	d := (*sha256Ctx)(unsafe.Pointer(&h.ctx))
-	b := make([]byte, 0, marshaledSize256)
-	b = append(b, magic256...)
-	b = appendUint32(b, d.h[0])
-	b = appendUint32(b, d.h[1])
-	b = appendUint32(b, d.h[2])
-	b = appendUint32(b, d.h[3])
-	b = appendUint32(b, d.h[4])
-	b = appendUint32(b, d.h[5])
-	b = appendUint32(b, d.h[6])
-	b = appendUint32(b, d.h[7])
-	b = append(b, d.x[:d.nx]...)
-	b = b[:len(b)+len(d.x)-int(d.nx)] // already zero
-	b = appendUint64(b, uint64(d.nl)>>3|uint64(d.nh)<<29)
+func (h *evpHash) MarshalBinary() ([]byte, error) {
+	m := h.marshaler
+	if m == nil {
+		return nil, errors.New("boringcrypto: hash state cannot be marshaled")
+	}
+	state := h.digestState()
+	if state == nil {
+		return nil, errors.New(m.pkg + ": hash state not available")
+	}
+	b := make([]byte, 0, m.size)
+	b = append(b, m.magic...)
+	b = m.marshal(b, state)
+	runtime.KeepAlive(h)
 	return b, nil
 }
 
-func (h *sha224Hash) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic224) || string(b[:len(magic224)]) != magic224 {
-		return errors.New("crypto/sha256: invalid hash state identifier")
+func (h *evpHash) UnmarshalBinary(b []byte) error {
+	m := h.marshaler
+	if m == nil {
+		return errors.New("boringcrypto: hash state cannot be unmarshaled")
 	}
-	if len(b) != marshaledSize256 {
-		return errors.New("crypto/sha256: invalid hash state size")
+	if len(b) < len(m.magic) || string(b[:len(m.magic)]) != m.magic {
+		return errors.New(m.pkg + ": invalid hash state identifier")
 	}
-	d := (*sha256Ctx)(unsafe.Pointer(&h.ctx))
-	b = b[len(magic224):]
-	b, d.h[0] = consumeUint32(b)
-	b, d.h[1] = consumeUint32(b)
-	b, d.h[2] = consumeUint32(b)
-	b, d.h[3] = consumeUint32(b)
-	b, d.h[4] = consumeUint32(b)
-	b, d.h[5] = consumeUint32(b)
-	b, d.h[6] = consumeUint32(b)
-	b, d.h[7] = consumeUint32(b)
-	b = b[copy(d.x[:], b):]
-	b, n := consumeUint64(b)
-	d.nl = uint32(n << 3)
-	d.nh = uint32(n >> 29)
-	d.nx = uint32(n) % 64
+	if len(b) != m.size {
+		return errors.New(m.pkg + ": invalid hash state size")
+	}
+	state := h.digestState()
+	if state == nil {
+		return errors.New(m.pkg + ": hash state not available")
+	}
+	m.unmarshal(b[len(m.magic):], state)
+	if h.state != nil {
+		h.detached = true
+	}
+	runtime.KeepAlive(h)
 	return nil
 }
 
-func (h *sha256Hash) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic256) || string(b[:len(magic256)]) != magic256 {
-		return errors.New("crypto/sha256: invalid hash state identifier")
+// The digest states below are the public SHA_CTX, SHA256_CTX and
+// SHA512_CTX structures of openssl/sha.h, accessed through cgo so that
+// their layout comes from the headers in use.
+
+const marshaledSize1 = len("sha\x01") + 5*4 + 64 + 8
+
+func marshalSHA1(b []byte, state unsafe.Pointer) []byte {
+	d := (*C.GO_SHA_CTX)(state)
+	x := (*[64]byte)(unsafe.Pointer(&d.data[0]))
+	b = appendUint32(b, uint32(d.h0))
+	b = appendUint32(b, uint32(d.h1))
+	b = appendUint32(b, uint32(d.h2))
+	b = appendUint32(b, uint32(d.h3))
+	b = appendUint32(b, uint32(d.h4))
+	b = append(b, x[:d.num]...)
+	b = b[:len(b)+len(x)-int(d.num)] // already zero
+	b = appendUint64(b, uint64(d.Nl)>>3|uint64(d.Nh)<<29)
+	return b
+}
+
+func unmarshalSHA1(b []byte, state unsafe.Pointer) {
+	d := (*C.GO_SHA_CTX)(state)
+	x := (*[64]byte)(unsafe.Pointer(&d.data[0]))
+	var v uint32
+	b, v = consumeUint32(b)
+	d.h0 = C.SHA_LONG(v)
+	b, v = consumeUint32(b)
+	d.h1 = C.SHA_LONG(v)
+	b, v = consumeUint32(b)
+	d.h2 = C.SHA_LONG(v)
+	b, v = consumeUint32(b)
+	d.h3 = C.SHA_LONG(v)
+	b, v = consumeUint32(b)
+	d.h4 = C.SHA_LONG(v)
+	b = b[copy(x[:], b):]
+	b, n := consumeUint64(b)
+	d.Nl = C.SHA_LONG(n << 3)
+	d.Nh = C.SHA_LONG(n >> 29)
+	d.num = C.uint(n % 64)
+}
+
+const marshaledSize256 = len("sha\x03") + 8*4 + 64 + 8
+
+// marshalSHA256 is used for SHA-224 and SHA-256, which share SHA256_CTX.
+func marshalSHA256(b []byte, state unsafe.Pointer) []byte {
+	d := (*C.SHA256_CTX)(state)
+	x := (*[64]byte)(unsafe.Pointer(&d.data[0]))
+	for _, h := range d.h {
+		b = appendUint32(b, uint32(h))
 	}
-	if len(b) != marshaledSize256 {
-		return errors.New("crypto/sha256: invalid hash state size")
+	b = append(b, x[:d.num]...)
+	b = b[:len(b)+len(x)-int(d.num)] // already zero
+	b = appendUint64(b, uint64(d.Nl)>>3|uint64(d.Nh)<<29)
+	return b
+}
+
+func unmarshalSHA256(b []byte, state unsafe.Pointer) {
+	d := (*C.SHA256_CTX)(state)
+	x := (*[64]byte)(unsafe.Pointer(&d.data[0]))
+	for i := range d.h {
+		var v uint32
+		b, v = consumeUint32(b)
+		d.h[i] = C.SHA_LONG(v)
 	}
-	d := (*sha256Ctx)(unsafe.Pointer(&h.ctx))
-	b = b[len(magic256):]
-	b, d.h[0] = consumeUint32(b)
-	b, d.h[1] = consumeUint32(b)
-	b, d.h[2] = consumeUint32(b)
-	b, d.h[3] = consumeUint32(b)
-	b, d.h[4] = consumeUint32(b)
-	b, d.h[5] = consumeUint32(b)
-	b, d.h[6] = consumeUint32(b)
-	b, d.h[7] = consumeUint32(b)
-	b = b[copy(d.x[:], b):]
+	b = b[copy(x[:], b):]
 	b, n := consumeUint64(b)
-	d.nl = uint32(n << 3)
-	d.nh = uint32(n >> 29)
-	d.nx = uint32(n) % 64
-	return nil
+	d.Nl = C.SHA_LONG(n << 3)
+	d.Nh = C.SHA_LONG(n >> 29)
+	d.num = C.uint(n % 64)
 }
 
-// NewSHA384 returns a new SHA384 hash.
-func NewSHA384() hash.Hash {
-	h := new(sha384Hash)
-	h.Reset()
-	return h
-}
+const marshaledSize512 = len("sha\x07") + 8*8 + 128 + 8
 
-type sha384Hash struct {
-	ctx C.GO_SHA512_CTX
-	out [384 / 8]byte
+// marshalSHA512 is used for SHA-384, SHA-512 and the truncated SHA-512/t
+// variants, which share SHA512_CTX.
+func marshalSHA512(b []byte, state unsafe.Pointer) []byte {
+	d := (*C.SHA512_CTX)(state)
+	x := (*[128]byte)(unsafe.Pointer(&d.u))
+	for _, h := range d.h {
+		b = appendUint64(b, uint64(h))
+	}
+	b = append(b, x[:d.num]...)
+	b = b[:len(b)+len(x)-int(d.num)] // already zero
+	b = appendUint64(b, uint64(d.Nl)>>3|uint64(d.Nh)<<61)
+	return b
+}
+
+func unmarshalSHA512(b []byte, state unsafe.Pointer) {
+	d := (*C.SHA512_CTX)(state)
+	x := (*[128]byte)(unsafe.Pointer(&d.u))
+	for i := range d.h {
+		var v uint64
+		b, v = consumeUint64(b)
+		d.h[i] = C.SHA_LONG64(v)
+	}
+	b = b[copy(x[:], b):]
+	b, n := consumeUint64(b)
+	d.Nl = C.SHA_LONG64(n << 3)
+	d.Nh = C.SHA_LONG64(n >> 61)
+	d.num = C.uint(n % 128)
 }
 
-func (h *sha384Hash) Reset()               { C._goboringcrypto_SHA384_Init(&h.ctx) }
-func (h *sha384Hash) Size() int            { return 384 / 8 }
-func (h *sha384Hash) BlockSize() int       { return 128 }
-func (h *sha384Hash) Sum(in []byte) []byte { return append(in, h.sum()...) }
+func initSHA1(state unsafe.Pointer) {
+	C._goboringcrypto_SHA1_Init((*C.GO_SHA_CTX)(state))
+}
 
-func (h *sha384Hash) Write(p []byte) (int, error) {
-	if len(p) > 0 && C._goboringcrypto_SHA384_Update(&h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) == 0 {
-		panic("boringcrypto: SHA384_Update failed")
-	}
-	return len(p), nil
+func updateSHA1(state unsafe.Pointer, p []byte) {
+	C._goboringcrypto_SHA1_Update((*C.GO_SHA_CTX)(state), unsafe.Pointer(&p[0]), C.size_t(len(p)))
 }
 
-func (h0 *sha384Hash) sum() []byte {
-	h := *h0 // make copy so future Write+Sum is valid
-	if C._goboringcrypto_SHA384_Final((*C.uint8_t)(unsafe.Pointer(&h.out[0])), &h.ctx) == 0 {
-		panic("boringcrypto: SHA384_Final failed")
-	}
-	return h.out[:]
+func finalSHA1(out []byte, state unsafe.Pointer) {
+	C._goboringcrypto_SHA1_Final(base(out), (*C.GO_SHA_CTX)(state))
 }
 
-// NewSHA512 returns a new SHA512 hash.
-func NewSHA512() hash.Hash {
-	h := new(sha512Hash)
-	h.Reset()
-	return h
+func initSHA224(state unsafe.Pointer) {
+	C._goboringcrypto_SHA224_Init((*C.SHA256_CTX)(state))
 }
 
-type sha512Hash struct {
-	ctx C.GO_SHA512_CTX
-	out [512 / 8]byte
+func initSHA256(state unsafe.Pointer) {
+	C._goboringcrypto_SHA256_Init((*C.SHA256_CTX)(state))
 }
 
-func (h *sha512Hash) Reset()               { C._goboringcrypto_SHA512_Init(&h.ctx) }
-func (h *sha512Hash) Size() int            { return 512 / 8 }
-func (h *sha512Hash) BlockSize() int       { return 128 }
-func (h *sha512Hash) Sum(in []byte) []byte { return append(in, h.sum()...) }
+func updateSHA256(state unsafe.Pointer, p []byte) {
+	C._goboringcrypto_SHA256_Update((*C.SHA256_CTX)(state), unsafe.Pointer(&p[0]), C.size_t(len(p)))
+}
 
-func (h *sha512Hash) Write(p []byte) (int, error) {
-	if len(p) > 0 && C._goboringcrypto_SHA512_Update(&h.ctx, unsafe.Pointer(&p[0]), C.size_t(len(p))) == 0 {
-		panic("boringcrypto: SHA512_Update failed")
-	}
-	return len(p), nil
+func finalSHA256(out []byte, state unsafe.Pointer) {
+	C._goboringcrypto_SHA256_Final(base(out), (*C.SHA256_CTX)(state))
 }
 
-func (h0 *sha512Hash) sum() []byte {
-	h := *h0 // make copy so future Write+Sum is valid
-	if C._goboringcrypto_SHA512_Final((*C.uint8_t)(unsafe.Pointer(&h.out[0])), &h.ctx) == 0 {
-		panic("boringcrypto: SHA512_Final failed")
-	}
-	return h.out[:]
+func initSHA384(state unsafe.Pointer) {
+	C._goboringcrypto_SHA384_Init((*C.SHA512_CTX)(state))
 }
 
-type sha512Ctx struct {
-	h      [8]uint64
-	nl, nh uint64
-	x      [128]byte
-	nx     uint32
+func initSHA512(state unsafe.Pointer) {
+	C._goboringcrypto_SHA512_Init((*C.SHA512_CTX)(state))
 }
 
-const (
-	magic384         = "sha\x04"
-	magic512_224     = "sha\x05"
-	magic512_256     = "sha\x06"
-	magic512         = "sha\x07"
-	marshaledSize512 = len(magic512) + 8*8 + 128 + 8
-)
+// There are no low-level SHA512_224_Init and SHA512_256_Init, so the
+// SHA-512/t states start as SHA-512 with the initial values of FIPS
+// 180-4, section 5.3.6. Their output is read from a full SHA-512 final
+// block, which is truncated by Sum.
 
-var zero [128]byte
-
-func (h *sha384Hash) MarshalBinary() ([]byte, error) {
-	d := (*sha512Ctx)(unsafe.Pointer(&h.ctx))
-	b := make([]byte, 0, marshaledSize512)
-	b = append(b, magic384...)
-	b = appendUint64(b, d.h[0])
-	b = appendUint64(b, d.h[1])
-	b = appendUint64(b, d.h[2])
-	b = appendUint64(b, d.h[3])
-	b = appendUint64(b, d.h[4])
-	b = appendUint64(b, d.h[5])
-	b = appendUint64(b, d.h[6])
-	b = appendUint64(b, d.h[7])
-	b = append(b, d.x[:d.nx]...)
-	b = b[:len(b)+len(d.x)-int(d.nx)] // already zero
-	b = appendUint64(b, d.nl>>3|d.nh<<61)
-	return b, nil
+func initSHA512_224(state unsafe.Pointer) {
+	initSHA512t(state, &[8]uint64{
+		0x8c3d37c819544da2, 0x73e1996689dcd4d6, 0x1dfab7ae32ff9c82, 0x679dd514582f9fcf,
+		0x0f6d2b697bd44da8, 0x77e36f7304c48942, 0x3f9d85a86a1d36c8, 0x1112e6ad91d692a1,
+	})
 }
 
-func (h *sha512Hash) MarshalBinary() ([]byte, error) {
-	d := (*sha512Ctx)(unsafe.Pointer(&h.ctx))
-	b := make([]byte, 0, marshaledSize512)
-	b = append(b, magic512...)
-	b = appendUint64(b, d.h[0])
-	b = appendUint64(b, d.h[1])
-	b = appendUint64(b, d.h[2])
-	b = appendUint64(b, d.h[3])
-	b = appendUint64(b, d.h[4])
-	b = appendUint64(b, d.h[5])
-	b = appendUint64(b, d.h[6])
-	b = appendUint64(b, d.h[7])
-	b = append(b, d.x[:d.nx]...)
-	b = b[:len(b)+len(d.x)-int(d.nx)] // already zero
-	b = appendUint64(b, d.nl>>3|d.nh<<61)
-	return b, nil
+func initSHA512_256(state unsafe.Pointer) {
+	initSHA512t(state, &[8]uint64{
+		0x22312194fc2bf72c, 0x9f555fa3c84c64c2, 0x2393b86b6f53b151, 0x963877195940eabd,
+		0x96283ee2a88effe3, 0xbe5e1e2553863992, 0x2b0199fc2c85b8aa, 0x0eb72ddc81c52ca2,
+	})
 }
 
-func (h *sha384Hash) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic512) {
-		return errors.New("crypto/sha512: invalid hash state identifier")
+func initSHA512t(state unsafe.Pointer, iv *[8]uint64) {
+	d := (*C.SHA512_CTX)(state)
+	C._goboringcrypto_SHA512_Init(d)
+	for i, v := range iv {
+		d.h[i] = C.SHA_LONG64(v)
 	}
-	if string(b[:len(magic384)]) != magic384 {
-		return errors.New("crypto/sha512: invalid hash state identifier")
-	}
-	if len(b) != marshaledSize512 {
-		return errors.New("crypto/sha512: invalid hash state size")
-	}
-	d := (*sha512Ctx)(unsafe.Pointer(&h.ctx))
-	b = b[len(magic512):]
-	b, d.h[0] = consumeUint64(b)
-	b, d.h[1] = consumeUint64(b)
-	b, d.h[2] = consumeUint64(b)
-	b, d.h[3] = consumeUint64(b)
-	b, d.h[4] = consumeUint64(b)
-	b, d.h[5] = consumeUint64(b)
-	b, d.h[6] = consumeUint64(b)
-	b, d.h[7] = consumeUint64(b)
-	b = b[copy(d.x[:], b):]
-	b, n := consumeUint64(b)
-	d.nl = n << 3
-	d.nh = n >> 61
-	d.nx = uint32(n) % 128
-	return nil
 }
 
-func (h *sha512Hash) UnmarshalBinary(b []byte) error {
-	if len(b) < len(magic512) {
-		return errors.New("crypto/sha512: invalid hash state identifier")
-	}
-	if string(b[:len(magic512)]) != magic512 {
-		return errors.New("crypto/sha512: invalid hash state identifier")
-	}
-	if len(b) != marshaledSize512 {
-		return errors.New("crypto/sha512: invalid hash state size")
-	}
-	d := (*sha512Ctx)(unsafe.Pointer(&h.ctx))
-	b = b[len(magic512):]
-	b, d.h[0] = consumeUint64(b)
-	b, d.h[1] = consumeUint64(b)
-	b, d.h[2] = consumeUint64(b)
-	b, d.h[3] = consumeUint64(b)
-	b, d.h[4] = consumeUint64(b)
-	b, d.h[5] = consumeUint64(b)
-	b, d.h[6] = consumeUint64(b)
-	b, d.h[7] = consumeUint64(b)
-	b = b[copy(d.x[:], b):]
-	b, n := consumeUint64(b)
-	d.nl = n << 3
-	d.nh = n >> 61
-	d.nx = uint32(n) % 128
-	return nil
+func updateSHA512(state unsafe.Pointer, p []byte) {
+	C._goboringcrypto_SHA512_Update((*C.SHA512_CTX)(state), unsafe.Pointer(&p[0]), C.size_t(len(p)))
+}
+
+func finalSHA512(out []byte, state unsafe.Pointer) {
+	C._goboringcrypto_SHA512_Final(base(out), (*C.SHA512_CTX)(state))
 }
 
 func appendUint64(b []byte, x uint64) []byte {
@@ -0,0 +1,125 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"hash"
+	"runtime"
+)
+
+func newSHA3(md *C.GO_EVP_MD) hash.Hash {
+	if md == nil {
+		panic("boringcrypto: SHA-3 not supported by this OpenSSL version")
+	}
+	return newEVPHash(md, nil)
+}
+
+// NewSHA3_224 returns a new SHA3-224 hash.
+func NewSHA3_224() hash.Hash { return newSHA3(C._goboringcrypto_EVP_sha3_224()) }
+
+// NewSHA3_256 returns a new SHA3-256 hash.
+func NewSHA3_256() hash.Hash { return newSHA3(C._goboringcrypto_EVP_sha3_256()) }
+
+// NewSHA3_384 returns a new SHA3-384 hash.
+func NewSHA3_384() hash.Hash { return newSHA3(C._goboringcrypto_EVP_sha3_384()) }
+
+// NewSHA3_512 returns a new SHA3-512 hash.
+func NewSHA3_512() hash.Hash { return newSHA3(C._goboringcrypto_EVP_sha3_512()) }
+
+// NewSHAKE128 returns a new SHAKE128 extendable-output function.
+// Sum appends 32 bytes of output; the returned value also implements
+// io.Reader for arbitrary-length output.
+func NewSHAKE128() hash.Hash { return newSHAKE(C._goboringcrypto_EVP_shake128(), 32) }
+
+// NewSHAKE256 returns a new SHAKE256 extendable-output function.
+// Sum appends 64 bytes of output; the returned value also implements
+// io.Reader for arbitrary-length output.
+func NewSHAKE256() hash.Hash { return newSHAKE(C._goboringcrypto_EVP_shake256(), 64) }
+
+// SupportsSHAKE reports whether the OpenSSL library in use
+// implements the SHAKE extendable-output functions.
+func SupportsSHAKE() bool {
+	return C._goboringcrypto_EVP_shake128() != nil && C._goboringcrypto_EVP_shake256() != nil
+}
+
+type shakeHash struct {
+	*evpHash
+	n   int    // bytes already returned by Read
+	buf []byte // output squeezed but not yet returned by Read
+}
+
+func newSHAKE(md *C.GO_EVP_MD, size int) *shakeHash {
+	if md == nil {
+		panic("boringcrypto: SHAKE not supported by this OpenSSL version")
+	}
+	h := &shakeHash{evpHash: newEVPHash(md, nil)}
+	h.size = size
+	return h
+}
+
+func (h *shakeHash) Reset() {
+	h.evpHash.Reset()
+	h.n = 0
+	h.buf = nil
+}
+
+func (h *shakeHash) Write(p []byte) (int, error) {
+	if h.n > 0 {
+		panic("sha3: Write after Read")
+	}
+	return h.evpHash.Write(p)
+}
+
+func (h *shakeHash) Sum(in []byte) []byte {
+	if h.n > 0 {
+		panic("sha3: Sum after Read")
+	}
+	return append(in, h.squeeze(h.size)...)
+}
+
+// Clone returns a copy of h, which can be read from independently.
+func (h *shakeHash) Clone() hash.Hash {
+	return &shakeHash{evpHash: h.evpHash.clone(), n: h.n, buf: h.buf}
+}
+
+// Read squeezes more output from the function. OpenSSL can only finalize
+// an XOF once, so when the buffered output runs out Read recomputes the
+// output stream from a copy of the absorbing state. It squeezes at least
+// twice as much as before each time, so that streaming reads take time
+// linear in the total output.
+func (h *shakeHash) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	if len(h.buf) < len(p) {
+		total := h.n + len(p)
+		if min := 2 * (h.n + len(h.buf)); total < min {
+			total = min
+		}
+		h.buf = h.squeeze(total)[h.n:]
+	}
+	copy(p, h.buf)
+	h.buf = h.buf[len(p):]
+	h.n += len(p)
+	return len(p), nil
+}
+
+func (h *shakeHash) squeeze(n int) []byte {
+	out := make([]byte, n)
+	h.copyCtx()
+	if C._goboringcrypto_EVP_DigestFinalXOF(h.ctx2, base(out), C.size_t(n)) != 1 {
+		panic("boringcrypto: EVP_DigestFinalXOF failed")
+	}
+	runtime.KeepAlive(h)
+	return out
+}
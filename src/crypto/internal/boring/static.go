@@ -0,0 +1,20 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+// +build openssl_static
+
+package boring
+
+// With the openssl_static build tag, libcrypto.a is linked into the
+// binary instead of being loaded with dlopen at run time. The library
+// is found through the linker search path, for example with
+// CGO_LDFLAGS=-L/path/to/openssl/lib. It should be a validated build of
+// the same OpenSSL version as the headers used to compile this package.
+
+import _ "crypto/internal/boring/staticlink"
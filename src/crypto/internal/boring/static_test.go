@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+import (
+	"internal/testenv"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const staticSrc = `
+package main
+
+import (
+	"crypto/sha256"
+	"fmt"
+)
+
+func main() {
+	fmt.Printf("%x\n", sha256.Sum256([]byte("hello")))
+}
+`
+
+// Test that a program using the OpenSSL backend builds, links and runs
+// with the openssl_static build tag.
+func TestOpenSSLStatic(t *testing.T) {
+	testenv.MustHaveGoBuild(t)
+	testenv.MustHaveCGO(t)
+	if testing.Short() {
+		t.Skip("skipping in short mode")
+	}
+
+	cc := strings.Fields(goEnv(t, "CC"))
+	out, err := exec.Command(cc[0], append(cc[1:], "-print-file-name=libcrypto.a")...).Output()
+	if lib := strings.TrimSpace(string(out)); err != nil || !filepath.IsAbs(lib) {
+		t.Skip("skipping: libcrypto.a not found")
+	}
+
+	tmpdir, err := ioutil.TempDir("", "TestOpenSSLStatic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpdir)
+
+	src := filepath.Join(tmpdir, "main.go")
+	if err := ioutil.WriteFile(src, []byte(staticSrc), 0666); err != nil {
+		t.Fatal(err)
+	}
+	exe := filepath.Join(tmpdir, "main.exe")
+	cmd := exec.Command(testenv.GoToolPath(t), "build", "-tags=openssl_static", "-o", exe, src)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go build -tags=openssl_static failed: %v\n%s", err, out)
+	}
+
+	// libcrypto must be part of the binary, not loaded at run time.
+	out, err = exec.Command(testenv.GoToolPath(t), "tool", "nm", exe).CombinedOutput()
+	if err != nil {
+		t.Fatalf("go tool nm failed: %v\n%s", err, out)
+	}
+	if !strings.Contains(string(out), " T EVP_DigestInit_ex\n") {
+		t.Errorf("EVP_DigestInit_ex is not defined in the binary")
+	}
+
+	cmd = exec.Command(exe)
+	cmd.Env = append(os.Environ(), "GOLANG_FIPS=1")
+	out, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("running static binary failed: %v\n%s", err, out)
+	}
+	const want = "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824\n"
+	if string(out) != want {
+		t.Errorf("static binary printed %q, want %q", out, want)
+	}
+}
+
+func goEnv(t *testing.T, name string) string {
+	out, err := exec.Command(testenv.GoToolPath(t), "env", name).Output()
+	if err != nil {
+		t.Fatalf("go env %s: %v", name, err)
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,10 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package staticlink forces external linking for binaries built with
+// the openssl_static build tag, which link libcrypto.a into
+// crypto/internal/boring. The Go linker allows crypto/internal/boring
+// itself to link internally, which cannot resolve symbols from a static
+// library. Without the build tag the package is empty.
+package staticlink
@@ -0,0 +1,33 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+import "testing"
+
+// Test that the library loaded at run time has the major version of the
+// headers, whose structures and function names the package relies on.
+func TestLibraryVersion(t *testing.T) {
+	if !Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	lib := libraryVersion()
+	t.Logf("headers %#x, library %#x", headerVersion, lib)
+	major := func(v uint64) uint64 {
+		if v >= 0x30000000 {
+			return v >> 28
+		}
+		// Before 3.0, the minor version also changed the ABI.
+		return v >> 20
+	}
+	if major(lib) != major(headerVersion) {
+		t.Errorf("loaded library version %#x does not match header version %#x", lib, uint64(headerVersion))
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// +build linux
+// +build !android
+// +build !no_openssl
+// +build !cmd_go_bootstrap
+// +build !msan
+
+package boring
+
+// #include "goboringcrypto.h"
+import "C"
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+	"runtime"
+	"unsafe"
+)
+
+// NewXTS returns an XTS-AES cipher, SP 800-38E, using c for the data units
+// and tweak for the tweaks, computed with the module's EVP_aes_*_xts. It
+// is used by crypto/cipher.NewXTS.
+func (c *aesCipher) NewXTS(tweak cipher.Block) (cipher.XTS, error) {
+	t, ok := tweak.(*aesCipher)
+	if !ok || len(t.key) != len(c.key) {
+		return nil, errors.New("crypto/cipher: XTS tweak cipher must be AES with the same key size")
+	}
+	// The module may not check this itself on decryption.
+	if subtle.ConstantTimeCompare(c.key, t.key) == 1 {
+		return nil, errors.New("crypto/cipher: XTS data and tweak keys must differ")
+	}
+
+	x := &aesXTS{key: make([]byte, 0, 2*len(c.key))}
+	x.key = append(append(x.key, c.key...), t.key...)
+	switch len(c.key) * 8 {
+	case 128:
+		x.cipher = C._goboringcrypto_EVP_aes_128_xts()
+	case 256:
+		x.cipher = C._goboringcrypto_EVP_aes_256_xts()
+	default:
+		return nil, errors.New("crypto/cipher: XTS requires AES-128 or AES-256")
+	}
+	return x, nil
+}
+
+type aesXTS struct {
+	key    []byte
+	cipher *C.EVP_CIPHER
+}
+
+func (x *aesXTS) Encrypt(dst, src []byte, sector uint64) {
+	x.crypt(dst, src, sector, C.GO_AES_ENCRYPT)
+}
+
+func (x *aesXTS) Decrypt(dst, src []byte, sector uint64) {
+	x.crypt(dst, src, sector, C.GO_AES_DECRYPT)
+}
+
+func (x *aesXTS) crypt(dst, src []byte, sector uint64, mode C.int) {
+	if len(src) < aesBlockSize {
+		panic("crypto/cipher: input smaller than a block")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	if inexactOverlap(dst[:len(src)], src) {
+		panic("crypto/cipher: invalid buffer overlap")
+	}
+
+	// The IV is the little-endian sector number, IEEE Std 1619.
+	var iv [aesBlockSize]byte
+	for i := 0; i < 8; i++ {
+		iv[i] = byte(sector >> (8 * i))
+	}
+
+	// A context per call keeps aesXTS safe for concurrent use; each data
+	// unit needs a fresh tweak anyway.
+	ctx := C._goboringcrypto_EVP_CIPHER_CTX_new()
+	if ctx == nil {
+		panic("cipher: unable to create EVP cipher ctx")
+	}
+	defer C._goboringcrypto_EVP_CIPHER_CTX_free(ctx)
+	if C._goboringcrypto_EVP_CipherInit_ex(ctx, x.cipher, nil, (*C.uchar)(unsafe.Pointer(base(x.key))), (*C.uchar)(unsafe.Pointer(&iv[0])), mode) != 1 {
+		panic("cipher: unable to initialize EVP cipher ctx")
+	}
+	var n C.int
+	if C._goboringcrypto_EVP_CipherUpdate(ctx, (*C.uchar)(unsafe.Pointer(base(dst))), &n, (*C.uchar)(unsafe.Pointer(base(src))), C.int(len(src))) != 1 || int(n) != len(src) {
+		panic("crypto/cipher: CipherUpdate failed")
+	}
+	runtime.KeepAlive(x)
+}
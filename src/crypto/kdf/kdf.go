@@ -0,0 +1,171 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package kdf implements the key-based key derivation function of NIST
+// SP 800-108 in counter mode, the one-step key derivation function of
+// NIST SP 800-56C, and the ANSI X9.63 key derivation function.
+//
+// In FIPS mode, the functions run in the OpenSSL module when it provides
+// them (OpenSSL 3.0 and later) for the given hash. Otherwise they use a
+// Go implementation.
+package kdf
+
+import (
+	"crypto/aes"
+	"crypto/cmac"
+	"crypto/hmac"
+	"crypto/internal/boring"
+	"encoding/binary"
+	"errors"
+	"hash"
+)
+
+var errLength = errors.New("kdf: invalid output length")
+
+// CounterHMAC derives length bytes from key with the SP 800-108 KDF in
+// counter mode, using HMAC with the hash h as the PRF. The counter is 32
+// bits long and precedes the fixed input data, which is
+// label || 0x00 || context || L, with L the output length in bits as a
+// 32-bit big-endian integer.
+func CounterHMAC(h func() hash.Hash, key, label, context []byte, length int) ([]byte, error) {
+	if length <= 0 || uint64(length) > (1<<32-1)/8 {
+		return nil, errLength
+	}
+	return counterHMAC(h, key, fixedInput(label, context, length), length)
+}
+
+// CounterCMAC is like CounterHMAC, using AES-CMAC with the AES key key as
+// the PRF.
+func CounterCMAC(key, label, context []byte, length int) ([]byte, error) {
+	if length <= 0 || uint64(length) > (1<<32-1)/8 {
+		return nil, errLength
+	}
+	return counterCMAC(key, fixedInput(label, context, length), length)
+}
+
+// fixedInput returns the fixed input data of SP 800-108, Section 5.
+func fixedInput(label, context []byte, length int) []byte {
+	in := make([]byte, 0, len(label)+1+len(context)+4)
+	in = append(in, label...)
+	in = append(in, 0)
+	in = append(in, context...)
+	return appendUint32(in, uint32(length*8))
+}
+
+func counterHMAC(h func() hash.Hash, key, fixedInput []byte, length int) ([]byte, error) {
+	if boring.Enabled() && boring.SupportsKDF(h) {
+		return boring.KBKDFCounterHMAC(h, key, fixedInput, length)
+	}
+	return counter(hmac.New(h, key), fixedInput, length)
+}
+
+func counterCMAC(key, fixedInput []byte, length int) ([]byte, error) {
+	if boring.Enabled() && boring.SupportsKDF(nil) {
+		return boring.KBKDFCounterCMAC(key, fixedInput, length)
+	}
+	b, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	prf, err := cmac.New(b)
+	if err != nil {
+		return nil, err
+	}
+	return counter(prf, fixedInput, length)
+}
+
+// counter computes PRF(i || fixedInput) for i = 1, 2, ... and returns
+// the first length bytes of the concatenation.
+func counter(prf hash.Hash, fixedInput []byte, length int) ([]byte, error) {
+	out := make([]byte, 0, length+prf.Size())
+	for i := uint32(1); len(out) < length; i++ {
+		prf.Reset()
+		prf.Write(appendUint32(nil, i))
+		prf.Write(fixedInput)
+		out = prf.Sum(out)
+	}
+	return out[:length], nil
+}
+
+// OneStepHash derives length bytes from the shared secret with the SP
+// 800-56C one-step KDF, using the hash h as the auxiliary function
+// (Option 1).
+func OneStepHash(h func() hash.Hash, secret, fixedInfo []byte, length int) ([]byte, error) {
+	if err := checkLength(h, length); err != nil {
+		return nil, err
+	}
+	if boring.Enabled() && boring.SupportsKDF(h) {
+		return boring.SSKDFHash(h, secret, fixedInfo, length)
+	}
+	return oneStep(h(), secret, fixedInfo, length), nil
+}
+
+// OneStepHMAC is like OneStepHash, using HMAC with the hash h keyed with
+// salt as the auxiliary function (Option 2). If salt is empty, the
+// default salt of SP 800-56C is used: as many zero bytes as the block
+// size of h.
+func OneStepHMAC(h func() hash.Hash, secret, salt, fixedInfo []byte, length int) ([]byte, error) {
+	if err := checkLength(h, length); err != nil {
+		return nil, err
+	}
+	if len(salt) == 0 {
+		salt = make([]byte, h().BlockSize())
+	}
+	if boring.Enabled() && boring.SupportsKDF(h) {
+		return boring.SSKDFHMAC(h, secret, salt, fixedInfo, length)
+	}
+	return oneStep(hmac.New(h, salt), secret, fixedInfo, length), nil
+}
+
+// oneStep computes H(i || secret || fixedInfo) for i = 1, 2, ... and
+// returns the first length bytes of the concatenation.
+func oneStep(aux hash.Hash, secret, fixedInfo []byte, length int) []byte {
+	out := make([]byte, 0, length+aux.Size())
+	for i := uint32(1); len(out) < length; i++ {
+		aux.Reset()
+		aux.Write(appendUint32(nil, i))
+		aux.Write(secret)
+		aux.Write(fixedInfo)
+		out = aux.Sum(out)
+	}
+	return out[:length]
+}
+
+// X963 derives length bytes from the shared secret with the ANSI X9.63
+// KDF, also specified in SEC 1, Section 3.6.1, using the hash h.
+func X963(h func() hash.Hash, secret, sharedInfo []byte, length int) ([]byte, error) {
+	if err := checkLength(h, length); err != nil {
+		return nil, err
+	}
+	if boring.Enabled() && boring.SupportsKDF(h) {
+		return boring.X963KDF(h, secret, sharedInfo, length)
+	}
+
+	// Unlike the one-step KDF, the counter follows the secret.
+	d := h()
+	out := make([]byte, 0, length+d.Size())
+	for i := uint32(1); len(out) < length; i++ {
+		d.Reset()
+		d.Write(secret)
+		d.Write(appendUint32(nil, i))
+		d.Write(sharedInfo)
+		out = d.Sum(out)
+	}
+	return out[:length], nil
+}
+
+// checkLength checks that length is positive and that at most 2^32 - 1
+// blocks of h are needed.
+func checkLength(h func() hash.Hash, length int) error {
+	if length <= 0 || uint64(length) > uint64(h().Size())*(1<<32-1) {
+		return errLength
+	}
+	return nil
+}
+
+func appendUint32(b []byte, v uint32) []byte {
+	var buf [4]byte
+	binary.BigEndian.PutUint32(buf[:], v)
+	return append(b, buf[:]...)
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package kdf
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cmac"
+	"crypto/hmac"
+	"crypto/internal/boring"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"testing"
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// From the NIST CAVP SP 800-108 test vectors (KDFCTR_gen.rsp), with
+// CTRLOCATION=BEFORE_FIXED and RLEN=32_BITS.
+var counterTests = []struct {
+	prf                  string
+	key, fixedInput, out string
+}{
+	{
+		"HMAC_SHA256",
+		"dd1d91b7d90b2bd3138533ce92b272fbf8a369316aefe242e659cc0ae238afe0",
+		"01322b96b30acd197979444e468e1c5c6859bf1b1cf951b7e725303e237e46b864a145fab25e517b08f8683d0315bb2911d80a0e8aba17f3b413faac",
+		"10621342bfb0fd40046c0e29f2cfdbf0",
+	},
+	{
+		"CMAC_AES128",
+		"c10b152e8c97b77e18704e0f0bd38305",
+		"98cd4cbbbebe15d17dc86e6dbad800a2dcbd64f7c7ad0e78e9cf94ffdba89d03e97eadf6c4f7b806caf52aa38f09d0eb71d71f497bcc6906b48d36c4",
+		"26faf61908ad9ee881b8305c221db53f",
+	},
+}
+
+func TestCounter(t *testing.T) {
+	for _, tt := range counterTests {
+		key, fixed, want := decodeHex(tt.key), decodeHex(tt.fixedInput), decodeHex(tt.out)
+
+		var got []byte
+		var err error
+		var prf hash.Hash
+		if tt.prf == "HMAC_SHA256" {
+			got, err = counterHMAC(sha256.New, key, fixed, len(want))
+			prf = hmac.New(sha256.New, key)
+		} else {
+			got, err = counterCMAC(key, fixed, len(want))
+			b, _ := aes.NewCipher(key)
+			prf, _ = cmac.New(b)
+		}
+		if err != nil {
+			t.Fatalf("%s: %v", tt.prf, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: got %x, want %x", tt.prf, got, want)
+		}
+
+		// Check the Go implementation, which FIPS mode may not reach.
+		if got, _ := counter(prf, fixed, len(want)); !bytes.Equal(got, want) {
+			t.Errorf("%s, Go: got %x, want %x", tt.prf, got, want)
+		}
+	}
+}
+
+// TestCounterFixedInput checks the layout of the fixed input data and
+// outputs spanning several PRF blocks. In FIPS mode the values come from
+// the KBKDF of OpenSSL 3.0 with its default fixed input layout.
+func TestCounterFixedInput(t *testing.T) {
+	label, context := []byte("label"), []byte("context")
+
+	got, err := CounterHMAC(sha256.New, decodeHex("000102030405060708090a0b0c0d0e0f"), label, context, 40)
+	if want := decodeHex("ea7d2f723c7c89aff21be0deb82b56a4a8245b01afe4a26f5bd4cf6bafd59f0337835beb381a6598"); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("CounterHMAC: got %x, %v, want %x", got, err, want)
+	}
+
+	got, err = CounterCMAC(decodeHex("000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"), label, context, 40)
+	if want := decodeHex("f14b2590d159191e74c966b0ccefd4e8acc21dac50a6b9816be8a8b591834af5291ae4253390327d"); err != nil || !bytes.Equal(got, want) {
+		t.Errorf("CounterCMAC: got %x, %v, want %x", got, err, want)
+	}
+
+	if _, err := CounterCMAC(make([]byte, 20), label, context, 16); err == nil {
+		t.Error("CounterCMAC accepted a 20-byte key")
+	}
+}
+
+// From the NIST CAVP SP 800-56C one-step KDF test vectors.
+var oneStepTests = []struct {
+	h                       func() hash.Hash
+	secret, salt, info, out string
+}{
+	{
+		sha1.New,
+		"ebe28edbae5a410b87a479243db3f690",
+		"",
+		"e60dd8b28228ce5b9be74d3b",
+		"b4a23963e07f485382cb358a493daec1",
+	},
+	{
+		sha256.New,
+		"52169af5c485dcc2321eb8d26d5efa21fb9b93c98e38412ee2484cf14f0d0d23",
+		"",
+		"a1b2c3d4e53728157e634612c12d6d5223e204aeea4341565369647bd184bcd246f72971f292badaa2fe4124612cba",
+		"1c3bc9e7c4547c5191c0d478cccaed55",
+	},
+	{
+		// Option 2, with HMAC-SHA256.
+		sha256.New,
+		"6ee6c00d70a6cd14bd5a4e8fcfec8386",
+		"532f5131e0a2fecc722f87e5aa2062cb",
+		"861aa2886798231259bd0314",
+		"13479e9a91dd20fdd757d68ffe8869fb",
+	},
+}
+
+func TestOneStep(t *testing.T) {
+	for i, tt := range oneStepTests {
+		secret, info, want := decodeHex(tt.secret), decodeHex(tt.info), decodeHex(tt.out)
+
+		var got, goGot []byte
+		var err error
+		if tt.salt == "" {
+			got, err = OneStepHash(tt.h, secret, info, len(want))
+			goGot = oneStep(tt.h(), secret, info, len(want))
+		} else {
+			salt := decodeHex(tt.salt)
+			got, err = OneStepHMAC(tt.h, secret, salt, info, len(want))
+			goGot = oneStep(hmac.New(tt.h, salt), secret, info, len(want))
+		}
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: got %x, want %x", i, got, want)
+		}
+		if !bytes.Equal(goGot, want) {
+			t.Errorf("#%d, Go: got %x, want %x", i, goGot, want)
+		}
+	}
+}
+
+// TestOneStepDefaultSalt checks the default HMAC salt. In FIPS mode the
+// value comes from the SSKDF of OpenSSL 3.0.
+func TestOneStepDefaultSalt(t *testing.T) {
+	got, err := OneStepHMAC(sha256.New, decodeHex("000102030405060708090a0b0c0d0e0f"), nil, []byte("info"), 40)
+	want := decodeHex("ee2f9e3b31bd8b83596b6022b6c396fefae670c487dfffe49f7f9ee75acfa855dc1c63483181fee0")
+	if err != nil || !bytes.Equal(got, want) {
+		t.Errorf("got %x, %v, want %x", got, err, want)
+	}
+}
+
+// From the NIST CAVP ANSI X9.63 KDF test vectors (ansx963_2001.rsp).
+var x963Tests = []struct {
+	h                 func() hash.Hash
+	secret, info, out string
+}{
+	{
+		sha1.New,
+		"1c7d7b5f0597b03d06a018466ed1a93e30ed4b04dc64ccdd",
+		"",
+		"bf71dffd8f4d99223936beb46fee8ccc",
+	},
+	{
+		sha256.New,
+		"96c05619d56c328ab95fe84b18264b08725b85e33fd34f08",
+		"",
+		"443024c3dae66b95e6f5670601558f71",
+	},
+	{
+		sha256.New,
+		"22518b10e70f2a3f243810ae3254139efbee04aa57c7af7d",
+		"75eef81aa3041e33b80971203d2c0c52",
+		"c498af77161cc59f2962b9a713e2b215152d139766ce34a776df11866a69bf2e" +
+			"52a13d9c7c6fc878c50c5ea0bc7b00e0da2447cfd874f6cf92f30d0097111485" +
+			"500c90c3af8b487872d04685d14c8d1dc8d7fa08beb0ce0ababc11f0bd496269" +
+			"142d43525a78e5bc79a17f59676a5706dc54d54d4d1f0bd7e386128ec26afc21",
+	},
+}
+
+func TestX963(t *testing.T) {
+	for i, tt := range x963Tests {
+		want := decodeHex(tt.out)
+		got, err := X963(tt.h, decodeHex(tt.secret), decodeHex(tt.info), len(want))
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("#%d: got %x, want %x", i, got, want)
+		}
+	}
+}
+
+func TestInvalidLength(t *testing.T) {
+	for _, n := range []int{-1, 0} {
+		if _, err := CounterHMAC(sha256.New, []byte("key"), nil, nil, n); err == nil {
+			t.Errorf("CounterHMAC accepted length %d", n)
+		}
+		if _, err := OneStepHash(sha256.New, []byte("key"), nil, n); err == nil {
+			t.Errorf("OneStepHash accepted length %d", n)
+		}
+		if _, err := X963(sha256.New, []byte("key"), nil, n); err == nil {
+			t.Errorf("X963 accepted length %d", n)
+		}
+	}
+}
+
+// TestOpenSSL checks that FIPS mode uses the OpenSSL KDFs and that they
+// agree with the Go implementations.
+func TestOpenSSL(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("OpenSSL is not in use")
+	}
+	if !boring.SupportsKDF(sha256.New) || !boring.SupportsKDF(nil) {
+		t.Skip("OpenSSL does not support the KDFs")
+	}
+
+	key, label, context := make([]byte, 16), []byte("label"), []byte("context")
+	for i := range key {
+		key[i] = byte(i)
+	}
+	for _, length := range []int{1, 16, 32, 33, 100} {
+		fixed := fixedInput(label, context, length)
+
+		got, err := boring.KBKDFCounterHMAC(sha256.New, key, fixed, length)
+		want, _ := counter(hmac.New(sha256.New, key), fixed, length)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("KBKDFCounterHMAC(%d): got %x, %v, want %x", length, got, err, want)
+		}
+
+		got, err = boring.KBKDFCounterCMAC(key, fixed, length)
+		b, _ := aes.NewCipher(key)
+		prf, _ := cmac.New(b)
+		want, _ = counter(prf, fixed, length)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("KBKDFCounterCMAC(%d): got %x, %v, want %x", length, got, err, want)
+		}
+
+		got, err = boring.SSKDFHash(sha256.New, key, context, length)
+		want = oneStep(sha256.New(), key, context, length)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("SSKDFHash(%d): got %x, %v, want %x", length, got, err, want)
+		}
+
+		got, err = boring.SSKDFHMAC(sha256.New, key, label, context, length)
+		want = oneStep(hmac.New(sha256.New, label), key, context, length)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("SSKDFHMAC(%d): got %x, %v, want %x", length, got, err, want)
+		}
+	}
+}
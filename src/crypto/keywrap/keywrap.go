@@ -0,0 +1,205 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package keywrap implements the AES Key Wrap (KW) and AES Key Wrap with
+// Padding (KWP) modes of NIST SP 800-38F, also specified in RFC 3394 and
+// RFC 5649.
+//
+// In FIPS mode, blocks returned by crypto/aes.NewCipher wrap and unwrap
+// with the OpenSSL module. Other blocks use a Go implementation.
+package keywrap
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"math"
+)
+
+// ErrUnwrap is returned when the integrity check of a wrapped key fails.
+var ErrUnwrap = errors.New("keywrap: integrity check failed")
+
+var (
+	errBlockSize  = errors.New("keywrap: block size must be 16 bytes")
+	errWrapLength = errors.New("keywrap: invalid input length")
+)
+
+// keyWrapper is implemented by ciphers with a native key wrap
+// implementation, such as the BoringCrypto AES cipher.
+type keyWrapper interface {
+	Wrap(plaintext []byte) ([]byte, error)
+	Unwrap(ciphertext []byte) ([]byte, error)
+	WrapPad(plaintext []byte) ([]byte, error)
+	UnwrapPad(ciphertext []byte) ([]byte, error)
+}
+
+// defaultIV is the initial value of RFC 3394, Section 2.2.3.1.
+var defaultIV = [8]byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// padIV is the constant part of the alternative initial value of
+// RFC 5649, Section 3.
+var padIV = [4]byte{0xa6, 0x59, 0x59, 0xa6}
+
+// Wrap wraps plaintext, which must be a multiple of 8 bytes long and at
+// least 16 bytes, with the KW mode. The result is 8 bytes longer than
+// plaintext.
+func Wrap(b cipher.Block, plaintext []byte) ([]byte, error) {
+	if b.BlockSize() != 16 {
+		return nil, errBlockSize
+	}
+	if len(plaintext) < 16 || len(plaintext)%8 != 0 {
+		return nil, errWrapLength
+	}
+	if kw, ok := b.(keyWrapper); ok {
+		return kw.Wrap(plaintext)
+	}
+
+	out := make([]byte, len(plaintext)+8)
+	copy(out[8:], plaintext)
+	wrap(b, defaultIV, out)
+	return out, nil
+}
+
+// Unwrap unwraps ciphertext produced by Wrap. It returns ErrUnwrap if the
+// integrity check fails.
+func Unwrap(b cipher.Block, ciphertext []byte) ([]byte, error) {
+	if b.BlockSize() != 16 {
+		return nil, errBlockSize
+	}
+	if len(ciphertext) < 24 || len(ciphertext)%8 != 0 {
+		return nil, errWrapLength
+	}
+	if kw, ok := b.(keyWrapper); ok {
+		out, err := kw.Unwrap(ciphertext)
+		if err != nil {
+			return nil, ErrUnwrap
+		}
+		return out, nil
+	}
+
+	out := make([]byte, len(ciphertext))
+	copy(out, ciphertext)
+	iv := unwrap(b, out)
+	if subtle.ConstantTimeCompare(iv[:], defaultIV[:]) != 1 {
+		return nil, ErrUnwrap
+	}
+	return out[8:], nil
+}
+
+// WrapPad wraps plaintext, which may be of any length from 1 byte to
+// 2³² - 1 bytes, with the KWP mode. The result is plaintext padded to a
+// multiple of 8 bytes, plus 8 bytes.
+func WrapPad(b cipher.Block, plaintext []byte) ([]byte, error) {
+	if b.BlockSize() != 16 {
+		return nil, errBlockSize
+	}
+	if len(plaintext) == 0 || uint64(len(plaintext)) > math.MaxUint32 {
+		return nil, errWrapLength
+	}
+	if kw, ok := b.(keyWrapper); ok {
+		return kw.WrapPad(plaintext)
+	}
+
+	var iv [8]byte
+	copy(iv[:], padIV[:])
+	binary.BigEndian.PutUint32(iv[4:], uint32(len(plaintext)))
+
+	out := make([]byte, 8+(len(plaintext)+7)/8*8)
+	copy(out[8:], plaintext)
+	if len(out) == 16 {
+		// A single padded block is encrypted directly, RFC 5649, Section 4.1.
+		copy(out, iv[:])
+		b.Encrypt(out, out)
+		return out, nil
+	}
+	wrap(b, iv, out)
+	return out, nil
+}
+
+// UnwrapPad unwraps ciphertext produced by WrapPad. It returns ErrUnwrap if
+// the integrity check fails.
+func UnwrapPad(b cipher.Block, ciphertext []byte) ([]byte, error) {
+	if b.BlockSize() != 16 {
+		return nil, errBlockSize
+	}
+	if len(ciphertext) < 16 || len(ciphertext)%8 != 0 {
+		return nil, errWrapLength
+	}
+	if kw, ok := b.(keyWrapper); ok {
+		out, err := kw.UnwrapPad(ciphertext)
+		if err != nil {
+			return nil, ErrUnwrap
+		}
+		return out, nil
+	}
+
+	out := make([]byte, len(ciphertext))
+	var iv [8]byte
+	if len(ciphertext) == 16 {
+		b.Decrypt(out, ciphertext)
+		copy(iv[:], out)
+	} else {
+		copy(out, ciphertext)
+		iv = unwrap(b, out)
+	}
+
+	// Check the constant, the message length indicator and the zero
+	// padding, without revealing which of them was wrong.
+	padded := len(out) - 8
+	mli := int(binary.BigEndian.Uint32(iv[4:]))
+	ok := subtle.ConstantTimeCompare(iv[:4], padIV[:])
+	ok &= subtle.ConstantTimeLessOrEq(padded-7, mli)
+	ok &= subtle.ConstantTimeLessOrEq(mli, padded)
+	if ok != 1 {
+		return nil, ErrUnwrap
+	}
+	var nonzero byte
+	for _, c := range out[8+mli:] {
+		nonzero |= c
+	}
+	if nonzero != 0 {
+		return nil, ErrUnwrap
+	}
+	return out[8 : 8+mli], nil
+}
+
+// wrap runs the wrapping process W of SP 800-38F, Section 6.1, in place
+// on buf, whose first 8 bytes are overwritten with iv.
+func wrap(b cipher.Block, iv [8]byte, buf []byte) {
+	n := len(buf)/8 - 1
+	var block [16]byte
+	copy(block[:8], iv[:])
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			r := buf[i*8 : i*8+8]
+			copy(block[8:], r)
+			b.Encrypt(block[:], block[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(block[:8], binary.BigEndian.Uint64(block[:8])^t)
+			copy(r, block[8:])
+		}
+	}
+	copy(buf[:8], block[:8])
+}
+
+// unwrap runs the unwrapping process W⁻¹ of SP 800-38F, Section 6.1, in
+// place on buf and returns the recovered initial value.
+func unwrap(b cipher.Block, buf []byte) (iv [8]byte) {
+	n := len(buf)/8 - 1
+	var block [16]byte
+	copy(block[:8], buf[:8])
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			r := buf[i*8 : i*8+8]
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(block[:8], binary.BigEndian.Uint64(block[:8])^t)
+			copy(block[8:], r)
+			b.Decrypt(block[:], block[:])
+			copy(r, block[8:])
+		}
+	}
+	copy(iv[:], block[:8])
+	return iv
+}
@@ -0,0 +1,175 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package keywrap
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func decodeHex(s string) []byte {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, " ", ""))
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// goBlock hides any native key wrap implementation of the underlying
+// cipher, so that the Go implementation is exercised in FIPS mode too.
+type goBlock struct {
+	cipher.Block
+}
+
+type wrapTest struct {
+	kek, plaintext, ciphertext string
+}
+
+// From RFC 3394, Section 4.
+var wrapTests = []wrapTest{
+	{
+		"000102030405060708090A0B0C0D0E0F",
+		"00112233445566778899AABBCCDDEEFF",
+		"1FA68B0A8112B447 AEF34BD8FB5A7B82 9D3E862371D2CFE5",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F1011121314151617",
+		"00112233445566778899AABBCCDDEEFF",
+		"96778B25AE6CA435 F92B5B97C050AED2 468AB8A17AD84E5D",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		"00112233445566778899AABBCCDDEEFF",
+		"64E8C3F9CE0F5BA2 63E9777905818A2A 93C8191E7D6E8AE7",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F1011121314151617",
+		"00112233445566778899AABBCCDDEEFF0001020304050607",
+		"031D33264E15D332 68F24EC260743EDC E1C6C7DDEE725A93 6BA814915C6762D2",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		"00112233445566778899AABBCCDDEEFF0001020304050607",
+		"A8F9BC1612C68B3F F6E6F4FBE30E71E4 769C8B80A32CB895 8CD5D17D6B254DA1",
+	},
+	{
+		"000102030405060708090A0B0C0D0E0F101112131415161718191A1B1C1D1E1F",
+		"00112233445566778899AABBCCDDEEFF000102030405060708090A0B0C0D0E0F",
+		"28C9F404C4B810F4 CBCCB35CFB87F826 3F5786E2D80ED326 CBC7F0E71A99F43B FB988B9B7A02DD21",
+	},
+}
+
+// From RFC 5649, Section 6.
+var wrapPadTests = []wrapTest{
+	{
+		"5840df6e29b02af1 ab493b705bf16ea1 ae8338f4dcc176a8",
+		"c37b7e6492584340 bed1220780894115 5068f738",
+		"138bdeaa9b8fa7fc 61f97742e72248ee 5ae6ae5360d1ae6a 5f54f373fa543b6a",
+	},
+	{
+		"5840df6e29b02af1 ab493b705bf16ea1 ae8338f4dcc176a8",
+		"466f7250617369",
+		"afbeb0f07dfbf541 9200f2ccb50bb24f",
+	},
+}
+
+func testWrap(t *testing.T, tests []wrapTest, wrapFn, unwrapFn func(cipher.Block, []byte) ([]byte, error)) {
+	for i, tt := range tests {
+		b, err := aes.NewCipher(decodeHex(tt.kek))
+		if err != nil {
+			t.Fatal(err)
+		}
+		plaintext, ciphertext := decodeHex(tt.plaintext), decodeHex(tt.ciphertext)
+		for _, block := range []cipher.Block{b, goBlock{b}} {
+			out, err := wrapFn(block, plaintext)
+			if err != nil {
+				t.Errorf("#%d, %T: wrap failed: %v", i, block, err)
+				continue
+			}
+			if !bytes.Equal(out, ciphertext) {
+				t.Errorf("#%d, %T: got %x, want %x", i, block, out, ciphertext)
+			}
+
+			out, err = unwrapFn(block, ciphertext)
+			if err != nil {
+				t.Errorf("#%d, %T: unwrap failed: %v", i, block, err)
+				continue
+			}
+			if !bytes.Equal(out, plaintext) {
+				t.Errorf("#%d, %T: got %x, want %x", i, block, out, plaintext)
+			}
+
+			for j := range ciphertext {
+				tampered := append([]byte(nil), ciphertext...)
+				tampered[j] ^= 0x01
+				if _, err := unwrapFn(block, tampered); err != ErrUnwrap {
+					t.Errorf("#%d, %T: tampered byte %d: got error %v, want ErrUnwrap", i, block, j, err)
+				}
+			}
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	testWrap(t, wrapTests, Wrap, Unwrap)
+}
+
+func TestWrapPad(t *testing.T) {
+	testWrap(t, wrapPadTests, WrapPad, UnwrapPad)
+}
+
+func TestWrapPadLengths(t *testing.T) {
+	b, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for n := 1; n <= 40; n++ {
+		plaintext := bytes.Repeat([]byte{byte(n)}, n)
+		native, err := WrapPad(b, plaintext)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		generic, err := WrapPad(goBlock{b}, plaintext)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if !bytes.Equal(native, generic) {
+			t.Errorf("%d bytes: implementations disagree: %x and %x", n, native, generic)
+		}
+		out, err := UnwrapPad(b, generic)
+		if err != nil || !bytes.Equal(out, plaintext) {
+			t.Errorf("%d bytes: unwrap got %x, %v", n, out, err)
+		}
+	}
+}
+
+func TestWrapInvalidLength(t *testing.T) {
+	b, err := aes.NewCipher(make([]byte, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range []int{0, 8, 15, 17} {
+		if _, err := Wrap(b, make([]byte, n)); err == nil {
+			t.Errorf("Wrap accepted %d bytes", n)
+		}
+	}
+	for _, n := range []int{0, 16, 23, 25} {
+		if _, err := Unwrap(b, make([]byte, n)); err == nil {
+			t.Errorf("Unwrap accepted %d bytes", n)
+		}
+	}
+	if _, err := WrapPad(b, nil); err == nil {
+		t.Error("WrapPad accepted an empty input")
+	}
+	for _, n := range []int{0, 8, 17} {
+		if _, err := UnwrapPad(b, make([]byte, n)); err == nil {
+			t.Errorf("UnwrapPad accepted %d bytes", n)
+		}
+	}
+}
@@ -8,9 +8,12 @@
 package rsa
 
 import (
+	"bytes"
 	"crypto"
+	"crypto/internal/boring"
 	"crypto/rand"
 	"encoding/asn1"
+	"math/big"
 	"reflect"
 	"runtime"
 	"runtime/debug"
@@ -83,6 +86,60 @@ func TestBoringGenerateKey(t *testing.T) {
 	}
 }
 
+func TestBoringPrivateKeyCheck(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("key consistency checks only run with the boring backend")
+	}
+	if _, err := boringPrivateKey(test2048Key); err != nil {
+		t.Fatalf("boringPrivateKey of valid key: %v", err)
+	}
+
+	// With the primes present RSA_check_key catches the bad exponent;
+	// without them only the pairwise consistency test can.
+	bad := *test2048Key
+	bad.boring = nil
+	bad.D = new(big.Int).Add(test2048Key.D, big.NewInt(2))
+	bad.Precomputed = PrecomputedValues{}
+	if _, err := boringPrivateKey(&bad); err == nil {
+		t.Error("boringPrivateKey accepted a key with an inconsistent private exponent")
+	}
+	bad.boring = nil
+	bad.Primes = nil
+	if _, err := boringPrivateKey(&bad); err == nil {
+		t.Error("boringPrivateKey accepted a key without primes and an inconsistent private exponent")
+	}
+}
+
+func TestBoringTestDRBG(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("test DRBG is only available with the boring backend")
+	}
+	msg := []byte("testing")
+	sign := func(seed string) []byte {
+		restore := boring.SetTestDRBG(boring.NewTestDRBG([]byte(seed), nil, nil))
+		defer restore()
+		sig, err := HashSignPSS(rand.Reader, test2048Key, crypto.SHA256, msg, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sig
+	}
+
+	// The first operation on a key sets up blinding, which also consumes
+	// random bytes, so do it before any DRBG is installed.
+	if _, err := HashSignPSS(rand.Reader, test2048Key, crypto.SHA256, msg, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sig1 := sign("PSS salt seed, first")
+	if sig2 := sign("PSS salt seed, first"); !bytes.Equal(sig1, sig2) {
+		t.Error("PSS signatures with identically seeded DRBGs differ")
+	}
+	if sig3 := sign("PSS salt seed, second"); bytes.Equal(sig1, sig3) {
+		t.Error("PSS signatures with differently seeded DRBGs are equal")
+	}
+}
+
 func TestBoringFinalizers(t *testing.T) {
 	if runtime.GOOS == "nacl" || runtime.GOOS == "js" {
 		// Times out on nacl and js/wasm (without BoringCrypto)
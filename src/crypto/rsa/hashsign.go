@@ -0,0 +1,126 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package rsa
+
+import (
+	"crypto"
+	"errors"
+	"hash"
+	"io"
+
+	"crypto/internal/boring"
+)
+
+// This file implements streaming hash-then-sign, for messages that are too
+// large to pass to HashVerifyPKCS1v15 and friends as a single slice.
+
+// A HashSigner signs a message that is written to it incrementally.
+// In FIPS mode, both the digest and the signature are computed by the
+// OpenSSL module.
+type HashSigner struct {
+	boring *boring.DigestSigner
+
+	rand io.Reader
+	priv *PrivateKey
+	hash crypto.Hash
+	h    hash.Hash
+}
+
+// NewHashSignerPKCS1v15 returns a HashSigner that signs the hash digest of
+// the message written to it with priv, using RSASSA-PKCS1-V1_5-SIGN from
+// RSA PKCS #1 v1.5. If rand is not nil then RSA blinding will be used to
+// avoid timing side-channel attacks, as in SignPKCS1v15. rand is not used
+// in FIPS mode, where the module blinds on its own.
+func NewHashSignerPKCS1v15(rand io.Reader, priv *PrivateKey, hash crypto.Hash) (*HashSigner, error) {
+	if boring.Enabled() {
+		bkey, err := boringPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		b, err := boring.NewDigestSignerRSAPKCS1v15(bkey, hash)
+		if err != nil {
+			return nil, err
+		}
+		return &HashSigner{boring: b}, nil
+	}
+	boring.UnreachableExceptTests()
+
+	if !hash.Available() {
+		return nil, errors.New("crypto/rsa: unsupported hash function")
+	}
+	return &HashSigner{rand: rand, priv: priv, hash: hash, h: hash.New()}, nil
+}
+
+// Write adds more data to the message being signed. It never returns an error.
+func (s *HashSigner) Write(p []byte) (int, error) {
+	if s.boring != nil {
+		return s.boring.Write(p)
+	}
+	return s.h.Write(p)
+}
+
+// Sign returns the signature of the message written so far. It does not
+// change the underlying state, so more data may be written and Sign
+// called again.
+func (s *HashSigner) Sign() ([]byte, error) {
+	if s.boring != nil {
+		return s.boring.Sign()
+	}
+	return SignPKCS1v15(s.rand, s.priv, s.hash, s.h.Sum(nil))
+}
+
+// A HashVerifier verifies the signature of a message that is written to it
+// incrementally. In FIPS mode, both the digest and the verification are
+// computed by the OpenSSL module.
+type HashVerifier struct {
+	boring *boring.DigestVerifier
+
+	pub  *PublicKey
+	hash crypto.Hash
+	h    hash.Hash
+}
+
+// NewHashVerifierPKCS1v15 returns a HashVerifier that checks RSA PKCS #1 v1.5
+// signatures of the hash digest of the message written to it with pub.
+func NewHashVerifierPKCS1v15(pub *PublicKey, hash crypto.Hash) (*HashVerifier, error) {
+	if boring.Enabled() {
+		bkey, err := boringPublicKey(pub)
+		if err != nil {
+			return nil, err
+		}
+		b, err := boring.NewDigestVerifierRSAPKCS1v15(bkey, hash)
+		if err != nil {
+			return nil, err
+		}
+		return &HashVerifier{boring: b}, nil
+	}
+	boring.UnreachableExceptTests()
+
+	if !hash.Available() {
+		return nil, errors.New("crypto/rsa: unsupported hash function")
+	}
+	return &HashVerifier{pub: pub, hash: hash, h: hash.New()}, nil
+}
+
+// Write adds more data to the message being verified. It never returns an error.
+func (v *HashVerifier) Write(p []byte) (int, error) {
+	if v.boring != nil {
+		return v.boring.Write(p)
+	}
+	return v.h.Write(p)
+}
+
+// Verify checks that sig is a valid signature of the message written so far.
+// A valid signature is indicated by returning a nil error. Like Sign, it
+// does not change the underlying state.
+func (v *HashVerifier) Verify(sig []byte) error {
+	if v.boring != nil {
+		if err := v.boring.Verify(sig); err != nil {
+			return ErrVerification
+		}
+		return nil
+	}
+	return VerifyPKCS1v15(v.pub, v.hash, v.h.Sum(nil), sig)
+}
@@ -237,6 +237,72 @@ func TestHashVerifyPKCS1v15(t *testing.T) {
 	}
 }
 
+func TestHashSignerPKCS1v15(t *testing.T) {
+	for i, test := range signPKCS1v15Tests {
+		s, err := NewHashSignerPKCS1v15(rand.Reader, rsaPrivateKey, crypto.SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Write the message a byte at a time to exercise streaming.
+		for j := range test.in {
+			s.Write([]byte{test.in[j]})
+		}
+		sig, err := s.Sign()
+		if err != nil {
+			t.Errorf("#%d: Sign: %s", i, err)
+			continue
+		}
+		expected, _ := hex.DecodeString(test.out)
+		if !bytes.Equal(sig, expected) {
+			t.Errorf("#%d got: %x want: %x", i, sig, expected)
+		}
+
+		v, err := NewHashVerifierPKCS1v15(&rsaPrivateKey.PublicKey, crypto.SHA1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(v, test.in)
+		if err := v.Verify(sig); err != nil {
+			t.Errorf("#%d: Verify: %s", i, err)
+		}
+		v.Write([]byte("x"))
+		if err := v.Verify(sig); err == nil {
+			t.Errorf("#%d: Verify of modified message succeeded", i)
+		}
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += n
+	return n, err
+}
+
+// Test that SignMessage passes rand through to the signer so that the
+// private key operation is blinded.
+func TestSignMessagePKCS1v15Blinded(t *testing.T) {
+	if boring.Enabled() {
+		t.Skip("skipping in boring mode")
+	}
+	r := &countingReader{r: rand.Reader}
+	sig, err := rsaPrivateKey.SignMessage(r, []byte("hello"), crypto.SHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.n == 0 {
+		t.Error("SignMessage did not read from rand")
+	}
+	hashed := sha256.Sum256([]byte("hello"))
+	if err := VerifyPKCS1v15(&rsaPrivateKey.PublicKey, crypto.SHA256, hashed[:], sig); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestOverlongMessagePKCS1v15(t *testing.T) {
 	ciphertext := decodeBase64("fjOVdirUzFoLlukv80dBllMLjXythIf22feqPrNo0YoIjzyzyoMFiLjAc/Y4krkeZ11XFThIrEvw\nkRiZcCq5ng==")
 	_, err := DecryptPKCS1v15(nil, rsaPrivateKey, ciphertext)
@@ -340,3 +340,72 @@ func VerifyPSS(pub *PublicKey, hash crypto.Hash, digest []byte, sig []byte, opts
 	em := m.FillBytes(make([]byte, emLen))
 	return emsaPSSVerify(digest, em, emBits, opts.saltLength(), hash.New())
 }
+
+// HashSignPSS calculates the signature of msg using PSS, hashing it first
+// with hash. In FIPS mode the digest is computed by the OpenSSL module as
+// part of the signature operation, as strict mode requires.
+//
+// The opts argument may be nil, in which case sensible defaults are used.
+// opts.Hash is ignored.
+func HashSignPSS(rand io.Reader, priv *PrivateKey, hash crypto.Hash, msg []byte, opts *PSSOptions) ([]byte, error) {
+	saltLength := opts.saltLength()
+	switch saltLength {
+	case PSSSaltLengthAuto:
+		saltLength = priv.Size() - 2 - hash.Size()
+	case PSSSaltLengthEqualsHash:
+		saltLength = hash.Size()
+	}
+
+	if boring.Enabled() {
+		bkey, err := boringPrivateKey(priv)
+		if err != nil {
+			return nil, err
+		}
+		s, err := boring.NewDigestSignerRSAPSS(bkey, hash, saltLength)
+		if err != nil {
+			return nil, err
+		}
+		s.Write(msg)
+		return s.Sign()
+	}
+	boring.UnreachableExceptTests()
+
+	if !hash.Available() {
+		return nil, errors.New("crypto/rsa: unsupported hash function")
+	}
+	h := hash.New()
+	h.Write(msg)
+	return SignPSS(rand, priv, hash, h.Sum(nil), &PSSOptions{SaltLength: saltLength})
+}
+
+// HashVerifyPSS verifies a PSS signature of msg, hashing it first with hash.
+// In FIPS mode the digest is computed by the OpenSSL module as part of the
+// verification.
+//
+// A valid signature is indicated by returning a nil error. The opts argument
+// may be nil, in which case sensible defaults are used. opts.Hash is ignored.
+func HashVerifyPSS(pub *PublicKey, hash crypto.Hash, msg []byte, sig []byte, opts *PSSOptions) error {
+	if boring.Enabled() {
+		bkey, err := boringPublicKey(pub)
+		if err != nil {
+			return err
+		}
+		v, err := boring.NewDigestVerifierRSAPSS(bkey, hash, opts.saltLength())
+		if err != nil {
+			return err
+		}
+		v.Write(msg)
+		if err := v.Verify(sig); err != nil {
+			return ErrVerification
+		}
+		return nil
+	}
+	boring.UnreachableExceptTests()
+
+	if !hash.Available() {
+		return errors.New("crypto/rsa: unsupported hash function")
+	}
+	h := hash.New()
+	h.Write(msg)
+	return VerifyPSS(pub, hash, h.Sum(nil), sig, &PSSOptions{SaltLength: opts.saltLength()})
+}
@@ -9,9 +9,11 @@ import (
 	"bytes"
 	"compress/bzip2"
 	"crypto"
+	"crypto/internal/boring"
 	"crypto/rand"
 	"crypto/sha1"
 	_ "crypto/sha256"
+	_ "crypto/sha3"
 	"encoding/hex"
 	"math/big"
 	"os"
@@ -233,6 +235,88 @@ func TestPSSSigning(t *testing.T) {
 	}
 }
 
+func TestHashSignPSS(t *testing.T) {
+	var saltLengthCombinations = []struct {
+		signSaltLength, verifySaltLength int
+		good                             bool
+	}{
+		{PSSSaltLengthAuto, PSSSaltLengthAuto, true},
+		{PSSSaltLengthEqualsHash, PSSSaltLengthAuto, true},
+		{PSSSaltLengthEqualsHash, PSSSaltLengthEqualsHash, true},
+		{PSSSaltLengthEqualsHash, 8, false},
+		{PSSSaltLengthAuto, PSSSaltLengthEqualsHash, false},
+		{8, 8, true},
+	}
+
+	hash := crypto.SHA256
+	msg := []byte("testing")
+	h := hash.New()
+	h.Write(msg)
+	hashed := h.Sum(nil)
+
+	for i, test := range saltLengthCombinations {
+		sig, err := HashSignPSS(rand.Reader, test2048Key, hash, msg, &PSSOptions{SaltLength: test.signSaltLength})
+		if err != nil {
+			t.Errorf("#%d: error while signing: %s", i, err)
+			continue
+		}
+
+		opts := &PSSOptions{SaltLength: test.verifySaltLength}
+		err = HashVerifyPSS(&test2048Key.PublicKey, hash, msg, sig, opts)
+		if (err == nil) != test.good {
+			t.Errorf("#%d: bad result, wanted: %t, got: %s", i, test.good, err)
+		}
+		// The signature must also be valid over the precomputed digest.
+		err = VerifyPSS(&test2048Key.PublicKey, hash, hashed, sig, opts)
+		if (err == nil) != test.good {
+			t.Errorf("#%d: VerifyPSS: bad result, wanted: %t, got: %s", i, test.good, err)
+		}
+	}
+
+	sig, err := HashSignPSS(rand.Reader, test2048Key, hash, msg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := HashVerifyPSS(&test2048Key.PublicKey, hash, []byte("Testing"), sig, nil); err == nil {
+		t.Error("HashVerifyPSS succeeded on a modified message")
+	}
+}
+
+func TestPSSSigningSHA3(t *testing.T) {
+	for _, hash := range []crypto.Hash{crypto.SHA3_256, crypto.SHA3_512} {
+		if boring.Enabled() && !boring.SupportsHash(hash) {
+			t.Logf("%v: skipping, not supported by the OpenSSL module", hash)
+			continue
+		}
+		h := hash.New()
+		h.Write([]byte("testing"))
+		hashed := h.Sum(nil)
+
+		opts := &PSSOptions{SaltLength: PSSSaltLengthEqualsHash}
+		sig, err := SignPSS(rand.Reader, test2048Key, hash, hashed, opts)
+		if err != nil {
+			t.Errorf("%v: error while signing: %s", hash, err)
+			continue
+		}
+		if err := VerifyPSS(&test2048Key.PublicKey, hash, hashed, sig, opts); err != nil {
+			t.Errorf("%v: error while verifying: %s", hash, err)
+		}
+		hashed[0] ^= 0xff
+		if err := VerifyPSS(&test2048Key.PublicKey, hash, hashed, sig, opts); err == nil {
+			t.Errorf("%v: verification of modified hash succeeded", hash)
+		}
+
+		sig, err = HashSignPSS(rand.Reader, test2048Key, hash, []byte("testing"), opts)
+		if err != nil {
+			t.Errorf("%v: error while signing the message: %s", hash, err)
+			continue
+		}
+		if err := HashVerifyPSS(&test2048Key.PublicKey, hash, []byte("testing"), sig, opts); err != nil {
+			t.Errorf("%v: error while verifying the message: %s", hash, err)
+		}
+	}
+}
+
 func bigFromHex(hex string) *big.Int {
 	n, ok := new(big.Int).SetString(hex, 16)
 	if !ok {
@@ -336,6 +336,44 @@ func TestEncryptDecryptOAEP(t *testing.T) {
 	}
 }
 
+func TestOAEPMGFHash(t *testing.T) {
+	// Generated with OpenSSL using SHA-256 OAEP, an MGF1-SHA1 mask and
+	// the label "kms".
+	ciphertext := fromHex("1eddb5e4947a39683a8b1fcd24ba73e799684c976614a7b8679b337e9a0c84c21e348ac789ad8fc470051804a15c302b0ff6c981fcea4bdcc2b0fca2dede41fe100e319316760b8df6b33edc629bf014b6b2d0e6f29e925031a326fb52db3387a394083317fe8da3818bf503cdff869d6f3d014aa8035340b3ba96f1587de386975b3d6304e4060da6912f37bb12133bfc414ac82568822e6da72fd0045cc2aae67186d4b53440f514db9dc508c7de23658ed6d0f6784a3422dadcde4f5f1b05dfef0aeb26d836f82ecf2314946c36e3b8342a3b9287ba5c261695a684e2ca57df46ffb1b1ff67c4da7457e044f1ca49de21a184fb0d5dee485d78fcd6d4dbbe")
+	opts := &OAEPOptions{Hash: crypto.SHA256, MGFHash: crypto.SHA1, Label: []byte("kms")}
+	dec, err := test2048Key.Decrypt(nil, ciphertext, opts)
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if string(dec) != "hello, world" {
+		t.Errorf("Decrypt = %q, want %q", dec, "hello, world")
+	}
+	if _, err := test2048Key.Decrypt(nil, ciphertext, &OAEPOptions{Hash: crypto.SHA256, Label: opts.Label}); err == nil {
+		t.Errorf("Decrypt with MGF1-SHA256 succeeded, want error")
+	}
+
+	for _, opts := range []*OAEPOptions{
+		{Hash: crypto.SHA256, MGFHash: crypto.SHA1},
+		{Hash: crypto.SHA1, MGFHash: crypto.SHA256, Label: []byte("label")},
+		{Hash: crypto.SHA256},
+	} {
+		msg := []byte("round trip")
+		enc, err := EncryptOAEPWithOptions(rand.Reader, &test2048Key.PublicKey, msg, opts)
+		if err != nil {
+			t.Errorf("%v/%v: EncryptOAEPWithOptions: %v", opts.Hash, opts.MGFHash, err)
+			continue
+		}
+		dec, err := test2048Key.Decrypt(rand.Reader, enc, opts)
+		if err != nil {
+			t.Errorf("%v/%v: Decrypt: %v", opts.Hash, opts.MGFHash, err)
+			continue
+		}
+		if !bytes.Equal(dec, msg) {
+			t.Errorf("%v/%v: round trip %q -> %q", opts.Hash, opts.MGFHash, msg, dec)
+		}
+	}
+}
+
 // testEncryptOAEPData contains a subset of the vectors from RSA's "Test vectors for RSA-OAEP".
 var testEncryptOAEPData = []testEncryptOAEPStruct{
 	// Key 1
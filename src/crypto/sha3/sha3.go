@@ -0,0 +1,199 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package sha3 implements the SHA-3 fixed-output-length hash functions and
+// the SHAKE variable-output-length hash functions defined by FIPS 202.
+//
+// When FIPS mode is enabled and the OpenSSL library provides them, the
+// hashes are computed by OpenSSL.
+package sha3
+
+import (
+	"crypto"
+	"encoding/binary"
+	"hash"
+)
+
+import "crypto/internal/boring"
+
+func init() {
+	crypto.RegisterHash(crypto.SHA3_224, New224)
+	crypto.RegisterHash(crypto.SHA3_256, New256)
+	crypto.RegisterHash(crypto.SHA3_384, New384)
+	crypto.RegisterHash(crypto.SHA3_512, New512)
+}
+
+const (
+	// The domain separation bytes of FIPS 202, including the first
+	// bit of the pad10*1 padding.
+	dsbyteSHA3  = 0x06
+	dsbyteShake = 0x1f
+
+	// maxRate is the rate, in bytes, of SHAKE128, the largest of all
+	// the functions in this package.
+	maxRate = 168
+)
+
+// state represents the partial evaluation of a Keccak sponge.
+type state struct {
+	a         [25]uint64
+	buf       [maxRate]byte
+	n         int // bytes buffered when absorbing, or consumed when squeezing
+	rate      int
+	dsbyte    byte
+	outputLen int
+	squeezing bool
+}
+
+func (d *state) BlockSize() int { return d.rate }
+func (d *state) Size() int      { return d.outputLen }
+
+func (d *state) Reset() {
+	d.a = [25]uint64{}
+	d.buf = [maxRate]byte{}
+	d.n = 0
+	d.squeezing = false
+}
+
+// xorIn absorbs a full block from d.buf into the state.
+func (d *state) xorIn() {
+	for i := 0; i < d.rate/8; i++ {
+		d.a[i] ^= binary.LittleEndian.Uint64(d.buf[i*8:])
+	}
+}
+
+// copyOut stores a full block of output from the state into d.buf.
+func (d *state) copyOut() {
+	for i := 0; i < d.rate/8; i++ {
+		binary.LittleEndian.PutUint64(d.buf[i*8:], d.a[i])
+	}
+}
+
+func (d *state) Write(p []byte) (int, error) {
+	if d.squeezing {
+		panic("sha3: Write after Read")
+	}
+	nn := len(p)
+	for len(p) > 0 {
+		n := copy(d.buf[d.n:d.rate], p)
+		d.n += n
+		p = p[n:]
+		if d.n == d.rate {
+			d.xorIn()
+			keccakF1600(&d.a)
+			d.n = 0
+		}
+	}
+	return nn, nil
+}
+
+// padAndPermute appends the domain separation bits and the final bit of
+// the padding, and switches the sponge to the squeezing phase.
+func (d *state) padAndPermute() {
+	d.buf[d.n] = d.dsbyte
+	for i := d.n + 1; i < d.rate; i++ {
+		d.buf[i] = 0
+	}
+	d.buf[d.rate-1] ^= 0x80
+	d.xorIn()
+	keccakF1600(&d.a)
+	d.copyOut()
+	d.n = 0
+	d.squeezing = true
+}
+
+// Read squeezes an arbitrary number of bytes from the sponge.
+func (d *state) Read(out []byte) (int, error) {
+	if !d.squeezing {
+		d.padAndPermute()
+	}
+	nn := len(out)
+	for len(out) > 0 {
+		if d.n == d.rate {
+			keccakF1600(&d.a)
+			d.copyOut()
+			d.n = 0
+		}
+		n := copy(out, d.buf[d.n:d.rate])
+		d.n += n
+		out = out[n:]
+	}
+	return nn, nil
+}
+
+func (d *state) Sum(in []byte) []byte {
+	if d.squeezing {
+		panic("sha3: Sum after Read")
+	}
+	// Make a copy of d so that caller can keep writing and summing.
+	d0 := *d
+	hash := make([]byte, d0.outputLen)
+	d0.Read(hash)
+	return append(in, hash...)
+}
+
+func newState(rate, outputLen int, dsbyte byte) *state {
+	return &state{rate: rate, outputLen: outputLen, dsbyte: dsbyte}
+}
+
+// New224 returns a new hash.Hash computing the SHA3-224 checksum.
+func New224() hash.Hash {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA3_224) {
+		return boring.NewSHA3_224()
+	}
+	return newState(144, 28, dsbyteSHA3)
+}
+
+// New256 returns a new hash.Hash computing the SHA3-256 checksum.
+func New256() hash.Hash {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA3_256) {
+		return boring.NewSHA3_256()
+	}
+	return newState(136, 32, dsbyteSHA3)
+}
+
+// New384 returns a new hash.Hash computing the SHA3-384 checksum.
+func New384() hash.Hash {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA3_384) {
+		return boring.NewSHA3_384()
+	}
+	return newState(104, 48, dsbyteSHA3)
+}
+
+// New512 returns a new hash.Hash computing the SHA3-512 checksum.
+func New512() hash.Hash {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA3_512) {
+		return boring.NewSHA3_512()
+	}
+	return newState(72, 64, dsbyteSHA3)
+}
+
+func sum(h hash.Hash, data []byte) []byte {
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// Sum224 returns the SHA3-224 checksum of the data.
+func Sum224(data []byte) (digest [28]byte) {
+	copy(digest[:], sum(New224(), data))
+	return
+}
+
+// Sum256 returns the SHA3-256 checksum of the data.
+func Sum256(data []byte) (digest [32]byte) {
+	copy(digest[:], sum(New256(), data))
+	return
+}
+
+// Sum384 returns the SHA3-384 checksum of the data.
+func Sum384(data []byte) (digest [48]byte) {
+	copy(digest[:], sum(New384(), data))
+	return
+}
+
+// Sum512 returns the SHA3-512 checksum of the data.
+func Sum512(data []byte) (digest [64]byte) {
+	copy(digest[:], sum(New512(), data))
+	return
+}
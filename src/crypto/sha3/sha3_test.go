@@ -0,0 +1,180 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+import (
+	"bytes"
+	"crypto"
+	"encoding/hex"
+	"hash"
+	"strings"
+	"testing"
+)
+
+type sha3Test struct {
+	out string
+	in  string
+}
+
+var a3x200 = strings.Repeat("\xa3", 200)
+
+var golden = []struct {
+	name  string
+	newFn func() hash.Hash
+	tests []sha3Test
+}{
+	{"SHA3-224", New224, []sha3Test{
+		{"6b4e03423667dbb73b6e15454f0eb1abd4597f9a1b078e3f5b5a6bc7", ""},
+		{"e642824c3f8cf24ad09234ee7d3c766fc9a3a5168d0c94ad73b46fdf", "abc"},
+		{"9376816aba503f72f96ce7eb65ac095deee3be4bf9bbc2a1cb7e11e0", a3x200},
+	}},
+	{"SHA3-256", New256, []sha3Test{
+		{"a7ffc6f8bf1ed76651c14756a061d662f580ff4de43b49fa82d80a4b80f8434a", ""},
+		{"3a985da74fe225b2045c172d6bd390bd855f086e3e9d525b46bfe24511431532", "abc"},
+		{"79f38adec5c20307a98ef76e8324afbfd46cfd81b22e3973c65fa1bd9de31787", a3x200},
+	}},
+	{"SHA3-384", New384, []sha3Test{
+		{"0c63a75b845e4f7d01107d852e4c2485c51a50aaaa94fc61995e71bbee983a2ac3713831264adb47fb6bd1e058d5f004", ""},
+		{"ec01498288516fc926459f58e2c6ad8df9b473cb0fc08c2596da7cf0e49be4b298d88cea927ac7f539f1edf228376d25", "abc"},
+		{"1881de2ca7e41ef95dc4732b8f5f002b189cc1e42b74168ed1732649ce1dbcdd76197a31fd55ee989f2d7050dd473e8f", a3x200},
+	}},
+	{"SHA3-512", New512, []sha3Test{
+		{"a69f73cca23a9ac5c8b567dc185a756e97c982164fe25859e0d1dcc1475c80a615b2123af1f5f94c11e3e9402c3ac558f500199d95b6d3e301758586281dcd26", ""},
+		{"b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0", "abc"},
+		{"e76dfad22084a8b1467fcf2ffa58361bec7628edf5f3fdc0e4805dc48caeeca81b7c13c30adf52a3659584739a2df46be589c51ca1a4a8416df6545a1ce8ba00", a3x200},
+	}},
+	{"SHAKE128", func() hash.Hash { return NewShake128() }, []sha3Test{
+		{"7f9c2ba4e88f827d616045507605853ed73b8093f6efbc88eb1a6eacfa66ef26", ""},
+		{"131ab8d2b594946b9c81333f9bb6e0ce75c3b93104fa3469d3917457385da037", a3x200},
+	}},
+	{"SHAKE256", func() hash.Hash { return NewShake256() }, []sha3Test{
+		{"46b9dd2b0ba88d13233b3feb743eeb243fcd52ea62b81b82b50c27646ed5762fd75dc4ddd8c0f200cb05019d67b592f6fc821c49479ab48640292eacb3b7c4be", ""},
+		{"cd8a920ed141aa0407a22d59288652e9d9f1a7ee0c1e7c1ca699424da84a904d2d700caae7396ece96604440577da4f3aa22aeb8857f961c4cd8e06f0ae6610b", a3x200},
+	}},
+}
+
+func TestGolden(t *testing.T) {
+	for _, g := range golden {
+		for _, tt := range g.tests {
+			c := g.newFn()
+			for j := 0; j < 3; j++ {
+				if j < 2 {
+					c.Write([]byte(tt.in))
+				} else {
+					c.Write([]byte(tt.in[0 : len(tt.in)/2]))
+					c.Sum(nil)
+					c.Write([]byte(tt.in[len(tt.in)/2:]))
+				}
+				s := hex.EncodeToString(c.Sum(nil))
+				if s != tt.out {
+					t.Fatalf("%s[%d](%q) = %s want %s", g.name, j, tt.in, s, tt.out)
+				}
+				c.Reset()
+			}
+		}
+	}
+}
+
+func TestSums(t *testing.T) {
+	in := []byte("abc")
+	check := func(name string, got []byte, h hash.Hash) {
+		h.Write(in)
+		if want := h.Sum(nil); !bytes.Equal(got, want) {
+			t.Errorf("%s = %x, want %x", name, got, want)
+		}
+	}
+	s224 := Sum224(in)
+	check("Sum224", s224[:], New224())
+	s256 := Sum256(in)
+	check("Sum256", s256[:], New256())
+	s384 := Sum384(in)
+	check("Sum384", s384[:], New384())
+	s512 := Sum512(in)
+	check("Sum512", s512[:], New512())
+}
+
+// shake128abc is the first 300 bytes of SHAKE128("abc").
+const shake128abc = "5881092dd818bf5cf8a3ddb793fbcba74097d5c526a6d35f97b83351940f2cc844c50af32acd3f2cdd066568706f509bc1bdde58295dae3f891a9a0fca5783789a41f8611214ce612394df286a62d1a2252aa94db9c538956c717dc2bed4f232a0294c857c730aa16067ac1062f1201fb0d377cfb9cde4c63599b27f3462bba4a0ed296c801f9ff7f57302bb3076ee145f97a32ae68e76ab66c48d51675bd49acc29082f5647584e6aa01b3f5af057805f973ff8ecb8b226ac32ada6f01c1fcd4818cb006aa5b4cdb3611eb1e533c8964cacfdf31012cd3fb744d02225b988b475375faad996eb1b9176ecb0f8b2871723d6dbb804e23357e50732f5cfc904b1319795000d7361d9e5e1b77b4b8f5774aa1482cfa58f83096bdb2e06a3eed543a38919b57ecbec737f4086be"
+
+// TestShakeRead checks that squeezing output in pieces of various sizes,
+// including across rate boundaries, matches the one-shot output.
+func TestShakeRead(t *testing.T) {
+	want, _ := hex.DecodeString(shake128abc)
+	for _, step := range []int{1, 7, 32, 168, 169, 300} {
+		h := NewShake128()
+		h.Write([]byte("abc"))
+		var got []byte
+		for len(got) < len(want) {
+			n := step
+			if len(got)+n > len(want) {
+				n = len(want) - len(got)
+			}
+			buf := make([]byte, n)
+			h.Read(buf)
+			got = append(got, buf...)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("step %d: got %x, want %x", step, got, want)
+		}
+	}
+
+	got := make([]byte, len(want))
+	ShakeSum128(got, []byte("abc"))
+	if !bytes.Equal(got, want) {
+		t.Errorf("ShakeSum128 = %x, want %x", got, want)
+	}
+}
+
+func TestWriteAfterRead(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Write after Read did not panic")
+		}
+	}()
+	h := NewShake256()
+	h.Read(make([]byte, 1))
+	h.Write([]byte("x"))
+}
+
+func TestRegistered(t *testing.T) {
+	for _, h := range []crypto.Hash{crypto.SHA3_224, crypto.SHA3_256, crypto.SHA3_384, crypto.SHA3_512} {
+		if !h.Available() {
+			t.Errorf("%v is not available", h)
+			continue
+		}
+		if got, want := h.New().Size(), h.Size(); got != want {
+			t.Errorf("%v: Size = %d, want %d", h, got, want)
+		}
+	}
+}
+
+func TestBlockSize(t *testing.T) {
+	for _, tt := range []struct {
+		h    hash.Hash
+		want int
+	}{
+		{New224(), 144},
+		{New256(), 136},
+		{New384(), 104},
+		{New512(), 72},
+		{NewShake128(), 168},
+		{NewShake256(), 136},
+	} {
+		if got := tt.h.BlockSize(); got != tt.want {
+			t.Errorf("BlockSize = %d, want %d", got, tt.want)
+		}
+	}
+}
+
+func BenchmarkSHA3_256(b *testing.B) {
+	buf := make([]byte, 8192)
+	h := New256()
+	b.SetBytes(int64(len(buf)))
+	for i := 0; i < b.N; i++ {
+		h.Reset()
+		h.Write(buf)
+		h.Sum(nil)
+	}
+}
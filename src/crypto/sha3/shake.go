@@ -0,0 +1,60 @@
+// Copyright 2021 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package sha3
+
+import (
+	"hash"
+	"io"
+)
+
+import "crypto/internal/boring"
+
+// ShakeHash defines the interface to hash functions that
+// support arbitrary-length output.
+//
+// Sum appends the function's default output length (32 bytes for
+// SHAKE128, 64 bytes for SHAKE256) and, like Write, must not be
+// called after Read.
+type ShakeHash interface {
+	hash.Hash
+
+	// Read reads more output from the hash; reading affects the hash's
+	// state. It never returns an error.
+	io.Reader
+}
+
+// NewShake128 creates a new SHAKE128 variable-output-length ShakeHash.
+// Its generic security strength is 128 bits against all attacks if at
+// least 32 bytes of its output are used.
+func NewShake128() ShakeHash {
+	if boring.Enabled() && boring.SupportsSHAKE() {
+		return boring.NewSHAKE128().(ShakeHash)
+	}
+	return newState(168, 32, dsbyteShake)
+}
+
+// NewShake256 creates a new SHAKE256 variable-output-length ShakeHash.
+// Its generic security strength is 256 bits against all attacks if
+// at least 64 bytes of its output are used.
+func NewShake256() ShakeHash {
+	if boring.Enabled() && boring.SupportsSHAKE() {
+		return boring.NewSHAKE256().(ShakeHash)
+	}
+	return newState(136, 64, dsbyteShake)
+}
+
+// ShakeSum128 writes an arbitrary-length digest of data into hash.
+func ShakeSum128(hash, data []byte) {
+	h := NewShake128()
+	h.Write(data)
+	h.Read(hash)
+}
+
+// ShakeSum256 writes an arbitrary-length digest of data into hash.
+func ShakeSum256(hash, data []byte) {
+	h := NewShake256()
+	h.Write(data)
+	h.Read(hash)
+}
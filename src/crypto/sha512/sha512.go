@@ -223,6 +223,9 @@ func New() hash.Hash {
 
 // New512_224 returns a new hash.Hash computing the SHA-512/224 checksum.
 func New512_224() hash.Hash {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA512_224) {
+		return boring.NewSHA512_224()
+	}
 	d := &digest{function: crypto.SHA512_224}
 	d.Reset()
 	return d
@@ -230,6 +233,9 @@ func New512_224() hash.Hash {
 
 // New512_256 returns a new hash.Hash computing the SHA-512/256 checksum.
 func New512_256() hash.Hash {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA512_256) {
+		return boring.NewSHA512_256()
+	}
 	d := &digest{function: crypto.SHA512_256}
 	d.Reset()
 	return d
@@ -376,6 +382,12 @@ func Sum384(data []byte) (sum384 [Size384]byte) {
 
 // Sum512_224 returns the Sum512/224 checksum of the data.
 func Sum512_224(data []byte) (sum224 [Size224]byte) {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA512_224) {
+		h := New512_224()
+		h.Write(data)
+		h.Sum(sum224[:0])
+		return
+	}
 	d := digest{function: crypto.SHA512_224}
 	d.Reset()
 	d.Write(data)
@@ -386,6 +398,12 @@ func Sum512_224(data []byte) (sum224 [Size224]byte) {
 
 // Sum512_256 returns the Sum512/256 checksum of the data.
 func Sum512_256(data []byte) (sum256 [Size256]byte) {
+	if boring.Enabled() && boring.SupportsHash(crypto.SHA512_256) {
+		h := New512_256()
+		h.Write(data)
+		h.Sum(sum256[:0])
+		return
+	}
 	d := digest{function: crypto.SHA512_256}
 	d.Reset()
 	d.Write(data)
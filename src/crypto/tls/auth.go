@@ -17,8 +17,10 @@ import (
 	"io"
 )
 
-// verifyHandshakeSignature verifies a signature against pre-hashed
-// (if required) handshake contents.
+// verifyHandshakeSignature verifies a signature against the handshake
+// contents signed, which are hashed with hashFunc (unless it is
+// directSigning) as part of the verification. It is used from TLS 1.2 on,
+// so that in FIPS mode the digest is computed by the crypto module.
 func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc crypto.Hash, signed, sig []byte) error {
 	switch sigType {
 	case signatureECDSA:
@@ -26,7 +28,12 @@ func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc c
 		if !ok {
 			return fmt.Errorf("expected an ECDSA public key, got %T", pubkey)
 		}
-		if !ecdsa.VerifyASN1(pubKey, signed, sig) {
+		v, err := ecdsa.NewHashVerifier(pubKey, hashFunc)
+		if err != nil {
+			return err
+		}
+		v.Write(signed)
+		if !v.Verify(sig) {
 			return errors.New("ECDSA verification failure")
 		}
 	case signatureEd25519:
@@ -42,7 +49,7 @@ func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc c
 		if !ok {
 			return fmt.Errorf("expected an RSA public key, got %T", pubkey)
 		}
-		if err := rsa.VerifyPKCS1v15(pubKey, hashFunc, signed, sig); err != nil {
+		if err := rsa.HashVerifyPKCS1v15(pubKey, hashFunc, signed, sig); err != nil {
 			return err
 		}
 	case signatureRSAPSS:
@@ -51,7 +58,33 @@ func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc c
 			return fmt.Errorf("expected an RSA public key, got %T", pubkey)
 		}
 		signOpts := &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash}
-		if err := rsa.VerifyPSS(pubKey, hashFunc, signed, sig, signOpts); err != nil {
+		if err := rsa.HashVerifyPSS(pubKey, hashFunc, signed, sig, signOpts); err != nil {
+			return err
+		}
+	default:
+		return errors.New("internal error: unknown signature type")
+	}
+	return nil
+}
+
+// verifyLegacyHandshakeSignature verifies a signature against pre-hashed
+// handshake contents, as produced before TLS 1.2.
+func verifyLegacyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc crypto.Hash, digest, sig []byte) error {
+	switch sigType {
+	case signatureECDSA:
+		pubKey, ok := pubkey.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("expected an ECDSA public key, got %T", pubkey)
+		}
+		if !ecdsa.VerifyASN1(pubKey, digest, sig) {
+			return errors.New("ECDSA verification failure")
+		}
+	case signaturePKCS1v15:
+		pubKey, ok := pubkey.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("expected an RSA public key, got %T", pubkey)
+		}
+		if err := rsa.VerifyPKCS1v15(pubKey, hashFunc, digest, sig); err != nil {
 			return err
 		}
 	default:
@@ -60,6 +93,23 @@ func verifyHandshakeSignature(sigType uint8, pubkey crypto.PublicKey, hashFunc c
 	return nil
 }
 
+// signHandshake signs the handshake contents signed with signer, hashing
+// them with opts.HashFunc() unless it is directSigning. Signers that
+// implement crypto.MessageSigner are handed the unhashed contents, so that
+// in FIPS mode the digest is computed by the crypto module.
+func signHandshake(rand io.Reader, signer crypto.Signer, signed []byte, opts crypto.SignerOpts) ([]byte, error) {
+	sigHash := opts.HashFunc()
+	if sigHash == directSigning {
+		return signer.Sign(rand, signed, opts)
+	}
+	if ms, ok := signer.(crypto.MessageSigner); ok {
+		return ms.SignMessage(rand, signed, opts)
+	}
+	h := sigHash.New()
+	h.Write(signed)
+	return signer.Sign(rand, h.Sum(nil), opts)
+}
+
 const (
 	serverSignatureContext = "TLS 1.3, server CertificateVerify\x00"
 	clientSignatureContext = "TLS 1.3, client CertificateVerify\x00"
@@ -76,21 +126,14 @@ var signaturePadding = []byte{
 	0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20, 0x20,
 }
 
-// signedMessage returns the pre-hashed (if necessary) message to be signed by
-// certificate keys in TLS 1.3. See RFC 8446, Section 4.4.3.
-func signedMessage(sigHash crypto.Hash, context string, transcript hash.Hash) []byte {
-	if sigHash == directSigning {
-		b := &bytes.Buffer{}
-		b.Write(signaturePadding)
-		io.WriteString(b, context)
-		b.Write(transcript.Sum(nil))
-		return b.Bytes()
-	}
-	h := sigHash.New()
-	h.Write(signaturePadding)
-	io.WriteString(h, context)
-	h.Write(transcript.Sum(nil))
-	return h.Sum(nil)
+// signedMessage returns the message to be signed by certificate keys in
+// TLS 1.3, before hashing. See RFC 8446, Section 4.4.3.
+func signedMessage(context string, transcript hash.Hash) []byte {
+	b := &bytes.Buffer{}
+	b.Write(signaturePadding)
+	io.WriteString(b, context)
+	b.Write(transcript.Sum(nil))
+	return b.Bytes()
 }
 
 // typeAndHashFromSignatureScheme returns the corresponding signature type and
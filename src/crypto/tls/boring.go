@@ -6,6 +6,7 @@ package tls
 
 import (
 	"crypto/ecdsa"
+	"crypto/internal/boring"
 	"crypto/internal/boring/fipstls"
 	"crypto/rsa"
 	"crypto/x509"
@@ -77,6 +78,29 @@ func fipsCipherSuites(c *Config) []uint16 {
 	return list
 }
 
+// supportsConstantTimeTLSMAC is a variable so that tests can take the
+// constant-time MAC away.
+var supportsConstantTimeTLSMAC = boring.SupportsConstantTimeTLSMAC
+
+// boringCipherSuites removes the CBC cipher suites from list when the
+// BoringCrypto HMAC cannot check their records in constant time, which is
+// the case in FIPS mode with a library that has no provider HMAC. They
+// would otherwise be left to tls10MAC, whose time depends on the padding.
+func boringCipherSuites(list []uint16) []uint16 {
+	if !boring.Enabled() || supportsConstantTimeTLSMAC() {
+		return list
+	}
+	var out []uint16
+	for _, id := range list {
+		// The CBC suites are the only ones with both a MAC and an IV.
+		if c := cipherSuiteByID(id); c != nil && c.mac != nil && c.ivLen > 0 {
+			continue
+		}
+		out = append(out, id)
+	}
+	return out
+}
+
 // isBoringCertificate reports whether a certificate may be used
 // when constructing a verified chain.
 // It is called for each leaf, intermediate, and root certificate.
@@ -147,6 +147,45 @@ func TestBoringServerCipherSuites(t *testing.T) {
 	}
 }
 
+func TestBoringCBCWithoutConstantTimeMAC(t *testing.T) {
+	if !boring.Enabled() {
+		t.Skip("boringcrypto: skipping test, FIPS not enabled")
+	}
+	// The CBC suites are not FIPS-approved, so they are only negotiated
+	// once fipstls is abandoned.
+	if fipstls.Required() {
+		fipstls.Abandon()
+		defer fipstls.Force()
+	}
+	defer func(f func() bool) { supportsConstantTimeTLSMAC = f }(supportsConstantTimeTLSMAC)
+
+	for _, constantTime := range []bool{true, false} {
+		supportsConstantTimeTLSMAC = func() bool { return constantTime }
+		config := &Config{CipherSuites: allCipherSuites()}
+		for _, id := range config.cipherSuites() {
+			if c := cipherSuiteByID(id); !constantTime && c != nil && c.mac != nil && c.ivLen > 0 {
+				t.Errorf("CBC cipher suite %#x enabled without a constant-time MAC", id)
+			}
+		}
+
+		for _, id := range []uint16{TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA, TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256} {
+			clientConfig := testConfig.Clone()
+			clientConfig.MaxVersion = VersionTLS12
+			clientConfig.CipherSuites = []uint16{id}
+			serverConfig := testConfig.Clone()
+			serverConfig.CipherSuites = []uint16{id}
+			clientErr, serverErr := boringHandshake(t, clientConfig, serverConfig)
+			if cbc := id == TLS_ECDHE_RSA_WITH_AES_128_CBC_SHA; cbc && !constantTime {
+				if serverErr == nil {
+					t.Errorf("suite %#x negotiated without a constant-time MAC", id)
+				}
+			} else if clientErr != nil || serverErr != nil {
+				t.Errorf("suite %#x, constant-time MAC %v: handshake failed: client %v, server %v", id, constantTime, clientErr, serverErr)
+			}
+		}
+	}
+}
+
 func TestBoringServerCurves(t *testing.T) {
 	serverConfig := testConfig.Clone()
 	serverConfig.Certificates = make([]Certificate, 1)
@@ -253,7 +253,9 @@ func cipherAES(key, iv []byte, isRead bool) interface{} {
 func macSHA1(key []byte) hash.Hash {
 	h := sha1.New
 	// The BoringCrypto SHA1 does not have a constant-time
-	// checksum function, so don't try to use it.
+	// checksum function, so don't try to use it. The BoringCrypto
+	// HMAC checks received records with its own constant-time
+	// implementation instead, see constantTimeTLSMAC.
 	if !boring.Enabled() {
 		h = newConstantTimeHash(h)
 	}
@@ -421,6 +423,18 @@ func newConstantTimeHash(h func() hash.Hash) func() hash.Hash {
 	}
 }
 
+// constantTimeTLSMAC is implemented by MACs, such as the BoringCrypto HMAC,
+// that compute the MAC of a received CBC record in time independent of the
+// length of its padding, which tls10MAC only does with a constantTimeHash.
+type constantTimeTLSMAC interface {
+	// ConstantTimeTLSSum appends to out the MAC of header (the sequence
+	// number and record header) followed by record[:n], hashing all of
+	// record. It returns nil if the MAC does not support it, which for
+	// a CBC record only happens if boringCipherSuites failed to keep the
+	// cipher suite from being negotiated.
+	ConstantTimeTLSSum(out, header, record []byte, n int) []byte
+}
+
 // tls10MAC implements the TLS 1.0 MAC function. RFC 2246, Section 6.2.3.
 func tls10MAC(h hash.Hash, out, seq, header, data, extra []byte) []byte {
 	h.Reset()
@@ -932,7 +932,7 @@ func (c *Config) cipherSuites() []uint16 {
 	if s == nil {
 		s = defaultCipherSuites()
 	}
-	return s
+	return boringCipherSuites(s)
 }
 
 var supportedVersions = []uint16{
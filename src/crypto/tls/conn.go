@@ -334,6 +334,7 @@ func (hc *halfConn) decrypt(record []byte) ([]byte, recordType, error) {
 
 	paddingGood := byte(255)
 	paddingLen := 0
+	cbc := false
 
 	explicitNonceLen := hc.explicitNonceLen()
 
@@ -386,6 +387,7 @@ func (hc *halfConn) decrypt(record []byte) ([]byte, recordType, error) {
 			// long as the digest computation is constant time and does not
 			// affect the subsequent write, modulo cache effects.
 			paddingLen, paddingGood = extractPadding(payload)
+			cbc = true
 		default:
 			panic("unknown cipher type")
 		}
@@ -424,7 +426,17 @@ func (hc *halfConn) decrypt(record []byte) ([]byte, recordType, error) {
 		record[3] = byte(n >> 8)
 		record[4] = byte(n)
 		remoteMAC := payload[n : n+macSize]
-		localMAC := tls10MAC(hc.mac, hc.scratchBuf[:0], hc.seq[:], record[:recordHeaderLen], payload[:n], payload[n+macSize:])
+		var localMAC []byte
+		if m, ok := hc.mac.(constantTimeTLSMAC); ok {
+			header := append(append(hc.scratchBuf[:0], hc.seq[:]...), record[:recordHeaderLen]...)
+			localMAC = m.ConstantTimeTLSSum(nil, header, payload, n)
+			if localMAC == nil && cbc {
+				return nil, 0, alertInternalError
+			}
+		}
+		if localMAC == nil {
+			localMAC = tls10MAC(hc.mac, hc.scratchBuf[:0], hc.seq[:], record[:recordHeaderLen], payload[:n], payload[n+macSize:])
+		}
 
 		// This is equivalent to checking the MACs and paddingGood
 		// separately, but in constant-time to prevent distinguishing
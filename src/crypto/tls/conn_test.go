@@ -6,6 +6,9 @@ package tls
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"hash"
 	"io"
 	"net"
 	"testing"
@@ -66,6 +69,95 @@ func TestRemovePadding(t *testing.T) {
 	}
 }
 
+func TestCBCRecordMAC(t *testing.T) {
+	key, iv := make([]byte, 16), make([]byte, 16)
+	block, _ := aes.NewCipher(key)
+	for _, test := range []struct {
+		name string
+		mac  func([]byte) hash.Hash
+	}{
+		{"SHA1", macSHA1},
+		{"SHA256", macSHA256},
+	} {
+		macKey := bytes.Repeat([]byte{0x42}, test.mac(nil).Size())
+		for payloadLen := 0; payloadLen < 64; payloadLen += 7 {
+			// Every valid padding length, not only the minimal one that
+			// encrypt uses, since the MAC check must not depend on it.
+			macLen := len(macKey)
+			for paddingLen := 16 - (payloadLen+macLen)%16; paddingLen <= 256; paddingLen += 16 {
+				for _, corrupt := range []bool{false, true} {
+					payload := bytes.Repeat([]byte{byte(payloadLen)}, payloadLen)
+					header := []byte{byte(recordTypeApplicationData), 3, 1, 0, byte(payloadLen)}
+					mac := tls10MAC(test.mac(macKey), nil, make([]byte, 8), header, payload, nil)
+					if corrupt {
+						mac[0] ^= 1
+					}
+					plaintext := append(append([]byte{}, payload...), mac...)
+					for i := 0; i < paddingLen; i++ {
+						plaintext = append(plaintext, byte(paddingLen-1))
+					}
+					cipher.NewCBCEncrypter(block, iv).CryptBlocks(plaintext, plaintext)
+					n := len(plaintext)
+					record := append([]byte{header[0], 3, 1, byte(n >> 8), byte(n)}, plaintext...)
+
+					// Check the record with the MAC as is, which may be a
+					// constantTimeTLSMAC, and hidden behind a plain
+					// hash.Hash, which decrypt checks with tls10MAC.
+					for _, mac := range []hash.Hash{test.mac(macKey), struct{ hash.Hash }{test.mac(macKey)}} {
+						hc := &halfConn{version: VersionTLS10, cipher: cipherAES(key, iv, true), mac: mac}
+						out, _, err := hc.decrypt(append([]byte{}, record...))
+						if corrupt {
+							if err != alertBadRecordMAC {
+								t.Errorf("%s, payload %d, padding %d: corrupted MAC accepted", test.name, payloadLen, paddingLen)
+							}
+						} else if err != nil || !bytes.Equal(out, payload) {
+							t.Errorf("%s, payload %d, padding %d: decrypt = %x, %v; want %x", test.name, payloadLen, paddingLen, out, err, payload)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// TestConstantTimeTLSSum checks the MAC of received CBC records computed
+// by a constantTimeTLSMAC, which only the BoringCrypto HMAC implements,
+// against tls10MAC.
+func TestConstantTimeTLSSum(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		mac  func([]byte) hash.Hash
+	}{
+		{"SHA1", macSHA1},
+		{"SHA256", macSHA256},
+	} {
+		macKey := bytes.Repeat([]byte{0x42}, test.mac(nil).Size())
+		m, ok := test.mac(macKey).(constantTimeTLSMAC)
+		if !ok {
+			t.Skip("the MAC has no constant-time TLS mode")
+		}
+		seq := []byte{0, 0, 0, 0, 0, 0, 0, 1}
+		record := make([]byte, 64+len(macKey)+256)
+		for i := range record {
+			record[i] = byte(i)
+		}
+		for n := 0; n < 64; n++ {
+			for _, paddingLen := range []int{1, 16, 256} {
+				payload := record[:n+len(macKey)+paddingLen]
+				header := []byte{byte(recordTypeApplicationData), 3, 1, byte(n >> 8), byte(n)}
+				want := tls10MAC(test.mac(macKey), nil, seq, header, payload[:n], nil)
+				got := m.ConstantTimeTLSSum(nil, append(append([]byte{}, seq...), header...), payload, n)
+				if got == nil {
+					t.Skip("ConstantTimeTLSSum is not supported")
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("%s, payload %d, padding %d: ConstantTimeTLSSum = %x, want %x", test.name, n, paddingLen, got, want)
+				}
+			}
+		}
+	}
+}
+
 var certExampleCom = `308201713082011ba003020102021005a75ddf21014d5f417083b7a010ba2e300d06092a864886f70d01010b050030123110300e060355040a130741636d6520436f301e170d3136303831373231343135335a170d3137303831373231343135335a30123110300e060355040a130741636d6520436f305c300d06092a864886f70d0101010500034b003048024100b37f0fdd67e715bf532046ac34acbd8fdc4dabe2b598588f3f58b1f12e6219a16cbfe54d2b4b665396013589262360b6721efa27d546854f17cc9aeec6751db10203010001a34d304b300e0603551d0f0101ff0404030205a030130603551d25040c300a06082b06010505070301300c0603551d130101ff0402300030160603551d11040f300d820b6578616d706c652e636f6d300d06092a864886f70d01010b050003410059fc487866d3d855503c8e064ca32aac5e9babcece89ec597f8b2b24c17867f4a5d3b4ece06e795bfc5448ccbd2ffca1b3433171ebf3557a4737b020565350a0`
 
 var certWildcardExampleCom = `308201743082011ea003020102021100a7aa6297c9416a4633af8bec2958c607300d06092a864886f70d01010b050030123110300e060355040a130741636d6520436f301e170d3136303831373231343231395a170d3137303831373231343231395a30123110300e060355040a130741636d6520436f305c300d06092a864886f70d0101010500034b003048024100b105afc859a711ee864114e7d2d46c2dcbe392d3506249f6c2285b0eb342cc4bf2d803677c61c0abde443f084745c1a6d62080e5664ef2cc8f50ad8a0ab8870b0203010001a34f304d300e0603551d0f0101ff0404030205a030130603551d25040c300a06082b06010505070301300c0603551d130101ff0402300030180603551d110411300f820d2a2e6578616d706c652e636f6d300d06092a864886f70d01010b0500034100af26088584d266e3f6566360cf862c7fecc441484b098b107439543144a2b93f20781988281e108c6d7656934e56950e1e5f2bcf38796b814ccb729445856c34`
@@ -617,12 +617,16 @@ func (hs *clientHandshakeState) doFullHandshake() error {
 			}
 		}
 
-		signed := hs.finishedHash.hashForClientCertificate(sigType, sigHash, hs.masterSecret)
+		signed := hs.finishedHash.hashForClientCertificate(sigType, hs.masterSecret)
 		signOpts := crypto.SignerOpts(sigHash)
 		if sigType == signatureRSAPSS {
 			signOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: sigHash}
 		}
-		certVerify.signature, err = key.Sign(c.config.rand(), signed, signOpts)
+		if c.vers >= VersionTLS12 {
+			certVerify.signature, err = signHandshake(c.config.rand(), key, signed, signOpts)
+		} else {
+			certVerify.signature, err = key.Sign(c.config.rand(), signed, signOpts)
+		}
 		if err != nil {
 			c.sendAlert(alertInternalError)
 			return err
@@ -636,8 +636,13 @@ func (hs *serverHandshakeState) doFullHandshake() error {
 			}
 		}
 
-		signed := hs.finishedHash.hashForClientCertificate(sigType, sigHash, hs.masterSecret)
-		if err := verifyHandshakeSignature(sigType, pub, sigHash, signed, certVerify.signature); err != nil {
+		signed := hs.finishedHash.hashForClientCertificate(sigType, hs.masterSecret)
+		if c.vers >= VersionTLS12 {
+			err = verifyHandshakeSignature(sigType, pub, sigHash, signed, certVerify.signature)
+		} else {
+			err = verifyLegacyHandshakeSignature(sigType, pub, sigHash, signed, certVerify.signature)
+		}
+		if err != nil {
 			c.sendAlert(alertDecryptError)
 			return errors.New("tls: invalid signature by the client certificate: " + err.Error())
 		}
@@ -101,26 +101,19 @@ func md5SHA1Hash(slices [][]byte) []byte {
 	return md5sha1
 }
 
-// hashForServerKeyExchange hashes the given slices and returns their digest
-// using the given hash function (for >= TLS 1.2) or using a default based on
-// the sigType (for earlier TLS versions). For Ed25519 signatures, which don't
-// do pre-hashing, it returns the concatenation of the slices.
-func hashForServerKeyExchange(sigType uint8, hashFunc crypto.Hash, version uint16, slices ...[]byte) []byte {
-	if sigType == signatureEd25519 {
+// hashForServerKeyExchange returns the contents to be signed for a
+// ServerKeyExchange message. For TLS 1.2 and Ed25519 that is the
+// concatenation of the given slices, which the signature operation hashes
+// itself. Earlier TLS versions sign a digest using a default based on the
+// sigType.
+func hashForServerKeyExchange(sigType uint8, version uint16, slices ...[]byte) []byte {
+	if sigType == signatureEd25519 || version >= VersionTLS12 {
 		var signed []byte
 		for _, slice := range slices {
 			signed = append(signed, slice...)
 		}
 		return signed
 	}
-	if version >= VersionTLS12 {
-		h := hashFunc.New()
-		for _, slice := range slices {
-			h.Write(slice)
-		}
-		digest := h.Sum(nil)
-		return digest
-	}
 	if sigType == signatureECDSA {
 		return sha1Hash(slices)
 	}
@@ -200,13 +193,18 @@ func (ka *ecdheKeyAgreement) generateServerKeyExchange(config *Config, cert *Cer
 		return nil, errors.New("tls: certificate cannot be used with the selected cipher suite")
 	}
 
-	signed := hashForServerKeyExchange(sigType, sigHash, ka.version, clientHello.random, hello.random, serverECDHEParams)
+	signed := hashForServerKeyExchange(sigType, ka.version, clientHello.random, hello.random, serverECDHEParams)
 
 	signOpts := crypto.SignerOpts(sigHash)
 	if sigType == signatureRSAPSS {
 		signOpts = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: sigHash}
 	}
-	sig, err := priv.Sign(config.rand(), signed, signOpts)
+	var sig []byte
+	if ka.version >= VersionTLS12 {
+		sig, err = signHandshake(config.rand(), priv, signed, signOpts)
+	} else {
+		sig, err = priv.Sign(config.rand(), signed, signOpts)
+	}
 	if err != nil {
 		return nil, errors.New("tls: failed to sign ECDHE parameters: " + err.Error())
 	}
@@ -318,8 +316,13 @@ func (ka *ecdheKeyAgreement) processServerKeyExchange(config *Config, clientHell
 	}
 	sig = sig[2:]
 
-	signed := hashForServerKeyExchange(sigType, sigHash, ka.version, clientHello.random, serverHello.random, serverECDHEParams)
-	if err := verifyHandshakeSignature(sigType, cert.PublicKey, sigHash, signed, sig); err != nil {
+	signed := hashForServerKeyExchange(sigType, ka.version, clientHello.random, serverHello.random, serverECDHEParams)
+	if ka.version >= VersionTLS12 {
+		err = verifyHandshakeSignature(sigType, cert.PublicKey, sigHash, signed, sig)
+	} else {
+		err = verifyLegacyHandshakeSignature(sigType, cert.PublicKey, sigHash, signed, sig)
+	}
+	if err != nil {
 		return errors.New("tls: invalid signature by the server certificate: " + err.Error())
 	}
 	return nil
@@ -7,6 +7,7 @@ package tls
 import (
 	"crypto"
 	"crypto/hmac"
+	"crypto/internal/boring"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
@@ -148,17 +149,28 @@ func newFinishedHash(version uint16, cipherSuite *cipherSuite) finishedHash {
 
 	prf, hash := prfAndHashForVersion(version, cipherSuite)
 	if hash != 0 {
-		return finishedHash{hash.New(), hash.New(), nil, nil, buffer, version, prf}
+		return finishedHash{hash.New(), newServerHash(hash.New), nil, nil, buffer, version, prf}
 	}
 
-	return finishedHash{sha1.New(), sha1.New(), md5.New(), md5.New(), buffer, version, prf}
+	return finishedHash{sha1.New(), newServerHash(sha1.New), md5.New(), md5.New(), buffer, version, prf}
+}
+
+// newServerHash returns a second instance of the hash to write the
+// handshake messages to, or nil if the hash is a boring.Cloner, in which
+// case the client hash is cloned when the server hash is needed.
+func newServerHash(h func() hash.Hash) hash.Hash {
+	server := h()
+	if _, ok := server.(boring.Cloner); ok {
+		return nil
+	}
+	return server
 }
 
 // A finishedHash calculates the hash of a set of handshake messages suitable
 // for including in a Finished message.
 type finishedHash struct {
 	client hash.Hash
-	server hash.Hash
+	server hash.Hash // nil if client is a boring.Cloner
 
 	// Prior to TLS 1.2, an additional MD5 hash is required.
 	clientMD5 hash.Hash
@@ -173,7 +185,9 @@ type finishedHash struct {
 
 func (h *finishedHash) Write(msg []byte) (n int, err error) {
 	h.client.Write(msg)
-	h.server.Write(msg)
+	if h.server != nil {
+		h.server.Write(msg)
+	}
 
 	if h.version < VersionTLS12 {
 		h.clientMD5.Write(msg)
@@ -213,24 +227,23 @@ func (h finishedHash) serverSum(masterSecret []byte) []byte {
 	return out
 }
 
-// hashForClientCertificate returns the handshake messages so far, pre-hashed if
-// necessary, suitable for signing by a TLS client certificate.
-func (h finishedHash) hashForClientCertificate(sigType uint8, hashAlg crypto.Hash, masterSecret []byte) []byte {
+// hashForClientCertificate returns the handshake messages so far, suitable
+// for signing by a TLS client certificate. From TLS 1.2 on they are returned
+// unhashed, for the signature operation to hash; earlier versions sign a
+// fixed pre-hash.
+func (h finishedHash) hashForClientCertificate(sigType uint8, masterSecret []byte) []byte {
 	if (h.version >= VersionTLS12 || sigType == signatureEd25519) && h.buffer == nil {
 		panic("tls: handshake hash for a client certificate requested after discarding the handshake buffer")
 	}
 
-	if sigType == signatureEd25519 {
+	if sigType == signatureEd25519 || h.version >= VersionTLS12 {
 		return h.buffer
 	}
 
-	if h.version >= VersionTLS12 {
-		hash := hashAlg.New()
-		hash.Write(h.buffer)
-		return hash.Sum(nil)
-	}
-
 	if sigType == signatureECDSA {
+		if h.server == nil {
+			return h.client.(boring.Cloner).Clone().Sum(nil)
+		}
 		return h.server.Sum(nil)
 	}
 
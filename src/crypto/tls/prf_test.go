@@ -5,7 +5,9 @@
 package tls
 
 import (
+	"bytes"
 	"crypto/internal/boring"
+	"crypto/sha1"
 	"encoding/hex"
 	"testing"
 )
@@ -142,3 +144,21 @@ var testKeysFromTests = []testKeysFromTest{
 		"f3b4ac743f015ef21d79978297a53da3e579ee047133f38c234d829c0f907dab",
 	},
 }
+
+// Test that the ECDSA pre-hash of TLS 1.0 covers every handshake message,
+// also when it is cloned from the client hash.
+func TestFinishedHashServerHash(t *testing.T) {
+	h := newFinishedHash(VersionTLS10, cipherSuiteByID(TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA))
+	h.Write([]byte("client hello"))
+	h.Write([]byte("server hello"))
+	want := sha1.Sum([]byte("client helloserver hello"))
+	if got := h.hashForClientCertificate(signatureECDSA, nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("hashForClientCertificate = %x, want %x", got, want)
+	}
+
+	h.Write([]byte("finished"))
+	want = sha1.Sum([]byte("client helloserver hellofinished"))
+	if got := h.hashForClientCertificate(signatureECDSA, nil); !bytes.Equal(got, want[:]) {
+		t.Errorf("hashForClientCertificate after Write = %x, want %x", got, want)
+	}
+}
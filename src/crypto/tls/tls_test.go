@@ -1474,3 +1474,56 @@ func TestPKCS1OnlyCert(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+type countingMessageSigner struct {
+	crypto.MessageSigner
+	calls *int
+}
+
+func (s countingMessageSigner) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	*s.calls++
+	return s.MessageSigner.SignMessage(rand, msg, opts)
+}
+
+// TestMessageSigner checks that certificate keys implementing
+// crypto.MessageSigner are handed the unhashed handshake contents from
+// TLS 1.2 on, for both the server and the client certificate.
+func TestMessageSigner(t *testing.T) {
+	for _, key := range []struct {
+		name string
+		cert []byte
+		priv crypto.MessageSigner
+	}{
+		{"RSA", testRSACertificate, testRSAPrivateKey},
+		{"ECDSA", testECDSACertificate, testECDSAPrivateKey},
+	} {
+		for _, v := range []struct {
+			version uint16
+			calls   int
+		}{
+			{VersionTLS10, 0},
+			{VersionTLS12, 2},
+			{VersionTLS13, 2},
+		} {
+			var calls int
+			cert := Certificate{
+				Certificate: [][]byte{key.cert},
+				PrivateKey:  countingMessageSigner{key.priv, &calls},
+			}
+			clientConfig := testConfig.Clone()
+			clientConfig.Certificates = []Certificate{cert}
+			clientConfig.MinVersion = v.version
+			clientConfig.MaxVersion = v.version
+			serverConfig := testConfig.Clone()
+			serverConfig.Certificates = []Certificate{cert}
+			serverConfig.ClientAuth = RequireAnyClientCert
+			if _, _, err := testHandshake(t, clientConfig, serverConfig); err != nil {
+				t.Errorf("%s, %x: %v", key.name, v.version, err)
+				continue
+			}
+			if calls != v.calls {
+				t.Errorf("%s, %x: got %d SignMessage calls, want %d", key.name, v.version, calls, v.calls)
+			}
+		}
+	}
+}
@@ -2008,6 +2008,23 @@ func subjectBytes(cert *Certificate) ([]byte, error) {
 	return asn1.Marshal(cert.Subject.ToRDNSequence())
 }
 
+// signTBS signs the DER encoded to-be-signed contents tbs with key, hashing
+// them first with opts.HashFunc() unless it is zero. If key implements
+// crypto.MessageSigner, the unhashed contents are passed to it, so that in
+// FIPS mode the digest is computed by the crypto module.
+func signTBS(rand io.Reader, key crypto.Signer, tbs []byte, opts crypto.SignerOpts) ([]byte, error) {
+	hashFunc := opts.HashFunc()
+	if hashFunc == 0 {
+		return key.Sign(rand, tbs, opts)
+	}
+	if ms, ok := key.(crypto.MessageSigner); ok {
+		return ms.SignMessage(rand, tbs, opts)
+	}
+	h := hashFunc.New()
+	h.Write(tbs)
+	return key.Sign(rand, h.Sum(nil), opts)
+}
+
 // signingParamsForPublicKey returns the parameters to use for signing with
 // priv. If requestedSigAlgo is not zero then it overrides the default
 // signature algorithm.
@@ -2209,13 +2226,6 @@ func CreateCertificate(rand io.Reader, template, parent *Certificate, pub, priv
 	}
 	c.Raw = tbsCertContents
 
-	signed := tbsCertContents
-	if hashFunc != 0 {
-		h := hashFunc.New()
-		h.Write(signed)
-		signed = h.Sum(nil)
-	}
-
 	var signerOpts crypto.SignerOpts = hashFunc
 	if template.SignatureAlgorithm != 0 && template.SignatureAlgorithm.isRSAPSS() {
 		signerOpts = &rsa.PSSOptions{
@@ -2225,7 +2235,7 @@ func CreateCertificate(rand io.Reader, template, parent *Certificate, pub, priv
 	}
 
 	var signature []byte
-	signature, err = key.Sign(rand, signed, signerOpts)
+	signature, err = signTBS(rand, key, tbsCertContents, signerOpts)
 	if err != nil {
 		return
 	}
@@ -2332,15 +2342,8 @@ func (c *Certificate) CreateCRL(rand io.Reader, priv interface{}, revokedCerts [
 		return
 	}
 
-	signed := tbsCertListContents
-	if hashFunc != 0 {
-		h := hashFunc.New()
-		h.Write(signed)
-		signed = h.Sum(nil)
-	}
-
 	var signature []byte
-	signature, err = key.Sign(rand, signed, hashFunc)
+	signature, err = signTBS(rand, key, tbsCertListContents, hashFunc)
 	if err != nil {
 		return
 	}
@@ -2635,15 +2638,8 @@ func CreateCertificateRequest(rand io.Reader, template *CertificateRequest, priv
 	}
 	tbsCSR.Raw = tbsCSRContents
 
-	signed := tbsCSRContents
-	if hashFunc != 0 {
-		h := hashFunc.New()
-		h.Write(signed)
-		signed = h.Sum(nil)
-	}
-
 	var signature []byte
-	signature, err = key.Sign(rand, signed, hashFunc)
+	signature, err = signTBS(rand, key, tbsCSRContents, hashFunc)
 	if err != nil {
 		return
 	}
@@ -2838,12 +2834,6 @@ func CreateRevocationList(rand io.Reader, template *RevocationList, issuer *Cert
 		return nil, err
 	}
 
-	input := tbsCertListContents
-	if hashFunc != 0 {
-		h := hashFunc.New()
-		h.Write(tbsCertListContents)
-		input = h.Sum(nil)
-	}
 	var signerOpts crypto.SignerOpts = hashFunc
 	if template.SignatureAlgorithm.isRSAPSS() {
 		signerOpts = &rsa.PSSOptions{
@@ -2852,7 +2842,7 @@ func CreateRevocationList(rand io.Reader, template *RevocationList, issuer *Cert
 		}
 	}
 
-	signature, err := priv.Sign(rand, input, signerOpts)
+	signature, err := signTBS(rand, priv, tbsCertListContents, signerOpts)
 	if err != nil {
 		return nil, err
 	}
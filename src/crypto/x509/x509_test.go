@@ -2897,6 +2897,74 @@ func TestCreateCertificateBrokenSigner(t *testing.T) {
 	}
 }
 
+type messageSigner struct {
+	crypto.MessageSigner
+	calls int
+}
+
+func (ms *messageSigner) SignMessage(rand io.Reader, msg []byte, opts crypto.SignerOpts) ([]byte, error) {
+	ms.calls++
+	return ms.MessageSigner.SignMessage(rand, msg, opts)
+}
+
+func TestCreateCertificateMessageSigner(t *testing.T) {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate test key: %s", err)
+	}
+	ms := &messageSigner{MessageSigner: k}
+	template := &Certificate{
+		SerialNumber:          big.NewInt(10),
+		DNSNames:              []string{"example.com"},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              KeyUsageCertSign | KeyUsageCRLSign,
+	}
+	der, err := CreateCertificate(rand.Reader, template, template, k.Public(), ms)
+	if err != nil {
+		t.Fatalf("CreateCertificate failed: %s", err)
+	}
+	cert, err := ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %s", err)
+	}
+	if err := cert.CheckSignatureFrom(cert); err != nil {
+		t.Errorf("signature check failed: %s", err)
+	}
+
+	csr, err := CreateCertificateRequest(rand.Reader, &CertificateRequest{DNSNames: []string{"example.com"}}, ms)
+	if err != nil {
+		t.Fatalf("CreateCertificateRequest failed: %s", err)
+	}
+	req, err := ParseCertificateRequest(csr)
+	if err != nil {
+		t.Fatalf("failed to parse certificate request: %s", err)
+	}
+	if err := req.CheckSignature(); err != nil {
+		t.Errorf("certificate request signature check failed: %s", err)
+	}
+
+	crl, err := CreateRevocationList(rand.Reader, &RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+	}, cert, ms)
+	if err != nil {
+		t.Fatalf("CreateRevocationList failed: %s", err)
+	}
+	list, err := ParseCRL(crl)
+	if err != nil {
+		t.Fatalf("failed to parse CRL: %s", err)
+	}
+	if err := cert.CheckCRLSignature(list); err != nil {
+		t.Errorf("CRL signature check failed: %s", err)
+	}
+
+	if ms.calls != 3 {
+		t.Errorf("got %d SignMessage calls, want 3", ms.calls)
+	}
+}
+
 func TestCreateCertificateMD5(t *testing.T) {
 	template := &Certificate{
 		SerialNumber:       big.NewInt(10),
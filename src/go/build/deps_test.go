@@ -380,10 +380,12 @@ var depsRules = `
 
 	sync/atomic < crypto/internal/boring/fipstls;
 	crypto/internal/boring/fipstls < crypto/internal/boring/sig;
+	CGO < crypto/internal/boring/staticlink;
 
 	encoding/binary, golang.org/x/sys/cpu, hash,
 	FMT, math/big,
-	CGO, crypto/internal/boring/sig, crypto/internal/boring/fipstls
+	CGO, crypto/internal/boring/sig, crypto/internal/boring/fipstls,
+	crypto/internal/boring/staticlink
 	< crypto
 	< crypto/subtle
 	< crypto/internal/subtle
@@ -391,7 +393,9 @@ var depsRules = `
 	< encoding/asn1
 	< crypto/internal/boring
 	< crypto/aes, crypto/des, crypto/hmac, crypto/md5, crypto/rc4,
-	  crypto/sha1, crypto/sha256, crypto/sha512
+	  crypto/sha1, crypto/sha256, crypto/sha512, crypto/sha3,
+	  crypto/keywrap, crypto/cmac
+	< crypto/kdf
 	< crypto/rand
 	< crypto/internal/randutil
 	< crypto/ed25519/internal/edwards25519
@@ -420,7 +424,7 @@ var depsRules = `
 	crypto/internal/boring/sig, crypto/internal/boring/fipstls
 	< crypto/tls/fipsonly;
 
-	crypto/internal/boring
+	CRYPTO-BORING
 	< crypto/boring;
 
 	# crypto-aware packages